@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,6 +15,8 @@ import (
 	"github.com/1psychoQAQ/verdict-agent/internal/api"
 	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
 	"github.com/1psychoQAQ/verdict-agent/internal/config"
+	"github.com/1psychoQAQ/verdict-agent/internal/export"
+	"github.com/1psychoQAQ/verdict-agent/internal/jobs"
 	"github.com/1psychoQAQ/verdict-agent/internal/pipeline"
 	"github.com/1psychoQAQ/verdict-agent/internal/search"
 	"github.com/1psychoQAQ/verdict-agent/internal/storage"
@@ -21,6 +24,11 @@ import (
 )
 
 func main() {
+	// Schema migration subcommand: server migrate [up|down [N]|status]
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
+
 	// Load configuration
 	cfg, err := config.Load()
 	if err != nil {
@@ -36,54 +44,84 @@ func main() {
 
 	// Initialize storage
 	var repo storage.Repository
-	var memRepo *storage.MemoryRepository
+	var userRepo storage.UserRepository
 
-	// Try PostgreSQL first, fall back to memory storage
-	if cfg.DatabaseURL != "" {
-		pgRepo, err := storage.NewPostgresRepository(ctx, cfg.DatabaseURL)
+	// Try SQLite or PostgreSQL first, fall back to memory storage
+	if storage.IsSQLiteURL(cfg.DatabaseURL) {
+		sqliteRepo, err := storage.NewSQLiteRepository(ctx, cfg.DatabaseURL)
+		if err != nil {
+			log.Fatalf("Failed to open SQLite database: %v", err)
+		}
+		repo, userRepo = sqliteRepo, sqliteRepo
+		defer sqliteRepo.Close()
+	} else if cfg.DatabaseURL != "" {
+		pgRepo, err := storage.NewPostgresRepositoryWithConfig(ctx, &storage.Config{
+			DatabaseURL:     cfg.DatabaseURL,
+			MaxConns:        10,
+			MinConns:        2,
+			MaxConnLifetime: time.Hour,
+			AutoMigrate:     cfg.DBAutoMigrate,
+		})
 		if err != nil {
 			log.Printf("Warning: Failed to connect to database: %v, using memory storage", err)
-			memRepo = storage.NewMemoryRepository()
-			repo = memRepo
+			memRepo := storage.NewMemoryRepository()
+			repo, userRepo = memRepo, memRepo
 		} else {
-			repo = pgRepo
+			repo, userRepo = pgRepo, pgRepo
 			defer pgRepo.Close()
-			// Also create memory repo for auth/history features
-			memRepo = storage.NewMemoryRepository()
 		}
 	} else {
 		log.Println("No database URL configured, using memory storage")
-		memRepo = storage.NewMemoryRepository()
-		repo = memRepo
+		memRepo := storage.NewMemoryRepository()
+		repo, userRepo = memRepo, memRepo
 	}
 
-	// Initialize LLM client
-	var llmClient agent.LLMClient
-	switch cfg.LLMProvider {
-	case "openai":
-		llmClient, err = agent.NewLLMClient(agent.Config{
-			Provider: "openai",
-			APIKey:   cfg.OpenAIAPIKey,
-		})
-	case "anthropic":
-		llmClient, err = agent.NewLLMClient(agent.Config{
-			Provider: "anthropic",
-			APIKey:   cfg.AnthropicAPIKey,
-		})
-	case "gemini":
-		llmClient, err = agent.NewLLMClient(agent.Config{
-			Provider: "gemini",
-			APIKey:   cfg.GeminiAPIKey,
-		})
-	}
+	// Initialize LLM clients, one per distinct provider/model used by the
+	// clarification, verdict and execution stages
+	fallbacks, err := agent.ParseProviderChain(cfg.LLMFallbacks)
 	if err != nil {
-		log.Fatalf("Failed to create LLM client: %v", err)
+		log.Fatalf("Invalid LLM_FALLBACKS: %v", err)
+	}
+	defaultSpec := agent.ProviderSpec{Provider: cfg.LLMProvider, Model: cfg.LLMModel}
+	llmClients := make(map[agent.ProviderSpec]agent.LLMClient)
+	stageClient := func(setting, raw string) agent.LLMClient {
+		spec := defaultSpec
+		if raw != "" {
+			if spec, err = agent.ParseProviderSpec(raw); err != nil {
+				log.Fatalf("Invalid %s: %v", setting, err)
+			}
+		}
+		if client, ok := llmClients[spec]; ok {
+			return client
+		}
+		client, err := newLLMClient(cfg, spec, fallbacks)
+		if err != nil {
+			log.Fatalf("Failed to create LLM client for %s: %v", setting, err)
+		}
+		llmClients[spec] = client
+		return client
 	}
+	clarificationClient := stageClient("CLARIFICATION_LLM", cfg.ClarificationLLM)
+	verdictClient := stageClient("VERDICT_LLM", cfg.VerdictLLM)
+	executionClient := stageClient("EXECUTION_LLM", cfg.ExecutionLLM)
 
 	// Initialize agents
-	verdictAgent := agent.NewVerdictAgent(llmClient)
-	executionAgent := agent.NewExecutionAgent(llmClient)
-	clarificationAgent := agent.NewClarificationAgent(llmClient)
+	verdictAgent := agent.NewVerdictAgent(verdictClient)
+	executionAgent := agent.NewExecutionAgent(executionClient)
+	verdictAgent.SetMaxRepairs(cfg.MaxRepairs)
+	executionAgent.SetMaxRepairs(cfg.MaxRepairs)
+
+	// Configure the hedging linter for verdicts
+	hedgingMode, err := agent.ParseHedgingMode(cfg.HedgingMode)
+	if err != nil {
+		log.Fatalf("Invalid HEDGING_MODE: %v", err)
+	}
+	hedgingPhrases, err := agent.ParsePhraseLists(cfg.HedgingPhrases, agent.DefaultHedgingPhrases())
+	if err != nil {
+		log.Fatalf("Failed to parse HEDGING_PHRASES: %v", err)
+	}
+	verdictAgent.SetHedgingLinter(agent.NewHedgingLinter(hedgingPhrases, nil), hedgingMode)
+	clarificationAgent := agent.NewClarificationAgent(clarificationClient)
 
 	// Initialize search client (optional)
 	var searchClient search.Client
@@ -111,16 +149,63 @@ func main() {
 	// Initialize pipeline with search
 	p := pipeline.NewPipelineWithSearch(verdictAgent, executionAgent, searchClient, 10*time.Minute)
 
+	// Price table for per-run cost estimates
+	prices, err := agent.ParsePriceTable(cfg.LLMPrices)
+	if err != nil {
+		log.Fatalf("Failed to parse LLM_PRICES: %v", err)
+	}
+	p.SetPriceTable(prices)
+
 	// Initialize artifact generator
 	generator := artifact.NewGenerator()
+	if cfg.SigningKey != "" {
+		key, err := artifact.ParseSigningKey(cfg.SigningKey)
+		if err != nil {
+			log.Fatalf("Failed to parse SIGNING_KEY: %v", err)
+		}
+		generator.SetSigningKey(key)
+		log.Printf("Signing artifacts with Ed25519 key %s", base64.StdEncoding.EncodeToString(generator.PublicKey()))
+	}
+	if cfg.TemplateDir != "" {
+		renderers := artifact.NewRegistry()
+		formats, err := renderers.LoadDir(cfg.TemplateDir)
+		if err != nil {
+			log.Fatalf("Failed to load TEMPLATE_DIR: %v", err)
+		}
+		generator.SetRenderers(renderers)
+		log.Printf("Loaded templates for %v from %s; render formats: %v", formats, cfg.TemplateDir, renderers.Formats())
+	}
+
+	// Initialize GitHub Issues export (optional)
+	var githubExporter *export.GitHubExporter
+	if cfg.GitHubToken != "" && cfg.GitHubRepository != "" {
+		githubExporter, err = export.NewGitHubExporter(export.GitHubConfig{
+			Token:      cfg.GitHubToken,
+			Repository: cfg.GitHubRepository,
+			BaseURL:    cfg.GitHubAPIURL,
+		})
+		if err != nil {
+			log.Fatalf("Failed to configure GitHub export: %v", err)
+		}
+		log.Printf("GitHub Issues export enabled for %s", cfg.GitHubRepository)
+	}
+
+	// Initialize job queue for asynchronous verdicts
+	jobQueue := jobs.NewQueue(repo, jobs.Config{
+		Workers:   cfg.JobWorkers,
+		QueueSize: cfg.JobQueueSize,
+	})
 
 	// Create router with configuration
 	routerCfg := api.RouterConfig{
 		Pipeline:           p,
 		Generator:          generator,
 		Repository:         repo,
-		MemoryRepository:   memRepo,
+		UserRepository:     userRepo,
 		ClarificationAgent: clarificationAgent,
+		JobQueue:           jobQueue,
+		GitHubExporter:     githubExporter,
+		DuplicateThreshold: cfg.DuplicateThreshold,
 		RateLimit:          10,
 		Timeout:            10 * time.Minute,
 		CORSConfig:         api.DefaultCORSConfig(),
@@ -131,6 +216,12 @@ func main() {
 	log.Println("User accounts and history enabled")
 	router := api.NewRouter(routerCfg)
 
+	// Start job workers (the router registers the job runner)
+	if err := jobQueue.Start(ctx); err != nil {
+		log.Fatalf("Failed to start job queue: %v", err)
+	}
+	log.Printf("Job queue enabled with %d worker(s)", cfg.JobWorkers)
+
 	// Create HTTP server
 	addr := fmt.Sprintf(":%d", cfg.Port)
 	server := &http.Server{
@@ -164,9 +255,63 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
+	// Stop job workers; interrupted jobs resume on next start
+	jobQueue.Stop()
+
 	log.Println("Server stopped")
 }
 
+// newLLMClient creates the client for a provider and model. If fallback
+// providers are configured, it is wrapped in a fallback chain trying them in
+// order after spec. Clients in a chain do not retry failed calls themselves,
+// so a failing provider is skipped at once instead of after a full retry
+// sequence.
+func newLLMClient(cfg *config.Config, spec agent.ProviderSpec, fallbacks []agent.ProviderSpec) (agent.LLMClient, error) {
+	chain := append([]agent.ProviderSpec{spec}, fallbacks...)
+	providers := make([]agent.FallbackProvider, 0, len(chain))
+	for _, s := range chain {
+		llmCfg := llmConfigFor(cfg, s.Provider, s.Model)
+		if len(chain) > 1 {
+			llmCfg.MaxRetries = agent.NoRetries
+		}
+		if llmCfg.APIKey == "" && s.Provider != agent.ProviderLocal {
+			return nil, fmt.Errorf("no API key configured for provider %s", s.Provider)
+		}
+		client, err := agent.NewLLMClient(llmCfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", s, err)
+		}
+		providers = append(providers, agent.FallbackProvider{Name: s.String(), Client: client})
+	}
+
+	if len(providers) == 1 {
+		log.Printf("LLM %s", spec)
+		return providers[0].Client, nil
+	}
+	log.Printf("LLM %s with fallbacks %s", spec, cfg.LLMFallbacks)
+	return agent.NewFallbackClient(providers, cfg.CircuitThreshold, time.Duration(cfg.CircuitCooldown)*time.Second), nil
+}
+
+// llmConfigFor builds the client configuration for a provider from the
+// application configuration
+func llmConfigFor(cfg *config.Config, provider, model string) agent.Config {
+	llmCfg := agent.Config{
+		Provider: provider,
+		Model:    model,
+	}
+	switch provider {
+	case agent.ProviderOpenAI:
+		llmCfg.APIKey, llmCfg.BaseURL = cfg.OpenAIAPIKey, cfg.OpenAIBaseURL
+	case agent.ProviderAnthropic:
+		llmCfg.APIKey, llmCfg.BaseURL = cfg.AnthropicAPIKey, cfg.AnthropicBaseURL
+	case agent.ProviderGemini:
+		llmCfg.APIKey, llmCfg.BaseURL = cfg.GeminiAPIKey, cfg.GeminiBaseURL
+	case agent.ProviderLocal:
+		llmCfg.APIKey, llmCfg.BaseURL = cfg.LocalAPIKey, cfg.LocalBaseURL
+	}
+	return llmCfg
+}
+
 // startHealthOnlyServer starts a minimal server with just the health check endpoint and frontend
 func startHealthOnlyServer(port int) {
 	router := api.NewRouter(api.RouterConfig{
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/config"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+)
+
+const migrateUsage = "usage: server migrate [up | down [N] | status]"
+
+// runMigrate applies, reverts or lists schema migrations and returns the exit code
+func runMigrate(args []string) int {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	steps := 1
+	if command == "down" && len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		steps = n
+	}
+	if command != "up" && command != "down" && command != "status" {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	databaseURL := config.LoadDatabaseURL()
+	if databaseURL == "" {
+		fmt.Fprintln(os.Stderr, "DATABASE_URL is required")
+		return 1
+	}
+	if storage.IsSQLiteURL(databaseURL) {
+		fmt.Fprintln(os.Stderr, "SQLite databases are migrated automatically when the server opens them")
+		return 1
+	}
+
+	ctx := context.Background()
+	repo, err := storage.NewPostgresRepositoryWithConfig(ctx, &storage.Config{
+		DatabaseURL:     databaseURL,
+		MaxConns:        1,
+		MaxConnLifetime: time.Hour,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer repo.Close()
+
+	migrator, err := repo.Migrator()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load migrations: %v\n", err)
+		return 1
+	}
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied  %03d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+	case "down":
+		reverted, err := migrator.Down(ctx, steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %03d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Migration failed: %v\n", err)
+			return 1
+		}
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to read migration status: %v\n", err)
+			return 1
+		}
+		for _, s := range status {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%03d_%-24s %s\n", s.Version, s.Name, state)
+		}
+	}
+	return 0
+}
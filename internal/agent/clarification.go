@@ -90,7 +90,7 @@ func (a *ClarificationAgent) BuildEnrichedInput(clarification *ClarificationCont
 
 // buildClarificationPrompt constructs the prompt for clarification analysis
 func buildClarificationPrompt(input string) string {
-	lang := detectLanguage(input)
+	lang := DetectLanguage(input)
 
 	var systemPrompt string
 	if lang == "zh" {
@@ -0,0 +1,233 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider error classes. Errors from LLM clients wrap one of these, or
+// ErrRateLimited, ErrServerError, ErrTimeout or ErrStreamStalled, so callers
+// can match them with errors.Is.
+var (
+	ErrOverloaded      = errors.New("provider overloaded")
+	ErrAuth            = errors.New("authentication failed")
+	ErrContextLength   = errors.New("context length exceeded")
+	ErrContentFiltered = errors.New("content filtered")
+	ErrBadRequest      = errors.New("bad request")
+)
+
+// Retry policy
+const (
+	baseRetryDelay = time.Second      // Backoff before the first retry is 2x this
+	maxRetryDelay  = 30 * time.Second // Server hints longer than this are not waited out
+)
+
+// ProviderError is an error response from an LLM provider
+type ProviderError struct {
+	Provider   string
+	Class      error // One of the provider error classes
+	StatusCode int   // Zero for errors reported inside a 200 response or stream
+	Message    string
+	RetryAfter time.Duration // Server's retry hint; zero if none
+}
+
+func (e *ProviderError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Provider, e.Class)
+	if e.StatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.StatusCode)
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Class
+}
+
+// providerErrorBody matches the error envelope of OpenAI, Anthropic and Gemini
+type providerErrorBody struct {
+	Error *struct {
+		Message string          `json:"message"`
+		Type    string          `json:"type"`   // OpenAI, Anthropic
+		Code    json.RawMessage `json:"code"`   // OpenAI (string), Gemini (number)
+		Status  string          `json:"status"` // Gemini
+	} `json:"error"`
+}
+
+// newProviderError classifies a non-200 response
+func newProviderError(provider string, resp *http.Response, body []byte) *ProviderError {
+	message := strings.TrimSpace(string(body))
+	var errType string
+	var parsed providerErrorBody
+	if json.Unmarshal(body, &parsed) == nil && parsed.Error != nil {
+		message = parsed.Error.Message
+		errType = strings.Trim(string(parsed.Error.Code), `"`) + " " + parsed.Error.Type + " " + parsed.Error.Status
+	}
+	if len(message) > 500 {
+		message = message[:500] + "..."
+	}
+
+	return &ProviderError{
+		Provider:   provider,
+		Class:      errorClass(resp.StatusCode, errType, message),
+		StatusCode: resp.StatusCode,
+		Message:    message,
+		RetryAfter: retryAfterHint(resp.Header, time.Now()),
+	}
+}
+
+// newBodyError classifies an error reported inside a 200 response or a stream
+func newBodyError(provider, errType, message string) *ProviderError {
+	return &ProviderError{
+		Provider: provider,
+		Class:    errorClass(0, errType, message),
+		Message:  message,
+	}
+}
+
+var (
+	contextLengthMarkers = []string{
+		"context_length_exceeded", "maximum context length", "context window",
+		"prompt is too long", "too many tokens", "input is too long",
+		"exceeds the maximum number of tokens",
+	}
+	contentFilterMarkers = []string{
+		"content_filter", "content_policy", "content management policy",
+		"safety", "blocked",
+	}
+)
+
+// errorClass maps a status code and the provider's error type and message to
+// an error class. Status zero means the error arrived without one.
+func errorClass(status int, errType, message string) error {
+	errType = strings.ToLower(errType)
+	text := errType + " " + strings.ToLower(message)
+
+	switch {
+	case status == http.StatusTooManyRequests || strings.Contains(errType, "rate_limit") ||
+		strings.Contains(errType, "resource_exhausted"):
+		return ErrRateLimited
+	case status == http.StatusServiceUnavailable || status == 529 || strings.Contains(errType, "overloaded") ||
+		strings.Contains(errType, "unavailable"):
+		return ErrOverloaded
+	case status >= 500:
+		return ErrServerError
+	case status == http.StatusUnauthorized || status == http.StatusForbidden ||
+		strings.Contains(errType, "authentication") || strings.Contains(errType, "permission"):
+		return ErrAuth
+	case status == http.StatusRequestEntityTooLarge || containsAny(text, contextLengthMarkers):
+		return ErrContextLength
+	case containsAny(text, contentFilterMarkers):
+		return ErrContentFiltered
+	case status == 0 && !strings.Contains(errType, "invalid_request"):
+		// Errors reported mid-response without a recognisable type
+		return ErrServerError
+	default:
+		return ErrBadRequest
+	}
+}
+
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryAfterHint reads how long the server asked us to wait: retry-after-ms
+// (OpenAI), Retry-After in seconds or as an HTTP date, or the reset time of an
+// exhausted anthropic-ratelimit-* limit. It returns zero if there is no hint.
+func retryAfterHint(h http.Header, now time.Time) time.Duration {
+	if v := h.Get("retry-after-ms"); v != "" {
+		if ms, err := strconv.ParseFloat(v, 64); err == nil && ms > 0 {
+			return time.Duration(ms * float64(time.Millisecond))
+		}
+	}
+	if v := h.Get("Retry-After"); v != "" {
+		if secs, err := strconv.ParseFloat(v, 64); err == nil && secs > 0 {
+			return time.Duration(secs * float64(time.Second))
+		}
+		if t, err := http.ParseTime(v); err == nil && t.After(now) {
+			return t.Sub(now)
+		}
+	}
+
+	var latest time.Time
+	for _, limit := range []string{"requests", "tokens", "input-tokens", "output-tokens"} {
+		if h.Get("anthropic-ratelimit-"+limit+"-remaining") != "0" {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, h.Get("anthropic-ratelimit-"+limit+"-reset")); err == nil && t.After(latest) {
+			latest = t
+		}
+	}
+	if latest.After(now) {
+		return latest.Sub(now)
+	}
+	return 0
+}
+
+// isRetryable reports whether a request that failed with err may succeed if retried
+func isRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrOverloaded) ||
+		errors.Is(err, ErrServerError) || errors.Is(err, ErrTimeout) || errors.Is(err, ErrStreamStalled)
+}
+
+// retryDelay returns how long to wait before retry number attempt (1-based)
+// and whether err should be retried at all. Server hints are honoured; without
+// one the delay is exponential (2s, 4s, 8s, ...) with jitter.
+func retryDelay(attempt int, err error) (time.Duration, bool) {
+	if !isRetryable(err) {
+		return 0, false
+	}
+
+	var perr *ProviderError
+	if errors.As(err, &perr) && perr.RetryAfter > 0 {
+		if perr.RetryAfter > maxRetryDelay {
+			return 0, false
+		}
+		return perr.RetryAfter, true
+	}
+
+	backoff := baseRetryDelay << attempt
+	if backoff <= 0 || backoff > maxRetryDelay {
+		backoff = maxRetryDelay
+	}
+	// Equal jitter: half fixed, half random, so concurrent clients spread out
+	return backoff/2 + rand.N(backoff/2+1), true
+}
+
+// withRetry calls call until it succeeds, returns an error that is not worth
+// retrying, or maxRetries retries have been made
+func withRetry(ctx context.Context, maxRetries int, call func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := call()
+		if err == nil {
+			return nil
+		}
+		delay, retry := retryDelay(attempt+1, err)
+		if !retry {
+			return err
+		}
+		if attempt >= maxRetries {
+			return fmt.Errorf("max retries exceeded: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestErrorClass tests classification of provider error responses
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"rate limited", 429, `{"error":{"message":"Rate limit reached","type":"requests"}}`, ErrRateLimited},
+		{"gemini quota", 429, `{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}`, ErrRateLimited},
+		{"anthropic overloaded", 529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, ErrOverloaded},
+		{"service unavailable", 503, `upstream connect error`, ErrOverloaded},
+		{"bad gateway", 502, `<html>Bad Gateway</html>`, ErrServerError},
+		{"invalid key", 401, `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`, ErrAuth},
+		{"openai context length", 400, `{"error":{"message":"This model's maximum context length is 128000 tokens","type":"invalid_request_error","code":"context_length_exceeded"}}`, ErrContextLength},
+		{"anthropic prompt too long", 400, `{"type":"error","error":{"type":"invalid_request_error","message":"prompt is too long: 210000 tokens > 200000 maximum"}}`, ErrContextLength},
+		{"content filter", 400, `{"error":{"message":"The response was filtered","type":"invalid_request_error","code":"content_filter"}}`, ErrContentFiltered},
+		{"bad request", 400, `{"error":{"message":"Invalid value for 'model'","type":"invalid_request_error"}}`, ErrBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			err := newProviderError("test", resp, []byte(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// Errors reported inside a stream
+	if err := newBodyError("anthropic", "overloaded_error", "Overloaded"); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("expected ErrOverloaded, got %v", err)
+	}
+	if err := newBodyError("anthropic", "api_error", "Internal error"); !errors.Is(err, ErrServerError) {
+		t.Errorf("expected ErrServerError, got %v", err)
+	}
+}
+
+// TestRetryAfterHint tests parsing of server retry hints
+func TestRetryAfterHint(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+	}{
+		{"none", http.Header{}, 0},
+		{"seconds", http.Header{"Retry-After": {"7"}}, 7 * time.Second},
+		{"http date", http.Header{"Retry-After": {now.Add(20 * time.Second).Format(http.TimeFormat)}}, 20 * time.Second},
+		{"milliseconds", http.Header{"Retry-After-Ms": {"250"}}, 250 * time.Millisecond},
+		{"anthropic exhausted limit", http.Header{
+			"Anthropic-Ratelimit-Requests-Remaining": {"12"},
+			"Anthropic-Ratelimit-Requests-Reset":     {now.Add(50 * time.Second).Format(time.RFC3339)},
+			"Anthropic-Ratelimit-Tokens-Remaining":   {"0"},
+			"Anthropic-Ratelimit-Tokens-Reset":       {now.Add(5 * time.Second).Format(time.RFC3339)},
+		}, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryAfterHint(tt.header, now); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+// TestRetryDelay tests that the retry policy follows the error class and server hints
+func TestRetryDelay(t *testing.T) {
+	if _, retry := retryDelay(1, &ProviderError{Class: ErrAuth}); retry {
+		t.Error("expected auth failures not to be retried")
+	}
+	if _, retry := retryDelay(1, &ProviderError{Class: ErrContextLength}); retry {
+		t.Error("expected context length errors not to be retried")
+	}
+
+	if delay, retry := retryDelay(1, &ProviderError{Class: ErrRateLimited, RetryAfter: 3 * time.Second}); !retry || delay != 3*time.Second {
+		t.Errorf("expected the server hint to be honoured, got %v, %v", delay, retry)
+	}
+	if _, retry := retryDelay(1, &ProviderError{Class: ErrRateLimited, RetryAfter: time.Hour}); retry {
+		t.Error("expected hints longer than the maximum delay not to be waited out")
+	}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		backoff := baseRetryDelay << attempt
+		delay, retry := retryDelay(attempt, &ProviderError{Class: ErrOverloaded})
+		if !retry || delay < backoff/2 || delay > backoff {
+			t.Errorf("attempt %d: expected a jittered delay in [%v, %v], got %v", attempt, backoff/2, backoff, delay)
+		}
+	}
+}
+
+// TestCompleteRetriesOverloaded tests that overloaded responses are retried after the hinted delay
+func TestCompleteRetriesOverloaded(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("retry-after-ms", "10")
+			w.WriteHeader(529)
+			w.Write([]byte(`{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"content": []map[string]string{{"type": "text", "text": "ok"}}})
+	}))
+	defer server.Close()
+
+	client := &anthropicClient{
+		config:     Config{APIKey: "test-key", Model: "test-model", MaxRetries: 2, BaseURL: server.URL},
+		httpClient: &http.Client{},
+	}
+
+	start := time.Now()
+	text, err := client.Complete(context.Background(), "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "ok" || attempts != 2 {
+		t.Errorf("expected success on the second attempt, got %q after %d attempts", text, attempts)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected the 10ms server hint to be used, took %v", elapsed)
+	}
+}
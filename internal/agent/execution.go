@@ -2,6 +2,7 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"fmt"
 )
 
@@ -20,36 +21,49 @@ type Phase struct {
 
 // ExecutionAgent is Agent B - accepts verdict and produces minimal execution plan
 type ExecutionAgent struct {
-	client LLMClient
+	client     LLMClient
+	maxRepairs int
 }
 
 // NewExecutionAgent creates a new execution agent
 func NewExecutionAgent(client LLMClient) *ExecutionAgent {
 	return &ExecutionAgent{
-		client: client,
+		client:     client,
+		maxRepairs: DefaultMaxRepairs,
 	}
 }
 
+// SetMaxRepairs sets how many corrective turns the agent may take when its
+// output fails validation. Zero disables repair.
+func (a *ExecutionAgent) SetMaxRepairs(n int) {
+	a.maxRepairs = n
+}
+
 // Process takes a verdict and produces an actionable execution plan
 // It focuses on MINIMAL viable scope and concrete, measurable tasks
 func (a *ExecutionAgent) Process(ctx context.Context, verdict *VerdictOutput) (*ExecutionOutput, error) {
+	return a.ProcessStream(ctx, verdict, nil)
+}
+
+// ProcessStream is like Process but reports the raw model output through
+// onDelta as it is generated, when the LLM client supports streaming
+func (a *ExecutionAgent) ProcessStream(ctx context.Context, verdict *VerdictOutput, onDelta DeltaFunc) (*ExecutionOutput, error) {
 	if verdict == nil {
 		return nil, fmt.Errorf("verdict cannot be nil")
 	}
 
 	prompt := a.buildPrompt(verdict)
 
-	var result ExecutionOutput
-	if err := a.client.CompleteJSON(ctx, prompt, &result); err != nil {
+	// Call LLM, repairing plans that break the output constraints
+	result, err := completeWithRepair(ctx, a.client, "execution", prompt, a.maxRepairs, onDelta, a.validateOutput)
+	if err != nil {
+		if isValidationError(err) {
+			return nil, fmt.Errorf("invalid execution plan: %w", err)
+		}
 		return nil, fmt.Errorf("failed to generate execution plan: %w", err)
 	}
 
-	// Validate output constraints
-	if err := a.validateOutput(&result); err != nil {
-		return nil, fmt.Errorf("invalid execution plan: %w", err)
-	}
-
-	return &result, nil
+	return result, nil
 }
 
 // buildPrompt constructs the system prompt for Agent B
@@ -91,39 +105,42 @@ Focus on the absolute minimum needed to fulfill the ruling. Do not expand scope.
 Output ONLY the JSON, nothing else.`, verdict.Ruling, verdict.Rationale)
 }
 
-// validateOutput ensures the execution plan meets constraints
+// validateOutput ensures the execution plan meets constraints.
+// It reports every violation found so they can be sent back for repair.
 func (a *ExecutionAgent) validateOutput(output *ExecutionOutput) error {
+	var errs []error
+
 	// Check phases constraint
 	if len(output.Phases) > 3 {
-		return fmt.Errorf("too many phases: %d (maximum 3)", len(output.Phases))
+		errs = append(errs, fmt.Errorf("too many phases: %d (maximum 3)", len(output.Phases)))
 	}
 
 	if len(output.Phases) == 0 {
-		return fmt.Errorf("no phases defined")
+		errs = append(errs, fmt.Errorf("no phases defined"))
 	}
 
-	// Check tasks per phase constraint
+	// Check tasks per phase constraint (phases are numbered from 1 for the model)
 	for i, phase := range output.Phases {
 		if len(phase.Tasks) > 5 {
-			return fmt.Errorf("phase %d has too many tasks: %d (maximum 5)", i, len(phase.Tasks))
+			errs = append(errs, fmt.Errorf("phase %d has too many tasks: %d (maximum 5)", i+1, len(phase.Tasks)))
 		}
 		if len(phase.Tasks) == 0 {
-			return fmt.Errorf("phase %d has no tasks", i)
+			errs = append(errs, fmt.Errorf("phase %d has no tasks", i+1))
 		}
 		if phase.Name == "" {
-			return fmt.Errorf("phase %d has no name", i)
+			errs = append(errs, fmt.Errorf("phase %d has no name", i+1))
 		}
 	}
 
 	// Check MVP scope is defined
 	if len(output.MVPScope) == 0 {
-		return fmt.Errorf("no MVP scope defined")
+		errs = append(errs, fmt.Errorf("no MVP scope defined"))
 	}
 
 	// Check done criteria is defined
 	if len(output.DoneCriteria) == 0 {
-		return fmt.Errorf("no done criteria defined")
+		errs = append(errs, fmt.Errorf("no done criteria defined"))
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
@@ -0,0 +1,303 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Circuit breaker defaults
+const (
+	DefaultCircuitThreshold = 3                // Consecutive failures that open the circuit
+	DefaultCircuitCooldown  = 30 * time.Second // Time an open circuit rejects calls
+)
+
+// ErrCircuitOpen is returned when every provider in a fallback chain is skipped
+// because its circuit is open
+var ErrCircuitOpen = errors.New("circuit open")
+
+// CircuitState is the state of a provider's circuit breaker
+type CircuitState string
+
+// Circuit states
+const (
+	CircuitClosed   CircuitState = "closed"    // Calls pass through
+	CircuitOpen     CircuitState = "open"      // Calls are rejected until the cooldown ends
+	CircuitHalfOpen CircuitState = "half-open" // One trial call decides whether to close or reopen
+)
+
+// circuitBreaker tracks consecutive failures of one provider.
+// It is safe for concurrent use.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	trial     bool // A half-open trial call is in flight
+	now       func() time.Time
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if threshold <= 0 {
+		threshold = DefaultCircuitThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultCircuitCooldown
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     CircuitClosed,
+		now:       time.Now,
+	}
+}
+
+// State returns the current state
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow reports whether a call may be made. An open circuit whose cooldown has
+// ended lets a single trial call through.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.trial = true
+		return true
+	case CircuitHalfOpen:
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+// success closes the circuit
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = CircuitClosed
+	b.failures = 0
+	b.trial = false
+}
+
+// failure counts a failed call, opening the circuit at the threshold or when
+// a half-open trial fails
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// release ends a call that neither succeeded nor failed (e.g. the caller
+// cancelled it) without changing the state
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
+
+// FallbackProvider is one entry of a fallback chain
+type FallbackProvider struct {
+	Name   string // Used in logs, e.g. "anthropic" or "local:llama3.1"
+	Client LLMClient
+}
+
+// FallbackClient is an LLMClient that tries an ordered list of providers,
+// failing over on 5xx and overload responses, rate limits, timeouts and
+// connection errors. Each provider has a circuit breaker so a dead provider is
+// skipped quickly. Streaming calls only fail over before the first delta is
+// delivered.
+type FallbackClient struct {
+	members []fallbackMember
+}
+
+type fallbackMember struct {
+	name    string
+	client  LLMClient
+	breaker *circuitBreaker
+}
+
+// NewFallbackClient creates a fallback chain. Providers are tried in order.
+// Zero threshold or cooldown use the defaults.
+func NewFallbackClient(providers []FallbackProvider, threshold int, cooldown time.Duration) *FallbackClient {
+	members := make([]fallbackMember, len(providers))
+	for i, p := range providers {
+		members[i] = fallbackMember{
+			name:    p.Name,
+			client:  p.Client,
+			breaker: newCircuitBreaker(threshold, cooldown),
+		}
+	}
+	return &FallbackClient{members: members}
+}
+
+// Complete sends a prompt to the first available provider
+func (c *FallbackClient) Complete(ctx context.Context, prompt string) (string, error) {
+	var text string
+	err := c.do(ctx, nil, func(client LLMClient, _ DeltaFunc) error {
+		var err error
+		text, err = client.Complete(ctx, prompt)
+		return err
+	})
+	return text, err
+}
+
+// CompleteJSON requests structured output from the first available provider
+func (c *FallbackClient) CompleteJSON(ctx context.Context, prompt string, result any) error {
+	return c.do(ctx, nil, func(client LLMClient, _ DeltaFunc) error {
+		return client.CompleteJSON(ctx, prompt, result)
+	})
+}
+
+// Stream streams a completion from the first available provider. Providers
+// that cannot stream deliver their whole response as one delta.
+func (c *FallbackClient) Stream(ctx context.Context, prompt string, onDelta DeltaFunc) (string, error) {
+	var text string
+	err := c.do(ctx, onDelta, func(client LLMClient, onDelta DeltaFunc) error {
+		var err error
+		if s, ok := client.(StreamingLLMClient); ok {
+			text, err = s.Stream(ctx, prompt, onDelta)
+			return err
+		}
+		if text, err = client.Complete(ctx, prompt); err == nil && onDelta != nil {
+			onDelta(text)
+		}
+		return err
+	})
+	return text, err
+}
+
+// StreamJSON streams structured output from the first available provider
+func (c *FallbackClient) StreamJSON(ctx context.Context, prompt string, result any, onDelta DeltaFunc) error {
+	return c.do(ctx, onDelta, func(client LLMClient, onDelta DeltaFunc) error {
+		return completeJSONStream(ctx, client, prompt, result, onDelta)
+	})
+}
+
+// do runs call against each provider in turn until one succeeds or returns an
+// error that is not worth failing over
+func (c *FallbackClient) do(ctx context.Context, onDelta DeltaFunc, call func(client LLMClient, onDelta DeltaFunc) error) error {
+	started := false
+	var tracked DeltaFunc
+	if onDelta != nil {
+		tracked = func(text string) {
+			started = true
+			onDelta(text)
+		}
+	}
+
+	var lastErr error
+	for _, m := range c.members {
+		if !m.breaker.allow() {
+			log.Printf("LLM provider %s skipped: circuit open", m.name)
+			continue
+		}
+
+		err := call(m.client, tracked)
+		switch {
+		case err == nil:
+			m.breaker.success()
+			return nil
+		case ctx.Err() != nil:
+			m.breaker.release()
+			return err
+		case !shouldFailOver(err):
+			// The provider answered; the request itself is at fault
+			m.breaker.success()
+			return err
+		}
+
+		m.breaker.failure()
+		if started {
+			// Output already reached the caller; switching providers would garble it
+			return err
+		}
+		log.Printf("LLM provider %s failed, trying next provider: %v", m.name, err)
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		return fmt.Errorf("%w: no LLM provider available", ErrCircuitOpen)
+	}
+	return fmt.Errorf("all LLM providers failed: %w", lastErr)
+}
+
+// shouldFailOver reports whether err means the provider is unavailable
+func shouldFailOver(err error) bool {
+	if isRetryable(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ProviderSpec names a provider and an optional model
+type ProviderSpec struct {
+	Provider string
+	Model    string // Empty uses the provider default
+}
+
+// String formats the spec as "provider" or "provider:model"
+func (s ProviderSpec) String() string {
+	if s.Model == "" {
+		return s.Provider
+	}
+	return s.Provider + ":" + s.Model
+}
+
+// ParseProviderSpec parses "provider" or "provider:model", e.g. "openai" or
+// "local:qwen2.5:7b"
+func ParseProviderSpec(raw string) (ProviderSpec, error) {
+	provider, model, _ := strings.Cut(strings.TrimSpace(raw), ":")
+	switch provider {
+	case ProviderOpenAI, ProviderAnthropic, ProviderGemini, ProviderLocal:
+	default:
+		return ProviderSpec{}, fmt.Errorf("unsupported provider: %s", provider)
+	}
+	if provider == ProviderLocal && model == "" {
+		return ProviderSpec{}, fmt.Errorf("model is required for provider %s", provider)
+	}
+	return ProviderSpec{Provider: provider, Model: model}, nil
+}
+
+// ParseProviderChain parses a comma-separated list of providers with optional
+// models, e.g. "anthropic,local:llama3.1"
+func ParseProviderChain(raw string) ([]ProviderSpec, error) {
+	var chain []ProviderSpec
+	for _, entry := range strings.Split(raw, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		spec, err := ParseProviderSpec(entry)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, spec)
+	}
+	return chain, nil
+}
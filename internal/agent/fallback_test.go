@@ -0,0 +1,223 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// scriptedClient returns the given errors in order, then succeeds with text
+type scriptedClient struct {
+	errs  []error
+	text  string
+	calls int
+}
+
+func (m *scriptedClient) next() error {
+	m.calls++
+	if m.calls <= len(m.errs) {
+		return m.errs[m.calls-1]
+	}
+	return nil
+}
+
+func (m *scriptedClient) Complete(ctx context.Context, prompt string) (string, error) {
+	if err := m.next(); err != nil {
+		return "", err
+	}
+	return m.text, nil
+}
+
+func (m *scriptedClient) CompleteJSON(ctx context.Context, prompt string, result any) error {
+	if err := m.next(); err != nil {
+		return err
+	}
+	return decodeStructured(m.text, result)
+}
+
+// TestCircuitBreaker tests the closed, open and half-open transitions
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.failure()
+	if !b.allow() || b.State() != CircuitClosed {
+		t.Fatalf("expected closed below threshold, got %s", b.State())
+	}
+	b.failure()
+	if b.allow() || b.State() != CircuitOpen {
+		t.Fatalf("expected open at threshold, got %s", b.State())
+	}
+
+	// After the cooldown a single trial call is let through
+	now = now.Add(time.Minute)
+	if !b.allow() || b.State() != CircuitHalfOpen {
+		t.Fatalf("expected half-open trial, got %s", b.State())
+	}
+	if b.allow() {
+		t.Error("expected only one trial call while half-open")
+	}
+
+	// A failed trial reopens the circuit
+	b.failure()
+	if b.allow() || b.State() != CircuitOpen {
+		t.Fatalf("expected reopened circuit, got %s", b.State())
+	}
+
+	// A successful trial closes it
+	now = now.Add(time.Minute)
+	b.allow()
+	b.success()
+	if !b.allow() || b.State() != CircuitClosed {
+		t.Fatalf("expected closed after successful trial, got %s", b.State())
+	}
+}
+
+// TestFallbackClient tests failover between providers
+func TestFallbackClient(t *testing.T) {
+	t.Run("fails over on server errors", func(t *testing.T) {
+		primary := &scriptedClient{errs: []error{fmt.Errorf("%w (status 503): overloaded", ErrServerError)}}
+		secondary := &scriptedClient{text: "from secondary"}
+		client := NewFallbackClient([]FallbackProvider{{Name: "primary", Client: primary}, {Name: "secondary", Client: secondary}}, 0, 0)
+
+		text, err := client.Complete(context.Background(), "hi")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != "from secondary" {
+			t.Errorf("expected secondary response, got %q", text)
+		}
+	})
+
+	t.Run("does not fail over on request errors", func(t *testing.T) {
+		requestErr := errors.New("API error (status 400): bad request")
+		primary := &scriptedClient{errs: []error{requestErr}}
+		secondary := &scriptedClient{text: "unused"}
+		client := NewFallbackClient([]FallbackProvider{{Name: "primary", Client: primary}, {Name: "secondary", Client: secondary}}, 0, 0)
+
+		if _, err := client.Complete(context.Background(), "hi"); !errors.Is(err, requestErr) {
+			t.Fatalf("expected the request error, got %v", err)
+		}
+		if secondary.calls != 0 {
+			t.Errorf("expected secondary not to be called, got %d calls", secondary.calls)
+		}
+	})
+
+	t.Run("skips providers with an open circuit", func(t *testing.T) {
+		primary := &scriptedClient{errs: []error{ErrRateLimited, ErrRateLimited, ErrRateLimited}}
+		secondary := &scriptedClient{text: `{"ruling":"Use Go","rationale":"Fast","rejected":[]}`}
+		client := NewFallbackClient([]FallbackProvider{{Name: "primary", Client: primary}, {Name: "secondary", Client: secondary}}, 2, time.Minute)
+
+		for i := 0; i < 3; i++ {
+			var result VerdictOutput
+			if err := client.CompleteJSON(context.Background(), "hi", &result); err != nil {
+				t.Fatalf("call %d: unexpected error: %v", i, err)
+			}
+			if result.Ruling != "Use Go" {
+				t.Errorf("call %d: unexpected ruling %q", i, result.Ruling)
+			}
+		}
+		if primary.calls != 2 {
+			t.Errorf("expected primary to be skipped once its circuit opened, got %d calls", primary.calls)
+		}
+	})
+
+	t.Run("all providers failing", func(t *testing.T) {
+		client := NewFallbackClient([]FallbackProvider{
+			{Name: "a", Client: &scriptedClient{errs: []error{ErrTimeout}}},
+			{Name: "b", Client: &scriptedClient{errs: []error{ErrRateLimited}}},
+		}, 1, time.Minute)
+
+		if _, err := client.Complete(context.Background(), "hi"); !errors.Is(err, ErrRateLimited) {
+			t.Fatalf("expected the last provider's error, got %v", err)
+		}
+		if _, err := client.Complete(context.Background(), "hi"); !errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected ErrCircuitOpen once every circuit is open, got %v", err)
+		}
+	})
+}
+
+// stallingStreamClient streams a delta and then stalls
+type stallingStreamClient struct {
+	scriptedClient
+}
+
+func (m *stallingStreamClient) Stream(ctx context.Context, prompt string, onDelta DeltaFunc) (string, error) {
+	m.calls++
+	onDelta("partial")
+	return "", ErrStreamStalled
+}
+
+// TestFallbackClientStream tests that streams only fail over before output is delivered
+func TestFallbackClientStream(t *testing.T) {
+	secondary := &scriptedClient{text: "from secondary"}
+	client := NewFallbackClient([]FallbackProvider{
+		{Name: "primary", Client: &stallingStreamClient{}},
+		{Name: "secondary", Client: secondary},
+	}, 0, 0)
+
+	var deltas []string
+	_, err := client.Stream(context.Background(), "hi", func(d string) { deltas = append(deltas, d) })
+	if !errors.Is(err, ErrStreamStalled) {
+		t.Fatalf("expected ErrStreamStalled, got %v", err)
+	}
+	if secondary.calls != 0 || strings.Join(deltas, "") != "partial" {
+		t.Errorf("expected no failover after a delta, got %d secondary calls and deltas %q", secondary.calls, deltas)
+	}
+
+	// A non-streaming provider delivers its response as one delta
+	client = NewFallbackClient([]FallbackProvider{{Name: "secondary", Client: secondary}}, 0, 0)
+	deltas = nil
+	text, err := client.Stream(context.Background(), "hi", func(d string) { deltas = append(deltas, d) })
+	if err != nil || text != "from secondary" || len(deltas) != 1 {
+		t.Errorf("unexpected result %q, %v with deltas %q", text, err, deltas)
+	}
+}
+
+// TestParseProviderChain tests parsing of fallback provider lists
+func TestParseProviderChain(t *testing.T) {
+	chain, err := ParseProviderChain("anthropic, local:qwen2.5:7b,gemini")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []ProviderSpec{{Provider: "anthropic"}, {Provider: "local", Model: "qwen2.5:7b"}, {Provider: "gemini"}}
+	if fmt.Sprint(chain) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, chain)
+	}
+
+	for _, raw := range []string{"unknown", "local"} {
+		if _, err := ParseProviderChain(raw); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+}
+
+func TestParseProviderSpec(t *testing.T) {
+	tests := []struct {
+		raw     string
+		want    ProviderSpec
+		wantErr bool
+	}{
+		{raw: "openai", want: ProviderSpec{Provider: "openai"}},
+		{raw: "anthropic:claude-3-5-haiku-latest", want: ProviderSpec{Provider: "anthropic", Model: "claude-3-5-haiku-latest"}},
+		{raw: " local:qwen2.5:7b ", want: ProviderSpec{Provider: "local", Model: "qwen2.5:7b"}},
+		{raw: "local", wantErr: true},
+		{raw: "", wantErr: true},
+		{raw: "mistral:large", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseProviderSpec(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseProviderSpec(%q) error = %v, wantErr %v", tt.raw, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseProviderSpec(%q) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
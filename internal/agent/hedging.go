@@ -0,0 +1,242 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Quality flag codes
+const (
+	QualityHedging         = "hedging"          // Ruling or rationale uses hedging language
+	QualityMultipleOptions = "multiple_options" // Ruling names several alternatives
+)
+
+// QualityFlag reports a verdict that breaks the "judge, not consultant" contract
+type QualityFlag struct {
+	Code    string `json:"code"`             // QualityHedging or QualityMultipleOptions
+	Field   string `json:"field"`            // "ruling" or "rationale"
+	Phrase  string `json:"phrase,omitempty"` // The offending text
+	Message string `json:"message"`
+}
+
+// HedgingMode controls what happens when the linter finds a violation
+type HedgingMode string
+
+// Hedging modes
+const (
+	HedgingOff   HedgingMode = "off"   // Do not lint
+	HedgingFlag  HedgingMode = "flag"  // Report violations as quality flags
+	HedgingReask HedgingMode = "reask" // Send violations back for repair, then flag what remains
+)
+
+// ParseHedgingMode parses a hedging mode name
+func ParseHedgingMode(s string) (HedgingMode, error) {
+	switch mode := HedgingMode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case HedgingOff, HedgingFlag, HedgingReask:
+		return mode, nil
+	case "":
+		return HedgingFlag, nil
+	default:
+		return "", fmt.Errorf("unknown hedging mode %q (want off, flag or reask)", s)
+	}
+}
+
+// PhraseLists maps a language code ("en", "zh") to a list of phrases
+type PhraseLists map[string][]string
+
+// DefaultHedgingPhrases returns the phrases the verdict prompt forbids
+func DefaultHedgingPhrases() PhraseLists {
+	return PhraseLists{
+		"en": {
+			"maybe", "perhaps", "possibly", "probably", "it depends", "depending on",
+			"you could also", "you might", "you may want to", "alternatively",
+			"another option", "on the other hand", "up to you", "either way",
+		},
+		"zh": {
+			"可能", "也许", "或许", "大概", "看情况", "取决于", "视情况而定",
+			"你也可以", "另一个选择", "另一种选择", "都可以", "见仁见智",
+		},
+	}
+}
+
+// DefaultAlternativeMarkers returns words that join alternatives in a ruling.
+// A bare "or" is not one, since decisive rulings use it too ("do not shard
+// or cache yet"); it is checked structurally instead.
+func DefaultAlternativeMarkers() PhraseLists {
+	return PhraseLists{
+		"en": {"and/or", "whichever"},
+		"zh": {"或者", "或是", "要么", "任选", "二选一"},
+	}
+}
+
+// ParsePhraseLists parses a JSON object of per-language phrase lists, e.g.
+// {"en": ["maybe"], "zh": ["可能"]}. Languages present replace the matching
+// list in defaults; other languages keep their defaults.
+func ParsePhraseLists(raw string, defaults PhraseLists) (PhraseLists, error) {
+	lists := PhraseLists{}
+	for lang, phrases := range defaults {
+		lists[lang] = phrases
+	}
+	if raw == "" {
+		return lists, nil
+	}
+
+	var overrides PhraseLists
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid phrase lists: %w", err)
+	}
+	for lang, phrases := range overrides {
+		lists[lang] = phrases
+	}
+	return lists, nil
+}
+
+// HedgingLinter detects hedging language and multi-option rulings
+type HedgingLinter struct {
+	hedging      []phraseMatcher
+	alternatives []phraseMatcher
+}
+
+// phraseMatcher finds one phrase in text
+type phraseMatcher struct {
+	re *regexp.Regexp
+}
+
+// listItemRegex matches enumerated lines ("1. ...", "- ...", "• ...")
+var listItemRegex = regexp.MustCompile(`(?m)^\s*(?:\d+[.)、]|[-*•])\s+`)
+
+var (
+	// clauseEndRegex matches the end of a ruling's first clause
+	clauseEndRegex = regexp.MustCompile(`[,;:\n]|\.\s`)
+	// orRegex matches "or" as a word
+	orRegex = regexp.MustCompile(`(?i)\bor\b`)
+	// negationRegex matches words after which "or" lists things not to do
+	negationRegex = regexp.MustCompile(`(?i)\b(?:not|no|never|don't|without)\b`)
+)
+
+// NewHedgingLinter creates a linter. Nil lists use the defaults.
+func NewHedgingLinter(hedging, alternatives PhraseLists) *HedgingLinter {
+	if hedging == nil {
+		hedging = DefaultHedgingPhrases()
+	}
+	if alternatives == nil {
+		alternatives = DefaultAlternativeMarkers()
+	}
+	return &HedgingLinter{
+		hedging:      compilePhrases(hedging),
+		alternatives: compilePhrases(alternatives),
+	}
+}
+
+// compilePhrases builds case-insensitive matchers. Phrases starting and ending
+// with a letter or digit must match whole words; others (e.g. Chinese) match
+// anywhere.
+func compilePhrases(lists PhraseLists) []phraseMatcher {
+	langs := make([]string, 0, len(lists))
+	for lang := range lists {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+
+	var matchers []phraseMatcher
+	for _, lang := range langs {
+		for _, phrase := range lists[lang] {
+			phrase = strings.TrimSpace(phrase)
+			if phrase == "" {
+				continue
+			}
+			pattern := regexp.QuoteMeta(phrase)
+			if isWordChar(phrase[0]) && isWordChar(phrase[len(phrase)-1]) {
+				pattern = `\b` + pattern + `\b`
+			}
+			matchers = append(matchers, phraseMatcher{re: regexp.MustCompile(`(?i)` + pattern)})
+		}
+	}
+	return matchers
+}
+
+func isWordChar(b byte) bool {
+	return b == '_' || (b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// Lint checks the ruling and rationale and returns any violations
+func (l *HedgingLinter) Lint(v *VerdictOutput) []QualityFlag {
+	if l == nil || v == nil {
+		return nil
+	}
+
+	var flags []QualityFlag
+	for _, field := range []struct{ name, text string }{
+		{"ruling", v.Ruling},
+		{"rationale", v.Rationale},
+	} {
+		for _, m := range l.hedging {
+			if found := m.re.FindString(field.text); found != "" {
+				flags = append(flags, QualityFlag{
+					Code:    QualityHedging,
+					Field:   field.name,
+					Phrase:  found,
+					Message: fmt.Sprintf("%s uses hedging language %q", field.name, found),
+				})
+			}
+		}
+	}
+
+	// Structural check: a ruling must name exactly one choice
+	for _, m := range l.alternatives {
+		if found := m.re.FindString(v.Ruling); found != "" {
+			flags = append(flags, QualityFlag{
+				Code:    QualityMultipleOptions,
+				Field:   "ruling",
+				Phrase:  found,
+				Message: fmt.Sprintf("ruling offers alternatives (%q); name a single choice", found),
+			})
+		}
+	}
+	if found := leadingAlternative(v.Ruling); found != "" {
+		flags = append(flags, QualityFlag{
+			Code:    QualityMultipleOptions,
+			Field:   "ruling",
+			Phrase:  found,
+			Message: fmt.Sprintf("ruling offers alternatives (%q); name a single choice", found),
+		})
+	}
+	if items := listItemRegex.FindAllString(v.Ruling, -1); len(items) > 1 {
+		flags = append(flags, QualityFlag{
+			Code:    QualityMultipleOptions,
+			Field:   "ruling",
+			Message: fmt.Sprintf("ruling lists %d items; name a single choice", len(items)),
+		})
+	}
+
+	return flags
+}
+
+// leadingAlternative returns the "or" that joins alternatives in the first
+// clause of a ruling, as in "Use PostgreSQL or MySQL", or "" if there is none.
+// An "or" after a negation ("Use Postgres and do not shard or cache") or in a
+// later clause is not one.
+func leadingAlternative(ruling string) string {
+	clause := ruling
+	if loc := clauseEndRegex.FindStringIndex(ruling); loc != nil {
+		clause = ruling[:loc[0]]
+	}
+	loc := orRegex.FindStringIndex(clause)
+	if loc == nil || negationRegex.MatchString(clause[:loc[0]]) {
+		return ""
+	}
+	return clause[loc[0]:loc[1]]
+}
+
+// qualityError converts flags to a validation error for the repair loop
+func qualityError(flags []QualityFlag) error {
+	errs := make([]error, len(flags))
+	for i, f := range flags {
+		errs[i] = errors.New(f.Message)
+	}
+	return errors.Join(errs...)
+}
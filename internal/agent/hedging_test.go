@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// TestHedgingLinter tests detection of hedging and multi-option rulings
+func TestHedgingLinter(t *testing.T) {
+	linter := NewHedgingLinter(nil, nil)
+
+	tests := []struct {
+		name      string
+		verdict   VerdictOutput
+		wantCodes []string
+	}{
+		{
+			name:    "decisive english",
+			verdict: VerdictOutput{Ruling: "Use PostgreSQL", Rationale: "It handles the relational workload and the team knows it."},
+		},
+		{
+			name:    "decisive chinese",
+			verdict: VerdictOutput{Ruling: "使用 PostgreSQL", Rationale: "团队熟悉它，且适合关系型数据。"},
+		},
+		{
+			name:      "english hedging in rationale",
+			verdict:   VerdictOutput{Ruling: "Use PostgreSQL", Rationale: "It depends on your workload."},
+			wantCodes: []string{QualityHedging},
+		},
+		{
+			name:      "chinese hedging in ruling",
+			verdict:   VerdictOutput{Ruling: "也许使用 PostgreSQL", Rationale: "适合关系型数据。"},
+			wantCodes: []string{QualityHedging},
+		},
+		{
+			name:    "word boundaries",
+			verdict: VerdictOutput{Ruling: "Use Oracle for ordering", Rationale: "Probabilistic models are irrelevant here."},
+		},
+		{
+			name:      "alternatives in ruling",
+			verdict:   VerdictOutput{Ruling: "Use PostgreSQL or MySQL", Rationale: "Both are mature."},
+			wantCodes: []string{QualityMultipleOptions},
+		},
+		{
+			name:      "chinese alternatives in ruling",
+			verdict:   VerdictOutput{Ruling: "使用 PostgreSQL 或者 MySQL", Rationale: "都很成熟。"},
+			wantCodes: []string{QualityMultipleOptions},
+		},
+		{
+			name:      "enumerated ruling",
+			verdict:   VerdictOutput{Ruling: "1. Use PostgreSQL\n2. Use MySQL", Rationale: "Both are mature."},
+			wantCodes: []string{QualityMultipleOptions},
+		},
+		{
+			name:      "either or in ruling",
+			verdict:   VerdictOutput{Ruling: "Use either Node.js or Deno", Rationale: "Both run TypeScript."},
+			wantCodes: []string{QualityMultipleOptions},
+		},
+		{
+			name:    "or in a later clause of the ruling",
+			verdict: VerdictOutput{Ruling: "Use Postgres; do not shard or cache yet", Rationale: "One node handles the load."},
+		},
+		{
+			name:    "or after a negation in the ruling",
+			verdict: VerdictOutput{Ruling: "Use Postgres and do not shard or cache yet", Rationale: "One node handles the load."},
+		},
+		{
+			name:    "either without alternatives in the ruling",
+			verdict: VerdictOutput{Ruling: "Use Postgres, which fits either workload", Rationale: "It handles both."},
+		},
+		{
+			name:    "or in rationale is allowed",
+			verdict: VerdictOutput{Ruling: "Use PostgreSQL", Rationale: "MySQL or SQLite would not handle the load."},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := linter.Lint(&tt.verdict)
+			var codes []string
+			for _, f := range flags {
+				codes = append(codes, f.Code)
+			}
+			if strings.Join(codes, ",") != strings.Join(tt.wantCodes, ",") {
+				t.Errorf("expected codes %v, got %+v", tt.wantCodes, flags)
+			}
+		})
+	}
+}
+
+// TestParsePhraseLists tests per-language overrides of the default phrases
+func TestParsePhraseLists(t *testing.T) {
+	lists, err := ParsePhraseLists(`{"en": ["kinda"], "fr": ["peut-être"]}`, DefaultHedgingPhrases())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lists["en"]) != 1 || lists["en"][0] != "kinda" {
+		t.Errorf("expected en to be replaced, got %v", lists["en"])
+	}
+	if len(lists["zh"]) != len(DefaultHedgingPhrases()["zh"]) {
+		t.Errorf("expected zh defaults to be kept, got %v", lists["zh"])
+	}
+
+	linter := NewHedgingLinter(lists, nil)
+	if flags := linter.Lint(&VerdictOutput{Ruling: "Use Go", Rationale: "Maybe later, peut-être."}); len(flags) != 1 || flags[0].Phrase != "peut-être" {
+		t.Errorf("expected only the custom phrase to match, got %+v", flags)
+	}
+
+	if _, err := ParsePhraseLists("not json", nil); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+// TestParseHedgingMode tests hedging mode parsing
+func TestParseHedgingMode(t *testing.T) {
+	for input, want := range map[string]HedgingMode{"": HedgingFlag, "off": HedgingOff, " ReAsk ": HedgingReask} {
+		if got, err := ParseHedgingMode(input); err != nil || got != want {
+			t.Errorf("ParseHedgingMode(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseHedgingMode("strict"); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+}
+
+// TestVerdictAgent_HedgingReask tests that reask mode sends hedging back for repair
+func TestVerdictAgent_HedgingReask(t *testing.T) {
+	hedged := VerdictOutput{Ruling: "Maybe use Go", Rationale: "Fast builds", Rejected: []RejectedOption{}}
+	decisive := VerdictOutput{Ruling: "Use Go", Rationale: "Fast builds", Rejected: []RejectedOption{}}
+
+	t.Run("repaired", func(t *testing.T) {
+		client := &sequenceClient{outputs: []any{hedged, decisive}}
+		agent := NewVerdictAgent(client)
+		agent.SetHedgingLinter(NewHedgingLinter(nil, nil), HedgingReask)
+
+		result, err := agent.Process(context.Background(), "Go or Rust?")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if result.Ruling != "Use Go" {
+			t.Errorf("expected the repaired ruling, got %q", result.Ruling)
+		}
+		if len(client.prompts) != 2 || !strings.Contains(client.prompts[1], `hedging language "Maybe"`) {
+			t.Errorf("expected a repair prompt naming the hedge, got %d prompts", len(client.prompts))
+		}
+	})
+
+	t.Run("persistent hedging is flagged", func(t *testing.T) {
+		client := &sequenceClient{outputs: []any{hedged, hedged, hedged}}
+		agent := NewVerdictAgent(client)
+		agent.SetHedgingLinter(NewHedgingLinter(nil, nil), HedgingReask)
+
+		result, err := agent.Process(context.Background(), "Go or Rust?")
+		if err != nil {
+			t.Fatalf("expected hedging alone not to fail the verdict, got %v", err)
+		}
+		if flags := agent.QualityFlags(result); len(flags) != 1 || flags[0].Code != QualityHedging {
+			t.Errorf("expected a hedging flag, got %+v", flags)
+		}
+	})
+
+	t.Run("flag mode does not reask", func(t *testing.T) {
+		client := &sequenceClient{outputs: []any{hedged}}
+		agent := NewVerdictAgent(client)
+
+		if _, err := agent.Process(context.Background(), "Go or Rust?"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(client.prompts) != 1 {
+			t.Errorf("expected 1 LLM call, got %d", len(client.prompts))
+		}
+	})
+}
@@ -7,7 +7,6 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"regexp"
 	"strings"
@@ -19,6 +18,7 @@ var (
 	ErrRateLimited = errors.New("rate limited")
 	ErrTimeout     = errors.New("request timeout")
 	ErrInvalidJSON = errors.New("invalid JSON in response")
+	ErrServerError = errors.New("provider server error") // 5xx response
 )
 
 // LLMClient defines the interface for interacting with LLM providers
@@ -27,13 +27,37 @@ type LLMClient interface {
 	CompleteJSON(ctx context.Context, prompt string, result any) error
 }
 
+// Supported providers
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+	ProviderGemini    = "gemini"
+	ProviderLocal     = "local" // OpenAI-compatible local server (Ollama, vLLM, llama.cpp)
+)
+
+// Default API base URLs
+const (
+	DefaultOpenAIBaseURL    = "https://api.openai.com/v1"
+	DefaultAnthropicBaseURL = "https://api.anthropic.com/v1"
+	DefaultGeminiBaseURL    = "https://generativelanguage.googleapis.com/v1beta"
+	DefaultLocalBaseURL     = "http://localhost:11434/v1" // Ollama
+)
+
+// NoRetries is the Config.MaxRetries of a client that does not retry failed
+// calls, e.g. one in a fallback chain, where failing over is the retry
+const NoRetries = -1
+
 // Config holds the configuration for LLM clients
 type Config struct {
-	Provider   string        // "openai", "anthropic", or "gemini"
-	APIKey     string
-	Model      string        // "gpt-4o", "claude-sonnet-4-20250514", or "gemini-2.5-flash"
-	MaxRetries int
+	Provider   string // "openai", "anthropic", "gemini", or "local"
+	APIKey     string // Optional for "local"
+	Model      string // "gpt-4o", "claude-sonnet-4-20250514", or "gemini-2.5-flash"; required for "local"
+	BaseURL    string // API base URL; defaults to the provider's public endpoint
+	MaxRetries int    // Retries of a failed call; 0 for the default of 3, NoRetries for none
 	Timeout    time.Duration
+
+	// StreamIdleTimeout aborts a streaming response when no data arrives for this long
+	StreamIdleTimeout time.Duration
 }
 
 // NewLLMClient creates a new LLM client based on the configuration
@@ -41,37 +65,52 @@ func NewLLMClient(cfg Config) (LLMClient, error) {
 	// Set defaults
 	if cfg.MaxRetries == 0 {
 		cfg.MaxRetries = 3
+	} else if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
 	}
 	if cfg.Timeout == 0 {
 		cfg.Timeout = 5 * time.Minute
 	}
+	if cfg.StreamIdleTimeout == 0 {
+		cfg.StreamIdleTimeout = 60 * time.Second
+	}
 
-	// Set default models based on provider
+	// Set default models and base URLs based on provider
+	var defaultModel, defaultBaseURL string
+	switch cfg.Provider {
+	case ProviderOpenAI:
+		defaultModel, defaultBaseURL = "gpt-4o", DefaultOpenAIBaseURL
+	case ProviderAnthropic:
+		defaultModel, defaultBaseURL = "claude-sonnet-4-20250514", DefaultAnthropicBaseURL
+	case ProviderGemini:
+		defaultModel, defaultBaseURL = "gemini-2.5-flash", DefaultGeminiBaseURL
+	case ProviderLocal:
+		defaultBaseURL = DefaultLocalBaseURL
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", cfg.Provider)
+	}
 	if cfg.Model == "" {
-		switch cfg.Provider {
-		case "openai":
-			cfg.Model = "gpt-4o"
-		case "anthropic":
-			cfg.Model = "claude-sonnet-4-20250514"
-		case "gemini":
-			cfg.Model = "gemini-2.5-flash"
-		default:
-			return nil, fmt.Errorf("unsupported provider: %s", cfg.Provider)
+		if defaultModel == "" {
+			return nil, fmt.Errorf("model is required for provider %s", cfg.Provider)
 		}
+		cfg.Model = defaultModel
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = defaultBaseURL
 	}
 
 	switch cfg.Provider {
-	case "openai":
+	case ProviderOpenAI, ProviderLocal:
 		return &openAIClient{
 			config:     cfg,
 			httpClient: &http.Client{Timeout: cfg.Timeout},
 		}, nil
-	case "anthropic":
+	case ProviderAnthropic:
 		return &anthropicClient{
 			config:     cfg,
 			httpClient: &http.Client{Timeout: cfg.Timeout},
 		}, nil
-	case "gemini":
+	case ProviderGemini:
 		return &geminiClient{
 			config:     cfg,
 			httpClient: &http.Client{Timeout: cfg.Timeout},
@@ -81,15 +120,60 @@ func NewLLMClient(cfg Config) (LLMClient, error) {
 	}
 }
 
-// openAIClient implements LLMClient for OpenAI
+// openAIClient implements LLMClient for OpenAI and OpenAI-compatible servers
 type openAIClient struct {
 	config     Config
 	httpClient *http.Client
 }
 
+// endpoint returns the chat completions URL
+func (c *openAIClient) endpoint() string {
+	return baseURL(c.config, DefaultOpenAIBaseURL) + "/chat/completions"
+}
+
+// provider returns the provider name recorded with usage
+func (c *openAIClient) provider() string {
+	if c.config.Provider == "" {
+		return ProviderOpenAI
+	}
+	return c.config.Provider
+}
+
+// baseURL returns the configured base URL, or def if none is set
+func baseURL(cfg Config, def string) string {
+	if cfg.BaseURL == "" {
+		return def
+	}
+	return strings.TrimRight(cfg.BaseURL, "/")
+}
+
 type openAIRequest struct {
-	Model    string          `json:"model"`
-	Messages []openAIMessage `json:"messages"`
+	Model         string               `json:"model"`
+	Messages      []openAIMessage      `json:"messages"`
+	Stream        bool                 `json:"stream,omitempty"`
+	StreamOptions *openAIStreamOptions `json:"stream_options,omitempty"`
+
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"` // "json_schema"
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string         `json:"name"`
+	Schema map[string]any `json:"schema"`
+	Strict bool           `json:"strict"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
 }
 
 type openAIMessage struct {
@@ -101,6 +185,7 @@ type openAIResponse struct {
 	Choices []struct {
 		Message openAIMessage `json:"message"`
 	} `json:"choices"`
+	Usage *openAIUsage `json:"usage,omitempty"`
 	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
@@ -108,65 +193,57 @@ type openAIResponse struct {
 }
 
 func (c *openAIClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.complete(ctx, c.newRequest(prompt, nil))
+}
+
+// newRequest builds a chat completion request, constrained to schema if set
+func (c *openAIClient) newRequest(prompt string, schema *outputSchema) openAIRequest {
 	reqBody := openAIRequest{
 		Model: c.config.Model,
 		Messages: []openAIMessage{
 			{Role: "user", Content: prompt},
 		},
 	}
-
-	var lastErr error
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 2^attempt seconds
-			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
-			select {
-			case <-ctx.Done():
-				return "", ctx.Err()
-			case <-time.After(backoff):
-			}
-		}
-
-		response, err := c.makeRequest(ctx, reqBody)
-		if err != nil {
-			lastErr = err
-			// Retry on rate limit or timeout
-			if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTimeout) {
-				continue
-			}
-			return "", err
+	if schema != nil {
+		// Non-strict: strict mode rejects optional and free-form fields
+		reqBody.ResponseFormat = &openAIResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &openAIJSONSchema{Name: schema.Name, Schema: schema.Schema},
 		}
+	}
+	return reqBody
+}
 
-		if len(response.Choices) == 0 {
-			return "", errors.New("no response from OpenAI")
-		}
+func (c *openAIClient) complete(ctx context.Context, reqBody openAIRequest) (string, error) {
+	var response *openAIResponse
+	err := withRetry(ctx, c.config.MaxRetries, func() error {
+		var err error
+		response, err = c.makeRequest(ctx, reqBody)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
 
-		return response.Choices[0].Message.Content, nil
+	if len(response.Choices) == 0 {
+		return "", errors.New("no response from OpenAI")
 	}
 
-	return "", fmt.Errorf("max retries exceeded: %w", lastErr)
+	return response.Choices[0].Message.Content, nil
 }
 
+// CompleteJSON requests output matching result's JSON schema via response_format
 func (c *openAIClient) CompleteJSON(ctx context.Context, prompt string, result any) error {
-	response, err := c.Complete(ctx, prompt)
-	if err != nil {
-		return err
-	}
-
-	jsonContent, err := extractJSON(response)
+	response, err := c.complete(ctx, c.newRequest(prompt, newOutputSchema(result)))
 	if err != nil {
 		return err
 	}
 
-	if err := json.Unmarshal([]byte(jsonContent), result); err != nil {
-		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
-	}
-
-	return nil
+	return decodeStructured(response, result)
 }
 
 func (c *openAIClient) makeRequest(ctx context.Context, reqBody openAIRequest) (*openAIResponse, error) {
-	return c.makeRequestWithURL(ctx, reqBody, "https://api.openai.com/v1/chat/completions")
+	return c.makeRequestWithURL(ctx, reqBody, c.endpoint())
 }
 
 func (c *openAIClient) makeRequestWithURL(ctx context.Context, reqBody openAIRequest, url string) (*openAIResponse, error) {
@@ -181,7 +258,9 @@ func (c *openAIClient) makeRequestWithURL(ctx context.Context, reqBody openAIReq
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -197,12 +276,8 @@ func (c *openAIClient) makeRequestWithURL(ctx context.Context, reqBody openAIReq
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, ErrRateLimited
-	}
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newProviderError(c.provider(), resp, body)
 	}
 
 	var response openAIResponse
@@ -211,9 +286,19 @@ func (c *openAIClient) makeRequestWithURL(ctx context.Context, reqBody openAIReq
 	}
 
 	if response.Error != nil {
-		return nil, fmt.Errorf("OpenAI API error: %s", response.Error.Message)
+		return nil, newBodyError(c.provider(), response.Error.Type, response.Error.Message)
 	}
 
+	// Recorded even without a usage block so the serving provider is known
+	var usage Usage
+	if response.Usage != nil {
+		usage = Usage{
+			InputTokens:  response.Usage.PromptTokens,
+			OutputTokens: response.Usage.CompletionTokens,
+		}
+	}
+	recordUsage(ctx, c.provider(), c.config.Model, usage)
+
 	return &response, nil
 }
 
@@ -227,6 +312,32 @@ type anthropicRequest struct {
 	Model     string             `json:"model"`
 	Messages  []anthropicMessage `json:"messages"`
 	MaxTokens int                `json:"max_tokens"`
+	Stream    bool               `json:"stream,omitempty"`
+
+	Tools      []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"` // "tool" forces the named tool
+	Name string `json:"name"`
+}
+
+type anthropicContentBlock struct {
+	Type  string          `json:"type,omitempty"` // "text" or "tool_use"
+	Text  string          `json:"text"`
+	Input json.RawMessage `json:"input,omitempty"` // Tool arguments for "tool_use"
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
 }
 
 type anthropicMessage struct {
@@ -235,16 +346,21 @@ type anthropicMessage struct {
 }
 
 type anthropicResponse struct {
-	Content []struct {
-		Text string `json:"text"`
-	} `json:"content"`
-	Error *struct {
+	Content []anthropicContentBlock `json:"content"`
+	Usage   *anthropicUsage         `json:"usage,omitempty"`
+	Error   *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
 	} `json:"error,omitempty"`
 }
 
 func (c *anthropicClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.complete(ctx, c.newRequest(prompt, nil))
+}
+
+// newRequest builds a messages request. If schema is set, the model is forced
+// to answer by calling a single tool whose input schema is the output schema.
+func (c *anthropicClient) newRequest(prompt string, schema *outputSchema) anthropicRequest {
 	reqBody := anthropicRequest{
 		Model: c.config.Model,
 		Messages: []anthropicMessage{
@@ -252,59 +368,55 @@ func (c *anthropicClient) Complete(ctx context.Context, prompt string) (string,
 		},
 		MaxTokens: 4096,
 	}
+	if schema != nil {
+		reqBody.Tools = []anthropicTool{{
+			Name:        schema.Name,
+			Description: "Record the response in the required structure.",
+			InputSchema: schema.Schema,
+		}}
+		reqBody.ToolChoice = &anthropicToolChoice{Type: "tool", Name: schema.Name}
+	}
+	return reqBody
+}
 
-	var lastErr error
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 2^attempt seconds
-			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
-			select {
-			case <-ctx.Done():
-				return "", ctx.Err()
-			case <-time.After(backoff):
-			}
-		}
+func (c *anthropicClient) complete(ctx context.Context, reqBody anthropicRequest) (string, error) {
 
-		response, err := c.makeRequest(ctx, reqBody)
-		if err != nil {
-			lastErr = err
-			// Retry on rate limit or timeout
-			if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTimeout) {
-				continue
-			}
-			return "", err
-		}
+	var response *anthropicResponse
+	err := withRetry(ctx, c.config.MaxRetries, func() error {
+		var err error
+		response, err = c.makeRequest(ctx, reqBody)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
 
-		if len(response.Content) == 0 {
-			return "", errors.New("no response from Anthropic")
-		}
+	if len(response.Content) == 0 {
+		return "", errors.New("no response from Anthropic")
+	}
 
-		return response.Content[0].Text, nil
+	// Forced tool use returns the structured output as the tool input
+	for _, block := range response.Content {
+		if block.Type == "tool_use" {
+			return string(block.Input), nil
+		}
 	}
 
-	return "", fmt.Errorf("max retries exceeded: %w", lastErr)
+	return response.Content[0].Text, nil
 }
 
+// CompleteJSON requests output matching result's JSON schema via forced tool use
 func (c *anthropicClient) CompleteJSON(ctx context.Context, prompt string, result any) error {
-	response, err := c.Complete(ctx, prompt)
-	if err != nil {
-		return err
-	}
-
-	jsonContent, err := extractJSON(response)
+	response, err := c.complete(ctx, c.newRequest(prompt, newOutputSchema(result)))
 	if err != nil {
 		return err
 	}
 
-	if err := json.Unmarshal([]byte(jsonContent), result); err != nil {
-		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
-	}
-
-	return nil
+	return decodeStructured(response, result)
 }
 
 func (c *anthropicClient) makeRequest(ctx context.Context, reqBody anthropicRequest) (*anthropicResponse, error) {
-	return c.makeRequestWithURL(ctx, reqBody, "https://api.anthropic.com/v1/messages")
+	return c.makeRequestWithURL(ctx, reqBody, baseURL(c.config, DefaultAnthropicBaseURL)+"/messages")
 }
 
 func (c *anthropicClient) makeRequestWithURL(ctx context.Context, reqBody anthropicRequest, url string) (*anthropicResponse, error) {
@@ -336,12 +448,8 @@ func (c *anthropicClient) makeRequestWithURL(ctx context.Context, reqBody anthro
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, ErrRateLimited
-	}
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newProviderError("anthropic", resp, body)
 	}
 
 	var response anthropicResponse
@@ -350,9 +458,18 @@ func (c *anthropicClient) makeRequestWithURL(ctx context.Context, reqBody anthro
 	}
 
 	if response.Error != nil {
-		return nil, fmt.Errorf("Anthropic API error: %s", response.Error.Message)
+		return nil, newBodyError("anthropic", response.Error.Type, response.Error.Message)
 	}
 
+	var usage Usage
+	if response.Usage != nil {
+		usage = Usage{
+			InputTokens:  response.Usage.InputTokens,
+			OutputTokens: response.Usage.OutputTokens,
+		}
+	}
+	recordUsage(ctx, "anthropic", c.config.Model, usage)
+
 	return &response, nil
 }
 
@@ -363,7 +480,13 @@ type geminiClient struct {
 }
 
 type geminiRequest struct {
-	Contents []geminiContent `json:"contents"`
+	Contents         []geminiContent         `json:"contents"`
+	GenerationConfig *geminiGenerationConfig `json:"generationConfig,omitempty"`
+}
+
+type geminiGenerationConfig struct {
+	ResponseMimeType string         `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]any `json:"responseSchema,omitempty"`
 }
 
 type geminiContent struct {
@@ -374,19 +497,30 @@ type geminiPart struct {
 	Text string `json:"text"`
 }
 
+type geminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+}
+
 type geminiResponse struct {
 	Candidates []struct {
 		Content struct {
 			Parts []geminiPart `json:"parts"`
 		} `json:"content"`
 	} `json:"candidates"`
-	Error *struct {
+	UsageMetadata *geminiUsageMetadata `json:"usageMetadata,omitempty"`
+	Error         *struct {
 		Message string `json:"message"`
 		Code    int    `json:"code"`
 	} `json:"error,omitempty"`
 }
 
 func (c *geminiClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return c.complete(ctx, c.newRequest(prompt, nil))
+}
+
+// newRequest builds a generateContent request, constrained to schema if set
+func (c *geminiClient) newRequest(prompt string, schema *outputSchema) geminiRequest {
 	reqBody := geminiRequest{
 		Contents: []geminiContent{
 			{
@@ -396,59 +530,46 @@ func (c *geminiClient) Complete(ctx context.Context, prompt string) (string, err
 			},
 		},
 	}
-
-	var lastErr error
-	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
-		if attempt > 0 {
-			// Exponential backoff: 2^attempt seconds
-			backoff := time.Duration(math.Pow(2, float64(attempt))) * time.Second
-			select {
-			case <-ctx.Done():
-				return "", ctx.Err()
-			case <-time.After(backoff):
-			}
+	if schema != nil {
+		reqBody.GenerationConfig = &geminiGenerationConfig{
+			ResponseMimeType: "application/json",
+			ResponseSchema:   geminiSchema(schema.Schema),
 		}
+	}
+	return reqBody
+}
 
-		response, err := c.makeRequest(ctx, reqBody)
-		if err != nil {
-			lastErr = err
-			// Retry on rate limit or timeout
-			if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrTimeout) {
-				continue
-			}
-			return "", err
-		}
+func (c *geminiClient) complete(ctx context.Context, reqBody geminiRequest) (string, error) {
 
-		if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
-			return "", errors.New("no response from Gemini")
-		}
+	var response *geminiResponse
+	err := withRetry(ctx, c.config.MaxRetries, func() error {
+		var err error
+		response, err = c.makeRequest(ctx, reqBody)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
 
-		return response.Candidates[0].Content.Parts[0].Text, nil
+	if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
+		return "", errors.New("no response from Gemini")
 	}
 
-	return "", fmt.Errorf("max retries exceeded: %w", lastErr)
+	return response.Candidates[0].Content.Parts[0].Text, nil
 }
 
+// CompleteJSON requests output matching result's JSON schema via responseSchema
 func (c *geminiClient) CompleteJSON(ctx context.Context, prompt string, result any) error {
-	response, err := c.Complete(ctx, prompt)
+	response, err := c.complete(ctx, c.newRequest(prompt, newOutputSchema(result)))
 	if err != nil {
 		return err
 	}
 
-	jsonContent, err := extractJSON(response)
-	if err != nil {
-		return err
-	}
-
-	if err := json.Unmarshal([]byte(jsonContent), result); err != nil {
-		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
-	}
-
-	return nil
+	return decodeStructured(response, result)
 }
 
 func (c *geminiClient) makeRequest(ctx context.Context, reqBody geminiRequest) (*geminiResponse, error) {
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent", c.config.Model)
+	url := fmt.Sprintf("%s/models/%s:generateContent", baseURL(c.config, DefaultGeminiBaseURL), c.config.Model)
 	return c.makeRequestWithURL(ctx, reqBody, url)
 }
 
@@ -480,12 +601,8 @@ func (c *geminiClient) makeRequestWithURL(ctx context.Context, reqBody geminiReq
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, ErrRateLimited
-	}
-
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, newProviderError("gemini", resp, body)
 	}
 
 	var response geminiResponse
@@ -494,13 +611,23 @@ func (c *geminiClient) makeRequestWithURL(ctx context.Context, reqBody geminiReq
 	}
 
 	if response.Error != nil {
-		return nil, fmt.Errorf("Gemini API error: %s", response.Error.Message)
+		return nil, newBodyError("gemini", "", response.Error.Message)
+	}
+
+	var usage Usage
+	if response.UsageMetadata != nil {
+		usage = Usage{
+			InputTokens:  response.UsageMetadata.PromptTokenCount,
+			OutputTokens: response.UsageMetadata.CandidatesTokenCount,
+		}
 	}
+	recordUsage(ctx, "gemini", c.config.Model, usage)
 
 	return &response, nil
 }
 
-// extractJSON extracts JSON content from LLM response (between ```json and ```)
+// extractJSON extracts JSON content from LLM response (between ```json and ```).
+// It is the fallback for providers or models that ignore the requested schema.
 func extractJSON(response string) (string, error) {
 	// Try to find JSON block marked with ```json
 	jsonBlockRegex := regexp.MustCompile("(?s)```json\\s*\\n(.*?)\\n```")
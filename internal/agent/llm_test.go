@@ -46,6 +46,22 @@ func TestNewLLMClient(t *testing.T) {
 			wantType:  "openai",
 			wantError: false,
 		},
+		{
+			name: "Local client with model",
+			config: Config{
+				Provider: "local",
+				Model:    "llama3.1",
+			},
+			wantType:  "openai",
+			wantError: false,
+		},
+		{
+			name: "Local client without model",
+			config: Config{
+				Provider: "local",
+			},
+			wantError: true,
+		},
 		{
 			name: "Unsupported provider",
 			config: Config{
@@ -88,6 +104,46 @@ func TestNewLLMClient(t *testing.T) {
 	}
 }
 
+// TestLocalProvider tests an OpenAI-compatible local server reached through a configured base URL
+func TestLocalProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("expected no Authorization header without an API key, got %q", auth)
+		}
+		var req openAIRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != "llama3.1" {
+			t.Errorf("expected model llama3.1, got %s", req.Model)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"local response"}}],"usage":{"prompt_tokens":7,"completion_tokens":3}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewLLMClient(Config{Provider: "local", Model: "llama3.1", BaseURL: server.URL + "/v1/"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	recorder := NewUsageRecorder()
+	ctx := WithUsageRecorder(context.Background(), recorder)
+	result, err := client.Complete(ctx, "hi")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "local response" {
+		t.Errorf("unexpected result: %q", result)
+	}
+	if records := recorder.Records(); len(records) != 1 || records[0].Provider != "local" {
+		t.Errorf("expected usage recorded for the local provider, got %+v", records)
+	}
+}
+
 // TestOpenAIComplete tests OpenAI completion
 func TestOpenAIComplete(t *testing.T) {
 	tests := []struct {
@@ -190,7 +246,7 @@ func TestOpenAIComplete(t *testing.T) {
 				response, err := client.makeRequestWithURL(ctx, reqBody, server.URL)
 				if err != nil {
 					lastErr = err
-					if err == ErrRateLimited {
+					if errors.Is(err, ErrRateLimited) {
 						continue
 					}
 					break
@@ -235,9 +291,7 @@ func TestAnthropicComplete(t *testing.T) {
 			name: "successful completion",
 			handler: func(w http.ResponseWriter, r *http.Request) {
 				response := anthropicResponse{
-					Content: []struct {
-						Text string `json:"text"`
-					}{
+					Content: []anthropicContentBlock{
 						{Text: "Hello from Claude!"},
 					},
 				}
@@ -258,9 +312,7 @@ func TestAnthropicComplete(t *testing.T) {
 						return
 					}
 					response := anthropicResponse{
-						Content: []struct {
-							Text string `json:"text"`
-						}{
+						Content: []anthropicContentBlock{
 							{Text: "Success after retry"},
 						},
 					}
@@ -275,9 +327,7 @@ func TestAnthropicComplete(t *testing.T) {
 			name: "empty response",
 			handler: func(w http.ResponseWriter, r *http.Request) {
 				response := anthropicResponse{
-					Content: []struct {
-						Text string `json:"text"`
-					}{},
+					Content: []anthropicContentBlock{},
 				}
 				w.Header().Set("Content-Type", "application/json")
 				json.NewEncoder(w).Encode(response)
@@ -317,7 +367,7 @@ func TestAnthropicComplete(t *testing.T) {
 				response, err := client.makeRequestWithURL(ctx, reqBody, server.URL)
 				if err != nil {
 					lastErr = err
-					if err == ErrRateLimited {
+					if errors.Is(err, ErrRateLimited) {
 						continue
 					}
 					break
@@ -615,7 +665,7 @@ func TestRetryExponentialBackoff(t *testing.T) {
 		_, err := client.makeRequestWithURL(ctx, reqBody, server.URL)
 		if err != nil {
 			lastErr = err
-			if err == ErrRateLimited {
+			if errors.Is(err, ErrRateLimited) {
 				continue
 			}
 			break
@@ -674,7 +724,7 @@ func TestConfigDefaults(t *testing.T) {
 			},
 			wantMaxRetries: 3,
 			wantTimeout:    5 * time.Minute,
-			wantModel:      "claude-3-opus-20240229",
+			wantModel:      "claude-sonnet-4-20250514",
 		},
 		{
 			name: "Custom values preserved",
@@ -689,6 +739,17 @@ func TestConfigDefaults(t *testing.T) {
 			wantTimeout:    10 * time.Minute,
 			wantModel:      "gpt-3.5-turbo",
 		},
+		{
+			name: "No retries",
+			inputConfig: Config{
+				Provider:   "anthropic",
+				APIKey:     "test-key",
+				MaxRetries: NoRetries,
+			},
+			wantMaxRetries: 0,
+			wantTimeout:    5 * time.Minute,
+			wantModel:      "claude-sonnet-4-20250514",
+		},
 	}
 
 	for _, tt := range tests {
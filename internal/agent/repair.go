@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxRepairs is the number of corrective turns an agent may take when
+// its output fails validation
+const DefaultMaxRepairs = 2
+
+// ValidationError is returned when agent output still fails validation after
+// all repair attempts. It wraps the validation errors of the last output.
+type ValidationError struct {
+	Err     error
+	Repairs int // Corrective turns taken
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// isValidationError reports whether err is a *ValidationError
+func isValidationError(err error) bool {
+	var verr *ValidationError
+	return errors.As(err, &verr)
+}
+
+// RepairAttempt records one corrective turn sent back to the model
+type RepairAttempt struct {
+	Agent    string          `json:"agent"`    // "verdict" or "execution"
+	Attempt  int             `json:"attempt"`  // 1-based repair number
+	Problems []string        `json:"problems"` // Validation errors sent to the model
+	Output   json.RawMessage `json:"output"`   // The rejected output
+	Repaired bool            `json:"repaired"` // Whether the corrected output passed validation
+}
+
+// RepairLog collects repair attempts made with a context.
+// It is safe for concurrent use.
+type RepairLog struct {
+	mu       sync.Mutex
+	attempts []RepairAttempt
+}
+
+// NewRepairLog creates an empty repair log
+func NewRepairLog() *RepairLog {
+	return &RepairLog{}
+}
+
+// Attempts returns a copy of the recorded attempts in order
+func (l *RepairLog) Attempts() []RepairAttempt {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	attempts := make([]RepairAttempt, len(l.attempts))
+	copy(attempts, l.attempts)
+	return attempts
+}
+
+func (l *RepairLog) add(a RepairAttempt) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.attempts = append(l.attempts, a)
+}
+
+type repairLogKey struct{}
+
+// WithRepairLog returns a context that records repair attempts made with it
+func WithRepairLog(ctx context.Context, l *RepairLog) context.Context {
+	return context.WithValue(ctx, repairLogKey{}, l)
+}
+
+// recordRepair reports a repair attempt to the context's log, if any
+func recordRepair(ctx context.Context, a RepairAttempt) {
+	if l, ok := ctx.Value(repairLogKey{}).(*RepairLog); ok && l != nil {
+		l.add(a)
+	}
+}
+
+// validationProblems splits a validation error into its individual messages
+func validationProblems(err error) []string {
+	var problems []string
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			problems = append(problems, line)
+		}
+	}
+	return problems
+}
+
+// buildRepairPrompt asks the model to correct output that failed validation
+func buildRepairPrompt(prompt string, output []byte, problems []string) string {
+	var sb strings.Builder
+	sb.WriteString(prompt)
+	sb.WriteString("\n\n--- CORRECTION REQUIRED ---\n")
+	sb.WriteString("Your previous response was rejected because it failed validation:\n")
+	for _, p := range problems {
+		sb.WriteString("- ")
+		sb.WriteString(p)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nPrevious response:\n")
+	sb.Write(output)
+	sb.WriteString("\n\nFix every problem listed above and change nothing else. ")
+	sb.WriteString("Keep the same language. Output ONLY the corrected JSON.")
+	return sb.String()
+}
+
+// completeWithRepair requests JSON output and, while it fails validation,
+// sends the output and the validation errors back to the model for up to
+// maxRepairs corrective turns. Corrective turns are not streamed.
+// If the output is still invalid it returns the last output together with a
+// *ValidationError.
+func completeWithRepair[T any](ctx context.Context, client LLMClient, agentName string, prompt string, maxRepairs int, onDelta DeltaFunc, validate func(*T) error) (*T, error) {
+	var result T
+	if err := completeJSONStream(ctx, client, prompt, &result, onDelta); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		verr := validate(&result)
+		if verr == nil {
+			return &result, nil
+		}
+		if attempt > maxRepairs {
+			return &result, &ValidationError{Err: verr, Repairs: attempt - 1}
+		}
+
+		output, err := json.Marshal(result)
+		if err != nil {
+			return &result, &ValidationError{Err: verr, Repairs: attempt - 1}
+		}
+		problems := validationProblems(verr)
+		log.Printf("%s agent output failed validation, requesting repair %d/%d: %s", agentName, attempt, maxRepairs, strings.Join(problems, "; "))
+
+		var repaired T
+		if err := client.CompleteJSON(ctx, buildRepairPrompt(prompt, output, problems), &repaired); err != nil {
+			recordRepair(ctx, RepairAttempt{Agent: agentName, Attempt: attempt, Problems: problems, Output: output})
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil, err
+			}
+			return &result, &ValidationError{Err: fmt.Errorf("%w (repair failed: %v)", verr, err), Repairs: attempt}
+		}
+
+		recordRepair(ctx, RepairAttempt{
+			Agent:    agentName,
+			Attempt:  attempt,
+			Problems: problems,
+			Output:   output,
+			Repaired: validate(&repaired) == nil,
+		})
+		result = repaired
+	}
+}
@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func validPlan() ExecutionOutput {
+	return ExecutionOutput{
+		MVPScope:     []string{"Core feature"},
+		Phases:       []Phase{{Name: "Build", Tasks: []string{"Task 1"}}},
+		DoneCriteria: []string{"It works"},
+	}
+}
+
+// sequenceClient returns the given outputs in order from CompleteJSON and
+// records the prompts it received
+type sequenceClient struct {
+	outputs []any
+	prompts []string
+}
+
+func (m *sequenceClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return "", nil
+}
+
+func (m *sequenceClient) CompleteJSON(ctx context.Context, prompt string, result any) error {
+	m.prompts = append(m.prompts, prompt)
+	if len(m.prompts) > len(m.outputs) {
+		return errors.New("unexpected call")
+	}
+	data, _ := json.Marshal(m.outputs[len(m.prompts)-1])
+	return json.Unmarshal(data, result)
+}
+
+func TestExecutionAgent_RepairsInvalidPlan(t *testing.T) {
+	invalid := validPlan()
+	invalid.Phases = []Phase{
+		{Name: "One", Tasks: []string{"a"}},
+		{Name: "Two", Tasks: []string{"a", "b", "c", "d", "e", "f"}},
+		{Name: "Three", Tasks: []string{"a"}},
+		{Name: "Four", Tasks: []string{"a"}},
+	}
+
+	client := &sequenceClient{outputs: []any{invalid, validPlan()}}
+	agent := NewExecutionAgent(client)
+
+	repairs := NewRepairLog()
+	ctx := WithRepairLog(context.Background(), repairs)
+
+	result, err := agent.Process(ctx, &VerdictOutput{Ruling: "Build it", Rationale: "Because"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Phases) != 1 {
+		t.Errorf("expected the repaired plan, got %d phases", len(result.Phases))
+	}
+
+	if len(client.prompts) != 2 {
+		t.Fatalf("expected 2 LLM calls, got %d", len(client.prompts))
+	}
+	repairPrompt := client.prompts[1]
+	for _, want := range []string{
+		"too many phases: 4 (maximum 3)",
+		"phase 2 has too many tasks: 6 (maximum 5)",
+		`"name":"Four"`,
+		"Output ONLY the corrected JSON",
+	} {
+		if !strings.Contains(repairPrompt, want) {
+			t.Errorf("repair prompt missing %q", want)
+		}
+	}
+
+	attempts := repairs.Attempts()
+	if len(attempts) != 1 {
+		t.Fatalf("expected 1 recorded attempt, got %d", len(attempts))
+	}
+	if attempts[0].Agent != "execution" || !attempts[0].Repaired || len(attempts[0].Problems) != 2 {
+		t.Errorf("unexpected attempt: %+v", attempts[0])
+	}
+}
+
+func TestExecutionAgent_RepairExhausted(t *testing.T) {
+	invalid := validPlan()
+	invalid.DoneCriteria = nil
+
+	client := &sequenceClient{outputs: []any{invalid, invalid, invalid}}
+	agent := NewExecutionAgent(client)
+
+	_, err := agent.Process(context.Background(), &VerdictOutput{Ruling: "Build it", Rationale: "Because"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	if verr.Repairs != DefaultMaxRepairs {
+		t.Errorf("expected %d repairs, got %d", DefaultMaxRepairs, verr.Repairs)
+	}
+	if len(client.prompts) != DefaultMaxRepairs+1 {
+		t.Errorf("expected %d LLM calls, got %d", DefaultMaxRepairs+1, len(client.prompts))
+	}
+}
+
+func TestVerdictAgent_RepairDisabled(t *testing.T) {
+	client := &sequenceClient{outputs: []any{VerdictOutput{Rationale: "No ruling"}}}
+	agent := NewVerdictAgent(client)
+	agent.SetMaxRepairs(0)
+
+	_, err := agent.Process(context.Background(), "Should I use Go?")
+	if !errors.Is(err, ErrEmptyRuling) {
+		t.Fatalf("expected ErrEmptyRuling, got %v", err)
+	}
+	if len(client.prompts) != 1 {
+		t.Errorf("expected no repair call, got %d calls", len(client.prompts))
+	}
+}
+
+func TestVerdictAgent_EmptyRationaleNotRepaired(t *testing.T) {
+	client := &sequenceClient{outputs: []any{VerdictOutput{Ruling: "Use Go"}}}
+	agent := NewVerdictAgent(client)
+
+	// The pipeline rejects it; the agent only repairs what it validates
+	verdict, err := agent.Process(context.Background(), "Should I use Go?")
+	if err != nil || verdict.Ruling != "Use Go" {
+		t.Fatalf("Process() = %+v, %v", verdict, err)
+	}
+	if len(client.prompts) != 1 {
+		t.Errorf("expected no repair call, got %d calls", len(client.prompts))
+	}
+}
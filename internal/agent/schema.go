@@ -0,0 +1,169 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// outputSchema describes the JSON a structured-output request must produce
+type outputSchema struct {
+	Name   string         // Identifier sent to the provider (Go type name)
+	Schema map[string]any // JSON Schema derived from the Go type
+}
+
+// newOutputSchema derives the output schema for the value CompleteJSON decodes
+// into. It returns nil if result is not a pointer to a struct, in which case
+// the caller falls back to free-text JSON extraction.
+func newOutputSchema(result any) *outputSchema {
+	t := reflect.TypeOf(result)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	return &outputSchema{
+		Name:   t.Elem().Name(),
+		Schema: jsonSchemaFor(t.Elem()),
+	}
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// jsonSchemaFor derives a JSON Schema from a Go type using its json tags.
+// Fields without omitempty are required; interface{} fields accept any value.
+func jsonSchemaFor(t reflect.Type) map[string]any {
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return jsonSchemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"} // []byte is base64 encoded
+		}
+		return map[string]any{"type": "array", "items": jsonSchemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": jsonSchemaFor(t.Elem())}
+	case reflect.Struct:
+		properties := map[string]any{}
+		required := []string{}
+		addStructFields(t, properties, &required)
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	default:
+		// interface{} and anything else: no constraint
+		return map[string]any{}
+	}
+}
+
+// addStructFields adds the JSON fields of a struct, flattening embedded structs
+func addStructFields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			addStructFields(field.Type, properties, required)
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = jsonSchemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// geminiSchema converts a JSON Schema to the OpenAPI subset accepted by
+// Gemini's responseSchema: upper-case types, no additionalProperties, and no
+// unconstrained (any-typed) properties.
+func geminiSchema(schema map[string]any) map[string]any {
+	out := map[string]any{}
+	for key, value := range schema {
+		switch key {
+		case "additionalProperties":
+			continue
+		case "type":
+			out[key] = strings.ToUpper(fmt.Sprint(value))
+		case "items":
+			out[key] = geminiSchema(value.(map[string]any))
+		case "properties":
+			properties := map[string]any{}
+			for name, prop := range value.(map[string]any) {
+				if p := prop.(map[string]any); len(p) > 0 {
+					properties[name] = geminiSchema(p)
+				}
+			}
+			out[key] = properties
+		default:
+			out[key] = value
+		}
+	}
+
+	// Drop required entries whose property was removed
+	if required, ok := out["required"].([]string); ok {
+		properties, _ := out["properties"].(map[string]any)
+		kept := []string{}
+		for _, name := range required {
+			if _, ok := properties[name]; ok {
+				kept = append(kept, name)
+			}
+		}
+		out["required"] = kept
+	}
+
+	return out
+}
+
+// decodeStructured decodes a structured-output response into result, falling
+// back to extracting JSON from free text if the provider ignored the schema
+func decodeStructured(response string, result any) error {
+	if err := json.Unmarshal([]byte(strings.TrimSpace(response)), result); err == nil {
+		return nil
+	}
+
+	jsonContent, err := extractJSON(response)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(jsonContent), result); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,191 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestJSONSchemaFor tests schema derivation from the agent output types
+func TestJSONSchemaFor(t *testing.T) {
+	schema := newOutputSchema(&VerdictOutput{})
+	if schema == nil {
+		t.Fatal("expected schema for *VerdictOutput")
+	}
+	if schema.Name != "VerdictOutput" {
+		t.Errorf("expected name VerdictOutput, got %q", schema.Name)
+	}
+
+	props := schema.Schema["properties"].(map[string]any)
+	if got := props["ruling"]; !reflect.DeepEqual(got, map[string]any{"type": "string"}) {
+		t.Errorf("unexpected ruling schema: %v", got)
+	}
+	rejected := props["rejected"].(map[string]any)
+	if rejected["type"] != "array" {
+		t.Errorf("expected rejected to be an array, got %v", rejected["type"])
+	}
+	item := rejected["items"].(map[string]any)
+	if !reflect.DeepEqual(item["required"], []string{"option", "reason"}) {
+		t.Errorf("unexpected rejected item required fields: %v", item["required"])
+	}
+	if got := props["ranking"]; !reflect.DeepEqual(got, map[string]any{}) {
+		t.Errorf("expected unconstrained ranking schema, got %v", got)
+	}
+	// ranking is omitempty, so not required
+	if !reflect.DeepEqual(schema.Schema["required"], []string{"ruling", "rationale", "rejected"}) {
+		t.Errorf("unexpected required fields: %v", schema.Schema["required"])
+	}
+
+	for _, v := range []any{&ExecutionOutput{}, &ClarificationOutput{}} {
+		if newOutputSchema(v) == nil {
+			t.Errorf("expected schema for %T", v)
+		}
+	}
+
+	var m map[string]any
+	if newOutputSchema(&m) != nil {
+		t.Error("expected no schema for a map target")
+	}
+}
+
+// TestGeminiSchema tests conversion to Gemini's responseSchema subset
+func TestGeminiSchema(t *testing.T) {
+	schema := geminiSchema(newOutputSchema(&VerdictOutput{}).Schema)
+
+	if schema["type"] != "OBJECT" {
+		t.Errorf("expected OBJECT, got %v", schema["type"])
+	}
+	if _, ok := schema["additionalProperties"]; ok {
+		t.Error("expected additionalProperties to be removed")
+	}
+	props := schema["properties"].(map[string]any)
+	if _, ok := props["ranking"]; ok {
+		t.Error("expected unconstrained ranking property to be removed")
+	}
+	if props["rejected"].(map[string]any)["items"].(map[string]any)["type"] != "OBJECT" {
+		t.Error("expected nested item types to be converted")
+	}
+}
+
+// TestCompleteJSONStructuredOutput tests that each provider sends its native
+// structured-output parameters and decodes the structured response
+func TestCompleteJSONStructuredOutput(t *testing.T) {
+	verdict := `{"ruling":"Use Go","rationale":"Fast, simple deployment {with braces}","rejected":[]}`
+
+	tests := []struct {
+		name     string
+		response string
+		check    func(t *testing.T, body map[string]any)
+		call     func(ctx context.Context, cfg Config, url string, result any) error
+	}{
+		{
+			name:     "openai response_format",
+			response: `{"choices":[{"message":{"role":"assistant","content":` + quote(verdict) + `}}]}`,
+			check: func(t *testing.T, body map[string]any) {
+				format := body["response_format"].(map[string]any)
+				if format["type"] != "json_schema" {
+					t.Errorf("expected json_schema response format, got %v", format["type"])
+				}
+				if format["json_schema"].(map[string]any)["name"] != "VerdictOutput" {
+					t.Errorf("unexpected schema name: %v", format["json_schema"])
+				}
+			},
+			call: func(ctx context.Context, cfg Config, url string, result any) error {
+				c := &openAIClient{config: cfg, httpClient: &http.Client{}}
+				response, err := c.makeRequestWithURL(ctx, c.newRequest("prompt", newOutputSchema(result)), url)
+				if err != nil {
+					return err
+				}
+				return decodeStructured(response.Choices[0].Message.Content, result)
+			},
+		},
+		{
+			name:     "anthropic forced tool use",
+			response: `{"content":[{"type":"tool_use","name":"VerdictOutput","input":` + verdict + `}]}`,
+			check: func(t *testing.T, body map[string]any) {
+				choice := body["tool_choice"].(map[string]any)
+				if choice["type"] != "tool" || choice["name"] != "VerdictOutput" {
+					t.Errorf("unexpected tool_choice: %v", choice)
+				}
+				tools := body["tools"].([]any)
+				if len(tools) != 1 || tools[0].(map[string]any)["input_schema"] == nil {
+					t.Errorf("unexpected tools: %v", tools)
+				}
+			},
+			call: func(ctx context.Context, cfg Config, url string, result any) error {
+				c := &anthropicClient{config: cfg, httpClient: &http.Client{}}
+				response, err := c.makeRequestWithURL(ctx, c.newRequest("prompt", newOutputSchema(result)), url)
+				if err != nil {
+					return err
+				}
+				return decodeStructured(string(response.Content[0].Input), result)
+			},
+		},
+		{
+			name:     "gemini responseSchema",
+			response: `{"candidates":[{"content":{"parts":[{"text":` + quote(verdict) + `}]}}]}`,
+			check: func(t *testing.T, body map[string]any) {
+				config := body["generationConfig"].(map[string]any)
+				if config["responseMimeType"] != "application/json" {
+					t.Errorf("unexpected responseMimeType: %v", config["responseMimeType"])
+				}
+				if config["responseSchema"].(map[string]any)["type"] != "OBJECT" {
+					t.Errorf("unexpected responseSchema: %v", config["responseSchema"])
+				}
+			},
+			call: func(ctx context.Context, cfg Config, url string, result any) error {
+				c := &geminiClient{config: cfg, httpClient: &http.Client{}}
+				response, err := c.makeRequestWithURL(ctx, c.newRequest("prompt", newOutputSchema(result)), url)
+				if err != nil {
+					return err
+				}
+				return decodeStructured(response.Candidates[0].Content.Parts[0].Text, result)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Fatalf("failed to decode request: %v", err)
+				}
+				tt.check(t, body)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			cfg := Config{APIKey: "test-key", Model: "test-model", Timeout: 5 * time.Second}
+			var result VerdictOutput
+			if err := tt.call(context.Background(), cfg, server.URL, &result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Ruling != "Use Go" || result.Rationale != "Fast, simple deployment {with braces}" {
+				t.Errorf("unexpected result: %+v", result)
+			}
+		})
+	}
+}
+
+// TestDecodeStructuredFallback tests the free-text fallback for models that ignore the schema
+func TestDecodeStructuredFallback(t *testing.T) {
+	var result VerdictOutput
+	response := "Here is my verdict:\n```json\n{\"ruling\": \"Ship it\", \"rationale\": \"Ready\", \"rejected\": []}\n```"
+	if err := decodeStructured(response, &result); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Ruling != "Ship it" {
+		t.Errorf("unexpected ruling: %q", result.Ruling)
+	}
+}
+
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
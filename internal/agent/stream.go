@@ -0,0 +1,469 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrStreamStalled is returned when a provider stops sending data mid-stream
+var ErrStreamStalled = errors.New("stream stalled")
+
+// DeltaFunc receives incremental text as it is generated
+type DeltaFunc func(delta string)
+
+// StreamingLLMClient is implemented by clients that can stream token deltas.
+// Stream calls onDelta for each text fragment as it arrives and returns the
+// complete response text once the provider finishes.
+type StreamingLLMClient interface {
+	LLMClient
+	Stream(ctx context.Context, prompt string, onDelta DeltaFunc) (string, error)
+}
+
+// structuredStreamer is implemented by streaming clients that can also
+// constrain the streamed output to the JSON schema of result
+type structuredStreamer interface {
+	StreamJSON(ctx context.Context, prompt string, result any, onDelta DeltaFunc) error
+}
+
+// completeJSONStream behaves like client.CompleteJSON, but streams the response
+// through onDelta when the client supports it
+func completeJSONStream(ctx context.Context, client LLMClient, prompt string, result any, onDelta DeltaFunc) error {
+	if onDelta == nil {
+		return client.CompleteJSON(ctx, prompt, result)
+	}
+	if s, ok := client.(structuredStreamer); ok {
+		return s.StreamJSON(ctx, prompt, result, onDelta)
+	}
+	streamer, ok := client.(StreamingLLMClient)
+	if !ok {
+		return client.CompleteJSON(ctx, prompt, result)
+	}
+
+	response, err := streamer.Stream(ctx, prompt, onDelta)
+	if err != nil {
+		return err
+	}
+
+	jsonContent, err := extractJSON(response)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(jsonContent), result); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	return nil
+}
+
+// sseHandler processes one Server-Sent Event. It returns done=true when the
+// stream has finished.
+type sseHandler func(event, data string) (done bool, err error)
+
+// streamWithRetry runs a streaming request with the same retry policy as
+// Complete. Retries only happen before any delta has been delivered, so callers
+// never see duplicated text.
+func streamWithRetry(ctx context.Context, maxRetries int, onDelta DeltaFunc, attempt func(ctx context.Context, onDelta DeltaFunc) (string, error)) (string, error) {
+	var delivered atomic.Bool
+	trackingDelta := func(delta string) {
+		delivered.Store(true)
+		if onDelta != nil {
+			onDelta(delta)
+		}
+	}
+
+	for i := 0; ; i++ {
+		text, err := attempt(ctx, trackingDelta)
+		if err == nil {
+			return text, nil
+		}
+		if delivered.Load() {
+			return "", err
+		}
+		delay, retry := retryDelay(i+1, err)
+		if !retry {
+			return "", err
+		}
+		if i >= maxRetries {
+			return "", fmt.Errorf("max retries exceeded: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// doStream sends a streaming request and dispatches each Server-Sent Event to
+// handle. If no data arrives for idleTimeout the request is aborted with
+// ErrStreamStalled.
+func doStream(ctx context.Context, provider string, httpClient *http.Client, req *http.Request, idleTimeout time.Duration, handle sseHandler) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Watchdog: abort the request when the provider goes quiet
+	var stalled atomic.Bool
+	watchdog := time.AfterFunc(idleTimeout, func() {
+		stalled.Store(true)
+		cancel()
+	})
+	defer watchdog.Stop()
+
+	resp, err := httpClient.Do(req.WithContext(streamCtx))
+	if err != nil {
+		if stalled.Load() {
+			return ErrStreamStalled
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return ErrTimeout
+		}
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return newProviderError(provider, resp, body)
+	}
+
+	var event string
+	var data strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		watchdog.Reset(idleTimeout)
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			// Blank line dispatches the buffered event
+			if data.Len() == 0 {
+				event = ""
+				continue
+			}
+			done, err := handle(event, data.String())
+			if err != nil {
+				return err
+			}
+			if done {
+				return nil
+			}
+			event = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// Comment / keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if stalled.Load() {
+			return ErrStreamStalled
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return ErrTimeout
+		}
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	// Flush a final event not followed by a blank line
+	if data.Len() > 0 {
+		if _, err := handle(event, data.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newJSONRequest builds a POST request with a JSON body
+func newJSONRequest(ctx context.Context, url string, body any) (*http.Request, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+
+	return req, nil
+}
+
+// === OpenAI ===
+
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage,omitempty"` // Only on the final chunk
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error,omitempty"`
+}
+
+// Stream streams a chat completion using OpenAI's "stream": true SSE format
+func (c *openAIClient) Stream(ctx context.Context, prompt string, onDelta DeltaFunc) (string, error) {
+	return c.stream(ctx, c.newRequest(prompt, nil), onDelta)
+}
+
+// StreamJSON streams a chat completion constrained to result's JSON schema
+func (c *openAIClient) StreamJSON(ctx context.Context, prompt string, result any, onDelta DeltaFunc) error {
+	response, err := c.stream(ctx, c.newRequest(prompt, newOutputSchema(result)), onDelta)
+	if err != nil {
+		return err
+	}
+	return decodeStructured(response, result)
+}
+
+func (c *openAIClient) stream(ctx context.Context, reqBody openAIRequest, onDelta DeltaFunc) (string, error) {
+	return streamWithRetry(ctx, c.config.MaxRetries, onDelta, func(ctx context.Context, onDelta DeltaFunc) (string, error) {
+		return c.streamWithURL(ctx, reqBody, c.endpoint(), onDelta)
+	})
+}
+
+func (c *openAIClient) streamWithURL(ctx context.Context, reqBody openAIRequest, url string, onDelta DeltaFunc) (string, error) {
+	reqBody.Stream = true
+	reqBody.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+
+	req, err := newJSONRequest(ctx, url, reqBody)
+	if err != nil {
+		return "", err
+	}
+	if c.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	}
+
+	var text strings.Builder
+	var usage *openAIUsage
+	err = doStream(ctx, c.provider(), c.httpClient, req, c.config.StreamIdleTimeout, func(event, data string) (bool, error) {
+		if data == "[DONE]" {
+			return true, nil
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return false, newBodyError(c.provider(), chunk.Error.Type, chunk.Error.Message)
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				text.WriteString(choice.Delta.Content)
+				onDelta(choice.Delta.Content)
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if text.Len() == 0 {
+		return "", errors.New("no response from OpenAI")
+	}
+	var recorded Usage
+	if usage != nil {
+		recorded = Usage{
+			InputTokens:  usage.PromptTokens,
+			OutputTokens: usage.CompletionTokens,
+		}
+	}
+	recordUsage(ctx, c.provider(), c.config.Model, recorded)
+	return text.String(), nil
+}
+
+// === Anthropic ===
+
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type        string `json:"type"` // "text_delta" or "input_json_delta"
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	Message struct {
+		Usage anthropicUsage `json:"usage"`
+	} `json:"message"` // message_start
+	Usage anthropicUsage `json:"usage"` // message_delta (cumulative output tokens)
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Stream streams a message using Anthropic's typed SSE events
+// (message_start, content_block_delta, ..., message_stop)
+func (c *anthropicClient) Stream(ctx context.Context, prompt string, onDelta DeltaFunc) (string, error) {
+	return c.stream(ctx, c.newRequest(prompt, nil), onDelta)
+}
+
+// StreamJSON streams a forced tool call whose input matches result's JSON
+// schema. The deltas are the partial tool input JSON.
+func (c *anthropicClient) StreamJSON(ctx context.Context, prompt string, result any, onDelta DeltaFunc) error {
+	response, err := c.stream(ctx, c.newRequest(prompt, newOutputSchema(result)), onDelta)
+	if err != nil {
+		return err
+	}
+	return decodeStructured(response, result)
+}
+
+func (c *anthropicClient) stream(ctx context.Context, reqBody anthropicRequest, onDelta DeltaFunc) (string, error) {
+	return streamWithRetry(ctx, c.config.MaxRetries, onDelta, func(ctx context.Context, onDelta DeltaFunc) (string, error) {
+		return c.streamWithURL(ctx, reqBody, baseURL(c.config, DefaultAnthropicBaseURL)+"/messages", onDelta)
+	})
+}
+
+func (c *anthropicClient) streamWithURL(ctx context.Context, reqBody anthropicRequest, url string, onDelta DeltaFunc) (string, error) {
+	reqBody.Stream = true
+
+	req, err := newJSONRequest(ctx, url, reqBody)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("x-api-key", c.config.APIKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	var text strings.Builder
+	var usage Usage
+	err = doStream(ctx, "anthropic", c.httpClient, req, c.config.StreamIdleTimeout, func(event, data string) (bool, error) {
+		var ev anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			return false, fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		switch ev.Type {
+		case "message_start":
+			usage.InputTokens = ev.Message.Usage.InputTokens
+			usage.OutputTokens = ev.Message.Usage.OutputTokens
+		case "message_delta":
+			usage.OutputTokens = ev.Usage.OutputTokens
+		case "content_block_delta":
+			delta := ev.Delta.Text
+			if ev.Delta.Type == "input_json_delta" {
+				delta = ev.Delta.PartialJSON
+			}
+			if delta != "" {
+				text.WriteString(delta)
+				onDelta(delta)
+			}
+		case "message_stop":
+			return true, nil
+		case "error":
+			if ev.Error != nil {
+				return false, newBodyError("anthropic", ev.Error.Type, ev.Error.Message)
+			}
+			return false, newBodyError("anthropic", "", "stream error")
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if text.Len() == 0 {
+		return "", errors.New("no response from Anthropic")
+	}
+	recordUsage(ctx, "anthropic", c.config.Model, usage)
+	return text.String(), nil
+}
+
+// === Gemini ===
+
+// Stream streams content using Gemini's streamGenerateContent endpoint in SSE mode
+func (c *geminiClient) Stream(ctx context.Context, prompt string, onDelta DeltaFunc) (string, error) {
+	return c.stream(ctx, c.newRequest(prompt, nil), onDelta)
+}
+
+// StreamJSON streams content constrained to result's JSON schema
+func (c *geminiClient) StreamJSON(ctx context.Context, prompt string, result any, onDelta DeltaFunc) error {
+	response, err := c.stream(ctx, c.newRequest(prompt, newOutputSchema(result)), onDelta)
+	if err != nil {
+		return err
+	}
+	return decodeStructured(response, result)
+}
+
+func (c *geminiClient) stream(ctx context.Context, reqBody geminiRequest, onDelta DeltaFunc) (string, error) {
+	url := fmt.Sprintf("%s/models/%s:streamGenerateContent?alt=sse", baseURL(c.config, DefaultGeminiBaseURL), c.config.Model)
+	return streamWithRetry(ctx, c.config.MaxRetries, onDelta, func(ctx context.Context, onDelta DeltaFunc) (string, error) {
+		return c.streamWithURL(ctx, reqBody, url, onDelta)
+	})
+}
+
+func (c *geminiClient) streamWithURL(ctx context.Context, reqBody geminiRequest, url string, onDelta DeltaFunc) (string, error) {
+	req, err := newJSONRequest(ctx, url, reqBody)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("x-goog-api-key", c.config.APIKey)
+
+	var text strings.Builder
+	var usage *geminiUsageMetadata
+	err = doStream(ctx, "gemini", c.httpClient, req, c.config.StreamIdleTimeout, func(event, data string) (bool, error) {
+		// Each event is a partial GenerateContentResponse
+		var chunk geminiResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return false, newBodyError("gemini", "", chunk.Error.Message)
+		}
+		// Usage metadata is cumulative; keep the latest
+		if chunk.UsageMetadata != nil {
+			usage = chunk.UsageMetadata
+		}
+		for _, candidate := range chunk.Candidates {
+			for _, part := range candidate.Content.Parts {
+				if part.Text != "" {
+					text.WriteString(part.Text)
+					onDelta(part.Text)
+				}
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if text.Len() == 0 {
+		return "", errors.New("no response from Gemini")
+	}
+	var recorded Usage
+	if usage != nil {
+		recorded = Usage{
+			InputTokens:  usage.PromptTokenCount,
+			OutputTokens: usage.CandidatesTokenCount,
+		}
+	}
+	recordUsage(ctx, "gemini", c.config.Model, recorded)
+	return text.String(), nil
+}
@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseServer returns a server that writes the given raw SSE lines, flushing after each
+func sseServer(t *testing.T, lines []string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("expected Accept: text/event-stream, got %q", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for _, line := range lines {
+			fmt.Fprintf(w, "%s\n", line)
+			flusher.Flush()
+		}
+	}))
+}
+
+func testStreamConfig(provider string) Config {
+	return Config{
+		Provider:          provider,
+		APIKey:            "test-key",
+		Model:             "test-model",
+		MaxRetries:        0,
+		Timeout:           5 * time.Second,
+		StreamIdleTimeout: time.Second,
+	}
+}
+
+// TestOpenAIStream tests parsing of OpenAI chat completion chunks
+func TestOpenAIStream(t *testing.T) {
+	server := sseServer(t, []string{
+		`data: {"choices":[{"delta":{"role":"assistant"}}]}`, "",
+		`data: {"choices":[{"delta":{"content":"Hello"}}]}`, "",
+		": keep-alive", "",
+		`data: {"choices":[{"delta":{"content":", world"}}]}`, "",
+		"data: [DONE]", "",
+	})
+	defer server.Close()
+
+	client := &openAIClient{config: testStreamConfig("openai"), httpClient: &http.Client{}}
+
+	var deltas []string
+	text, err := client.streamWithURL(context.Background(), client.newRequest("hi", nil), server.URL, func(d string) {
+		deltas = append(deltas, d)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "Hello, world" {
+		t.Errorf("expected %q, got %q", "Hello, world", text)
+	}
+	if len(deltas) != 2 || deltas[0] != "Hello" || deltas[1] != ", world" {
+		t.Errorf("unexpected deltas: %q", deltas)
+	}
+}
+
+// TestAnthropicStream tests parsing of Anthropic typed stream events
+func TestAnthropicStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		lines     []string
+		wantText  string
+		wantError error
+	}{
+		{
+			name: "text deltas",
+			lines: []string{
+				"event: message_start", `data: {"type":"message_start"}`, "",
+				"event: content_block_start", `data: {"type":"content_block_start","index":0}`, "",
+				"event: content_block_delta", `data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"{\"ruling\":"}}`, "",
+				"event: ping", `data: {"type":"ping"}`, "",
+				"event: content_block_delta", `data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"\"yes\"}"}}`, "",
+				"event: message_stop", `data: {"type":"message_stop"}`, "",
+			},
+			wantText: `{"ruling":"yes"}`,
+		},
+		{
+			name: "overloaded error",
+			lines: []string{
+				"event: error", `data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, "",
+			},
+			wantError: ErrOverloaded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := sseServer(t, tt.lines)
+			defer server.Close()
+
+			client := &anthropicClient{config: testStreamConfig("anthropic"), httpClient: &http.Client{}}
+
+			var got strings.Builder
+			text, err := client.streamWithURL(context.Background(), client.newRequest("hi", nil), server.URL, func(d string) {
+				got.WriteString(d)
+			})
+			if tt.wantError != nil {
+				if !errors.Is(err, tt.wantError) {
+					t.Fatalf("expected %v, got %v", tt.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if text != tt.wantText || got.String() != tt.wantText {
+				t.Errorf("expected %q, got text %q and deltas %q", tt.wantText, text, got.String())
+			}
+		})
+	}
+}
+
+// TestGeminiStream tests parsing of Gemini streamGenerateContent chunks
+func TestGeminiStream(t *testing.T) {
+	server := sseServer(t, []string{
+		`data: {"candidates":[{"content":{"parts":[{"text":"Part one. "}]}}]}`, "",
+		`data: {"candidates":[{"content":{"parts":[{"text":"Part two."}]}}]}`, "",
+	})
+	defer server.Close()
+
+	client := &geminiClient{config: testStreamConfig("gemini"), httpClient: &http.Client{}}
+
+	count := 0
+	text, err := client.streamWithURL(context.Background(), client.newRequest("hi", nil), server.URL, func(d string) { count++ })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "Part one. Part two." {
+		t.Errorf("unexpected text: %q", text)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 deltas, got %d", count)
+	}
+}
+
+// TestStreamStalled tests that a stream that stops sending data is aborted
+func TestStreamStalled(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"partial\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg := testStreamConfig("openai")
+	cfg.StreamIdleTimeout = 100 * time.Millisecond
+	client := &openAIClient{config: cfg, httpClient: &http.Client{}}
+
+	start := time.Now()
+	_, err := client.streamWithURL(context.Background(), client.newRequest("hi", nil), server.URL, func(string) {})
+	if !errors.Is(err, ErrStreamStalled) {
+		t.Fatalf("expected ErrStreamStalled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("stall took too long to detect: %v", elapsed)
+	}
+}
+
+// TestStreamWithRetry tests that retries only happen before output is delivered
+func TestStreamWithRetry(t *testing.T) {
+	t.Run("retries before first delta", func(t *testing.T) {
+		attempts := 0
+		text, err := streamWithRetry(context.Background(), 1, nil, func(ctx context.Context, onDelta DeltaFunc) (string, error) {
+			attempts++
+			if attempts == 1 {
+				return "", ErrStreamStalled
+			}
+			onDelta("ok")
+			return "ok", nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if text != "ok" || attempts != 2 {
+			t.Errorf("expected success on second attempt, got %q after %d attempts", text, attempts)
+		}
+	})
+
+	t.Run("no retry after delta", func(t *testing.T) {
+		attempts := 0
+		_, err := streamWithRetry(context.Background(), 3, nil, func(ctx context.Context, onDelta DeltaFunc) (string, error) {
+			attempts++
+			onDelta("partial")
+			return "", ErrStreamStalled
+		})
+		if !errors.Is(err, ErrStreamStalled) {
+			t.Fatalf("expected ErrStreamStalled, got %v", err)
+		}
+		if attempts != 1 {
+			t.Errorf("expected 1 attempt, got %d", attempts)
+		}
+	})
+}
+
+// streamingMockClient is a minimal StreamingLLMClient for testing
+type streamingMockClient struct {
+	chunks []string
+}
+
+func (m *streamingMockClient) Complete(ctx context.Context, prompt string) (string, error) {
+	return strings.Join(m.chunks, ""), nil
+}
+
+func (m *streamingMockClient) CompleteJSON(ctx context.Context, prompt string, result any) error {
+	return errors.New("CompleteJSON should not be called when streaming")
+}
+
+func (m *streamingMockClient) Stream(ctx context.Context, prompt string, onDelta DeltaFunc) (string, error) {
+	for _, c := range m.chunks {
+		onDelta(c)
+	}
+	return strings.Join(m.chunks, ""), nil
+}
+
+// TestVerdictAgentProcessStream tests that the verdict agent streams through a streaming client
+func TestVerdictAgentProcessStream(t *testing.T) {
+	client := &streamingMockClient{chunks: []string{
+		"```json\n{\"ruling\": \"Build the web app\", ",
+		"\"rationale\": \"Faster to ship\", \"rejected\": [], \"ranking\": [1]}\n```",
+	}}
+	verdictAgent := NewVerdictAgent(client)
+
+	var deltas int
+	result, err := verdictAgent.ProcessStream(context.Background(), "web or mobile?", "", func(string) { deltas++ })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Ruling != "Build the web app" {
+		t.Errorf("unexpected ruling: %q", result.Ruling)
+	}
+	if deltas != 2 {
+		t.Errorf("expected 2 deltas, got %d", deltas)
+	}
+}
@@ -0,0 +1,174 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Usage holds token counts reported by a provider
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// Add returns the sum of two usages
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:  u.InputTokens + other.InputTokens,
+		OutputTokens: u.OutputTokens + other.OutputTokens,
+	}
+}
+
+// UsageRecord is the usage of a single LLM call
+type UsageRecord struct {
+	Stage    string `json:"stage,omitempty"` // Pipeline stage the call was made for
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	Usage
+}
+
+// UsageRecorder collects the usage of every LLM call made with a context.
+// It is safe for concurrent use.
+type UsageRecorder struct {
+	mu      sync.Mutex
+	records []UsageRecord
+}
+
+// NewUsageRecorder creates an empty usage recorder
+func NewUsageRecorder() *UsageRecorder {
+	return &UsageRecorder{}
+}
+
+// Record adds the usage of one call
+func (r *UsageRecorder) Record(provider, model string, usage Usage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, UsageRecord{Provider: provider, Model: model, Usage: usage})
+}
+
+func (r *UsageRecorder) add(rec UsageRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rec)
+}
+
+// Records returns a copy of the recorded calls in the order they were made
+func (r *UsageRecorder) Records() []UsageRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	records := make([]UsageRecord, len(r.records))
+	copy(records, r.records)
+	return records
+}
+
+// Total returns the summed usage of all recorded calls
+func (r *UsageRecorder) Total() Usage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total Usage
+	for _, rec := range r.records {
+		total = total.Add(rec.Usage)
+	}
+	return total
+}
+
+type usageRecorderKey struct{}
+
+// WithUsageRecorder returns a context that records the usage of LLM calls made with it
+func WithUsageRecorder(ctx context.Context, r *UsageRecorder) context.Context {
+	return context.WithValue(ctx, usageRecorderKey{}, r)
+}
+
+// UsageRecorderFromContext returns the context's usage recorder, or nil.
+// Custom LLMClient implementations use it to report their usage.
+func UsageRecorderFromContext(ctx context.Context) *UsageRecorder {
+	r, _ := ctx.Value(usageRecorderKey{}).(*UsageRecorder)
+	return r
+}
+
+// ServedBy returns the "provider/model" that served the last call of each
+// stage, keyed by stage. Calls made without a stage are ignored.
+func (r *UsageRecorder) ServedBy() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var served map[string]string
+	for _, rec := range r.records {
+		if rec.Stage == "" {
+			continue
+		}
+		if served == nil {
+			served = make(map[string]string)
+		}
+		served[rec.Stage] = rec.Provider + "/" + rec.Model
+	}
+	return served
+}
+
+type stageKey struct{}
+
+// WithStage returns a context whose LLM calls are recorded under the given
+// pipeline stage
+func WithStage(ctx context.Context, stage string) context.Context {
+	return context.WithValue(ctx, stageKey{}, stage)
+}
+
+// recordUsage reports a call's usage to the context's recorder, if any
+func recordUsage(ctx context.Context, provider, model string, usage Usage) {
+	if r := UsageRecorderFromContext(ctx); r != nil {
+		stage, _ := ctx.Value(stageKey{}).(string)
+		r.add(UsageRecord{Stage: stage, Provider: provider, Model: model, Usage: usage})
+	}
+}
+
+// ModelPrice is the price of a model in USD per million tokens
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// PriceTable maps model names to their prices
+type PriceTable map[string]ModelPrice
+
+// DefaultPriceTable returns list prices for the default models of each provider
+func DefaultPriceTable() PriceTable {
+	return PriceTable{
+		"gpt-4o":                   {Input: 2.50, Output: 10.00},
+		"gpt-4o-mini":              {Input: 0.15, Output: 0.60},
+		"claude-sonnet-4-20250514": {Input: 3.00, Output: 15.00},
+		"gemini-2.5-flash":         {Input: 0.30, Output: 2.50},
+	}
+}
+
+// Cost estimates the cost in USD of the given calls. Calls to models missing
+// from the table are counted as free.
+func (t PriceTable) Cost(records []UsageRecord) float64 {
+	var cost float64
+	for _, rec := range records {
+		price, ok := t[rec.Model]
+		if !ok {
+			continue
+		}
+		cost += float64(rec.InputTokens)*price.Input/1e6 + float64(rec.OutputTokens)*price.Output/1e6
+	}
+	return cost
+}
+
+// ParsePriceTable parses a JSON object of model prices, e.g.
+// {"gpt-4o": {"input": 2.5, "output": 10}}, and merges it over the defaults
+func ParsePriceTable(raw string) (PriceTable, error) {
+	prices := DefaultPriceTable()
+	if raw == "" {
+		return prices, nil
+	}
+
+	var overrides PriceTable
+	if err := json.Unmarshal([]byte(raw), &overrides); err != nil {
+		return nil, fmt.Errorf("invalid price table: %w", err)
+	}
+	for model, price := range overrides {
+		prices[model] = price
+	}
+	return prices, nil
+}
@@ -0,0 +1,163 @@
+package agent
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestUsageRecorder tests that usage is collected through the context
+func TestUsageRecorder(t *testing.T) {
+	rec := NewUsageRecorder()
+	ctx := WithUsageRecorder(context.Background(), rec)
+
+	recordUsage(ctx, "openai", "gpt-4o", Usage{InputTokens: 100, OutputTokens: 20})
+	recordUsage(ctx, "openai", "gpt-4o", Usage{InputTokens: 50, OutputTokens: 10})
+	recordUsage(context.Background(), "openai", "gpt-4o", Usage{InputTokens: 1000}) // No recorder: ignored
+
+	if got := rec.Total(); got.InputTokens != 150 || got.OutputTokens != 30 {
+		t.Errorf("unexpected total: %+v", got)
+	}
+	if records := rec.Records(); len(records) != 2 || records[0].Model != "gpt-4o" {
+		t.Errorf("unexpected records: %+v", records)
+	}
+}
+
+// TestPriceTableCost tests cost estimation from the price table
+func TestPriceTableCost(t *testing.T) {
+	prices := PriceTable{"model-a": {Input: 2, Output: 10}}
+	records := []UsageRecord{
+		{Model: "model-a", Usage: Usage{InputTokens: 1_000_000, OutputTokens: 500_000}},
+		{Model: "unknown", Usage: Usage{InputTokens: 1_000_000}},
+	}
+
+	if got := prices.Cost(records); math.Abs(got-7) > 1e-9 {
+		t.Errorf("expected cost 7, got %v", got)
+	}
+}
+
+// TestParsePriceTable tests parsing price overrides
+func TestParsePriceTable(t *testing.T) {
+	prices, err := ParsePriceTable(`{"gpt-4o": {"input": 1, "output": 2}, "custom": {"input": 3, "output": 4}}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prices["gpt-4o"].Input != 1 || prices["custom"].Output != 4 {
+		t.Errorf("overrides not applied: %+v", prices)
+	}
+	if _, ok := prices["claude-sonnet-4-20250514"]; !ok {
+		t.Error("expected defaults to be kept")
+	}
+
+	if _, err := ParsePriceTable("not json"); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+// TestMakeRequestRecordsUsage tests that each provider's usage block is parsed
+func TestMakeRequestRecordsUsage(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		call func(ctx context.Context, cfg Config, url string) error
+	}{
+		{
+			name: "openai",
+			body: `{"choices":[{"message":{"role":"assistant","content":"ok"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`,
+			call: func(ctx context.Context, cfg Config, url string) error {
+				c := &openAIClient{config: cfg, httpClient: &http.Client{}}
+				_, err := c.makeRequestWithURL(ctx, openAIRequest{Model: cfg.Model}, url)
+				return err
+			},
+		},
+		{
+			name: "anthropic",
+			body: `{"content":[{"text":"ok"}],"usage":{"input_tokens":12,"output_tokens":3}}`,
+			call: func(ctx context.Context, cfg Config, url string) error {
+				c := &anthropicClient{config: cfg, httpClient: &http.Client{}}
+				_, err := c.makeRequestWithURL(ctx, anthropicRequest{Model: cfg.Model}, url)
+				return err
+			},
+		},
+		{
+			name: "gemini",
+			body: `{"candidates":[{"content":{"parts":[{"text":"ok"}]}}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":3}}`,
+			call: func(ctx context.Context, cfg Config, url string) error {
+				c := &geminiClient{config: cfg, httpClient: &http.Client{}}
+				_, err := c.makeRequestWithURL(ctx, geminiRequest{}, url)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			rec := NewUsageRecorder()
+			ctx := WithUsageRecorder(context.Background(), rec)
+			cfg := Config{Provider: tt.name, APIKey: "test-key", Model: "test-model", Timeout: 5 * time.Second}
+
+			if err := tt.call(ctx, cfg, server.URL); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			records := rec.Records()
+			if len(records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(records))
+			}
+			want := UsageRecord{Provider: tt.name, Model: "test-model", Usage: Usage{InputTokens: 12, OutputTokens: 3}}
+			if records[0] != want {
+				t.Errorf("expected %+v, got %+v", want, records[0])
+			}
+		})
+	}
+}
+
+// TestStreamRecordsUsage tests that usage is collected from Anthropic stream events
+func TestStreamRecordsUsage(t *testing.T) {
+	server := sseServer(t, []string{
+		`data: {"type":"message_start","message":{"usage":{"input_tokens":25,"output_tokens":1}}}`, "",
+		`data: {"type":"content_block_delta","delta":{"type":"text_delta","text":"ok"}}`, "",
+		`data: {"type":"message_delta","usage":{"output_tokens":15}}`, "",
+		`data: {"type":"message_stop"}`, "",
+	})
+	defer server.Close()
+
+	rec := NewUsageRecorder()
+	ctx := WithUsageRecorder(context.Background(), rec)
+	client := &anthropicClient{config: testStreamConfig("anthropic"), httpClient: &http.Client{}}
+
+	if _, err := client.streamWithURL(ctx, client.newRequest("hi", nil), server.URL, func(string) {}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := rec.Total(); got.InputTokens != 25 || got.OutputTokens != 15 {
+		t.Errorf("unexpected usage: %+v", got)
+	}
+}
+
+// TestUsageRecorderServedBy tests that the provider serving each stage is reported
+func TestUsageRecorderServedBy(t *testing.T) {
+	rec := NewUsageRecorder()
+	ctx := WithUsageRecorder(context.Background(), rec)
+
+	recordUsage(WithStage(ctx, "verdict"), "openai", "gpt-4o", Usage{})
+	recordUsage(WithStage(ctx, "execution"), "openai", "gpt-4o", Usage{})
+	recordUsage(WithStage(ctx, "execution"), "anthropic", "claude-sonnet-4-20250514", Usage{}) // Later call wins
+	recordUsage(ctx, "gemini", "gemini-2.5-flash", Usage{})                                    // No stage: ignored
+
+	served := rec.ServedBy()
+	if len(served) != 2 || served["verdict"] != "openai/gpt-4o" || served["execution"] != "anthropic/claude-sonnet-4-20250514" {
+		t.Errorf("unexpected providers: %v", served)
+	}
+	if records := rec.Records(); records[0].Stage != "verdict" {
+		t.Errorf("expected the stage on the record, got %+v", records[0])
+	}
+}
@@ -17,16 +17,44 @@ var (
 
 // VerdictAgent processes fuzzy user input and produces singular rulings
 type VerdictAgent struct {
-	client LLMClient
+	client      LLMClient
+	maxRepairs  int
+	linter      *HedgingLinter
+	hedgingMode HedgingMode
 }
 
 // NewVerdictAgent creates a new VerdictAgent with the given LLM client
 func NewVerdictAgent(client LLMClient) *VerdictAgent {
 	return &VerdictAgent{
-		client: client,
+		client:      client,
+		maxRepairs:  DefaultMaxRepairs,
+		linter:      NewHedgingLinter(nil, nil),
+		hedgingMode: HedgingFlag,
 	}
 }
 
+// SetHedgingLinter sets the linter enforcing decisive language and what to do
+// when it finds a violation
+func (a *VerdictAgent) SetHedgingLinter(linter *HedgingLinter, mode HedgingMode) {
+	a.linter = linter
+	a.hedgingMode = mode
+}
+
+// QualityFlags lints a verdict for hedging language and multi-option rulings.
+// It returns nil when linting is disabled.
+func (a *VerdictAgent) QualityFlags(output *VerdictOutput) []QualityFlag {
+	if a.hedgingMode == HedgingOff {
+		return nil
+	}
+	return a.linter.Lint(output)
+}
+
+// SetMaxRepairs sets how many corrective turns the agent may take when its
+// output fails validation. Zero disables repair.
+func (a *VerdictAgent) SetMaxRepairs(n int) {
+	a.maxRepairs = n
+}
+
 // Process takes user input and returns a decisive verdict with explicit rejections
 func (a *VerdictAgent) Process(ctx context.Context, input string) (*VerdictOutput, error) {
 	return a.ProcessWithContext(ctx, input, "")
@@ -34,6 +62,12 @@ func (a *VerdictAgent) Process(ctx context.Context, input string) (*VerdictOutpu
 
 // ProcessWithContext takes user input and optional search context for real-time information
 func (a *VerdictAgent) ProcessWithContext(ctx context.Context, input string, searchContext string) (*VerdictOutput, error) {
+	return a.ProcessStream(ctx, input, searchContext, nil)
+}
+
+// ProcessStream is like ProcessWithContext but reports the raw model output
+// through onDelta as it is generated, when the LLM client supports streaming
+func (a *VerdictAgent) ProcessStream(ctx context.Context, input string, searchContext string, onDelta DeltaFunc) (*VerdictOutput, error) {
 	// Validate input
 	if err := validateInput(input); err != nil {
 		return nil, err
@@ -42,18 +76,28 @@ func (a *VerdictAgent) ProcessWithContext(ctx context.Context, input string, sea
 	// Detect language and build prompt
 	prompt := buildVerdictPromptWithContext(input, searchContext)
 
-	// Call LLM
-	var result VerdictOutput
-	if err := a.client.CompleteJSON(ctx, prompt, &result); err != nil {
-		return nil, fmt.Errorf("failed to get verdict: %w", err)
+	// In reask mode hedging is sent back for repair along with validation errors
+	validate := validateOutput
+	if a.hedgingMode == HedgingReask {
+		validate = func(output *VerdictOutput) error {
+			return errors.Join(validateOutput(output), qualityError(a.linter.Lint(output)))
+		}
 	}
 
-	// Validate output
-	if err := validateOutput(&result); err != nil {
-		return nil, err
+	// Call LLM, repairing output that fails validation
+	result, err := completeWithRepair(ctx, a.client, "verdict", prompt, a.maxRepairs, onDelta, validate)
+	if err != nil {
+		if isValidationError(err) {
+			// Persistent hedging alone does not fail the verdict; it is flagged instead
+			if result != nil && validateOutput(result) == nil {
+				return result, nil
+			}
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get verdict: %w", err)
 	}
 
-	return &result, nil
+	return result, nil
 }
 
 // validateInput checks input constraints
@@ -68,16 +112,18 @@ func validateInput(input string) error {
 	return nil
 }
 
-// validateOutput ensures the verdict meets quality standards
+// validateOutput ensures the verdict meets quality standards.
+// It reports every problem found so they can be sent back for repair.
 func validateOutput(output *VerdictOutput) error {
+	var errs []error
 	if strings.TrimSpace(output.Ruling) == "" {
-		return ErrEmptyRuling
+		errs = append(errs, ErrEmptyRuling)
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
-// detectLanguage returns "zh" for Chinese, "en" for English
-func detectLanguage(input string) string {
+// DetectLanguage returns "zh" for Chinese, "en" for English
+func DetectLanguage(input string) string {
 	// Count Chinese characters and total characters
 	chineseCount := 0
 	totalChars := utf8.RuneCountInString(input)
@@ -102,7 +148,7 @@ func buildVerdictPrompt(input string) string {
 
 // buildVerdictPromptWithContext constructs the system prompt with optional search context
 func buildVerdictPromptWithContext(input string, searchContext string) string {
-	lang := detectLanguage(input)
+	lang := DetectLanguage(input)
 
 	var systemPrompt string
 	if lang == "zh" {
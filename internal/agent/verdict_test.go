@@ -164,9 +164,9 @@ func TestDetectLanguage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := detectLanguage(tt.input)
+			result := DetectLanguage(tt.input)
 			if result != tt.expected {
-				t.Errorf("detectLanguage() = %v, want %v", result, tt.expected)
+				t.Errorf("DetectLanguage() = %v, want %v", result, tt.expected)
 			}
 		})
 	}
@@ -388,8 +388,12 @@ func TestVerdictOutput_JSONMarshaling(t *testing.T) {
 	if len(decoded.Rejected) != len(output.Rejected) {
 		t.Errorf("rejected count mismatch: got %d, want %d", len(decoded.Rejected), len(output.Rejected))
 	}
-	if len(decoded.Ranking) != len(output.Ranking) {
-		t.Errorf("ranking count mismatch: got %d, want %d", len(decoded.Ranking), len(output.Ranking))
+	decodedRanking, ok := decoded.Ranking.([]interface{})
+	if !ok {
+		t.Fatalf("ranking type mismatch: got %T, want []interface{}", decoded.Ranking)
+	}
+	if len(decodedRanking) != len(output.Ranking.([]int)) {
+		t.Errorf("ranking count mismatch: got %d, want %d", len(decodedRanking), len(output.Ranking.([]int)))
 	}
 }
 
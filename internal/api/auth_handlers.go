@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -12,11 +13,12 @@ import (
 
 // AuthHandlers holds dependencies for auth-related handlers
 type AuthHandlers struct {
-	repo *storage.MemoryRepository
+	repo      storage.UserRepository
+	decisions storage.Repository // Optional: records a state snapshot of each progress update
 }
 
 // NewAuthHandlers creates new AuthHandlers
-func NewAuthHandlers(repo *storage.MemoryRepository) *AuthHandlers {
+func NewAuthHandlers(repo storage.UserRepository) *AuthHandlers {
 	return &AuthHandlers{repo: repo}
 }
 
@@ -48,16 +50,22 @@ type UserResponse struct {
 
 // HistoryResponse represents a history entry in API response
 type HistoryResponse struct {
-	ID              string                       `json:"id"`
-	DecisionID      string                       `json:"decision_id"`
-	Input           string                       `json:"input"`
-	Verdict         json.RawMessage              `json:"verdict"`
-	Todo            string                       `json:"todo"`
-	DoneCriteria    []storage.DoneCriterion      `json:"done_criteria"`
-	Score           float64                      `json:"score"`
-	UploadedContent []storage.UploadedContent    `json:"uploaded_content,omitempty"`
-	CreatedAt       string                       `json:"created_at"`
-	UpdatedAt       string                       `json:"updated_at"`
+	ID              string                    `json:"id"`
+	DecisionID      string                    `json:"decision_id"`
+	Input           string                    `json:"input"`
+	Verdict         json.RawMessage           `json:"verdict"`
+	Todo            string                    `json:"todo"`
+	DoneCriteria    []storage.DoneCriterion   `json:"done_criteria"`
+	Score           float64                   `json:"score"`
+	UploadedContent []storage.UploadedContent `json:"uploaded_content,omitempty"`
+	CreatedAt       string                    `json:"created_at"`
+	UpdatedAt       string                    `json:"updated_at"`
+}
+
+// HistoryListResponse represents a page of GET /api/history
+type HistoryListResponse struct {
+	History    []HistoryResponse `json:"history"`
+	NextCursor string            `json:"next_cursor,omitempty"` // Pass as ?cursor= for the next page
 }
 
 // UpdateDoneCriteriaRequest represents request to update done criteria
@@ -86,11 +94,7 @@ func (h *AuthHandlers) RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	// Create user
 	user, err := h.repo.CreateUser(r.Context(), req.Username, req.Password)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			writeError(w, http.StatusConflict, "USERNAME_EXISTS", "Username already exists", "")
-			return
-		}
-		writeError(w, http.StatusInternalServerError, "CREATE_FAILED", "Failed to create user", err.Error())
+		writeStorageError(w, err, "Username", "create user")
 		return
 	}
 
@@ -118,10 +122,14 @@ func (h *AuthHandlers) LoginHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get user
 	user, err := h.repo.GetUserByUsername(r.Context(), req.Username)
-	if err != nil {
+	if errors.Is(err, storage.ErrNotFound) {
 		writeError(w, http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid username or password", "")
 		return
 	}
+	if err != nil {
+		writeStorageError(w, err, "User", "look up user")
+		return
+	}
 
 	// Validate password
 	if !h.repo.ValidatePassword(user, req.Password) {
@@ -167,7 +175,8 @@ func (h *AuthHandlers) MeHandler(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetHistoryHandler handles GET /api/history
+// GetHistoryHandler handles GET /api/history, accepting the same filters and
+// paging as GET /api/decisions
 func (h *AuthHandlers) GetHistoryHandler(w http.ResponseWriter, r *http.Request) {
 	user := GetUserFromContext(r)
 	if user == nil {
@@ -175,15 +184,24 @@ func (h *AuthHandlers) GetHistoryHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	histories, err := h.repo.GetUserHistory(r.Context(), user.ID)
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidQuery, "Invalid query parameter", err.Error())
+		return
+	}
+
+	page, err := h.repo.ListUserHistory(r.Context(), user.ID, opts)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "FETCH_FAILED", "Failed to fetch history", err.Error())
+		writeStorageError(w, err, "History", "fetch history")
 		return
 	}
 
-	response := make([]HistoryResponse, len(histories))
-	for i, h := range histories {
-		response[i] = historyToResponse(h)
+	response := HistoryListResponse{
+		History:    make([]HistoryResponse, len(page.Entries)),
+		NextCursor: page.NextCursor,
+	}
+	for i, h := range page.Entries {
+		response.History[i] = historyToResponse(h)
 	}
 
 	writeJSON(w, http.StatusOK, response)
@@ -205,14 +223,12 @@ func (h *AuthHandlers) GetHistoryByIDHandler(w http.ResponseWriter, r *http.Requ
 	}
 
 	history, err := h.repo.GetHistory(r.Context(), id)
-	if err != nil {
-		writeError(w, http.StatusNotFound, "NOT_FOUND", "History not found", "")
-		return
+	if err == nil {
+		// Verify ownership
+		err = checkOwner(user, history.UserID)
 	}
-
-	// Verify ownership
-	if history.UserID != user.ID {
-		writeError(w, http.StatusForbidden, "FORBIDDEN", "Access denied", "")
+	if err != nil {
+		writeStorageError(w, err, "History", "fetch history")
 		return
 	}
 
@@ -236,14 +252,12 @@ func (h *AuthHandlers) UpdateDoneCriteriaHandler(w http.ResponseWriter, r *http.
 
 	// Get existing history
 	history, err := h.repo.GetHistory(r.Context(), id)
-	if err != nil {
-		writeError(w, http.StatusNotFound, "NOT_FOUND", "History not found", "")
-		return
+	if err == nil {
+		// Verify ownership
+		err = checkOwner(user, history.UserID)
 	}
-
-	// Verify ownership
-	if history.UserID != user.ID {
-		writeError(w, http.StatusForbidden, "FORBIDDEN", "Access denied", "")
+	if err != nil {
+		writeStorageError(w, err, "History", "fetch history")
 		return
 	}
 
@@ -256,10 +270,18 @@ func (h *AuthHandlers) UpdateDoneCriteriaHandler(w http.ResponseWriter, r *http.
 	// Update criteria
 	updated, err := h.repo.UpdateDoneCriteria(r.Context(), id, req.DoneCriteria)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "UPDATE_FAILED", "Failed to update criteria", err.Error())
+		writeStorageError(w, err, "History", "update criteria")
 		return
 	}
 
+	// Version the decision's state; retrying the update records it again
+	if h.decisions != nil {
+		if _, err := recordState(r.Context(), h.decisions, updated.DecisionID, withDoneCriteria(updated.DoneCriteria)); err != nil {
+			writeStorageError(w, err, "Decision state", "record state")
+			return
+		}
+	}
+
 	writeJSON(w, http.StatusOK, historyToResponse(updated))
 }
 
@@ -278,4 +300,3 @@ func historyToResponse(h *storage.UserHistory) HistoryResponse {
 		UpdatedAt:       h.UpdatedAt.Format("2006-01-02T15:04:05Z"),
 	}
 }
-
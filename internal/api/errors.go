@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/google/uuid"
+)
+
+// writeStorageError maps a storage error to an HTTP error response. resource
+// names the record in client errors ("Decision not found") and action
+// describes the failed operation otherwise ("retrieve decision").
+func writeStorageError(w http.ResponseWriter, err error, resource, action string) {
+	status, resp := storageErrorResponse(err, resource, action)
+	writeJSON(w, status, resp)
+}
+
+// storageErrorResponse maps a storage error to an HTTP status and error body.
+// Only the typed storage sentinels become client errors; anything else, such
+// as an unreachable database, is an internal error.
+func storageErrorResponse(err error, resource, action string) (int, ErrorResponse) {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound, ErrorResponse{Error: resource + " not found", Code: ErrCodeNotFound}
+	case errors.Is(err, storage.ErrConflict):
+		return http.StatusConflict, ErrorResponse{Error: resource + " already exists", Code: ErrCodeConflict}
+	case errors.Is(err, storage.ErrInvalidCursor):
+		return http.StatusBadRequest, ErrorResponse{Error: "Invalid pagination cursor", Code: ErrCodeInvalidQuery}
+	case errors.Is(err, storage.ErrForbidden):
+		return http.StatusForbidden, ErrorResponse{Error: "Access denied", Code: ErrCodeForbidden}
+	default:
+		return http.StatusInternalServerError, ErrorResponse{Error: "Failed to " + action, Code: ErrCodeInternalError, Details: err.Error()}
+	}
+}
+
+// checkOwner returns storage.ErrForbidden unless user owns a record with the
+// given owner
+func checkOwner(user *storage.User, owner uuid.UUID) error {
+	if user == nil || user.ID != owner {
+		return storage.ErrForbidden
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/google/uuid"
+)
+
+func TestStorageErrorResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantError  string
+	}{
+		{
+			name:       "not found",
+			err:        fmt.Errorf("decision %w", storage.ErrNotFound),
+			wantStatus: http.StatusNotFound,
+			wantCode:   ErrCodeNotFound,
+			wantError:  "Decision not found",
+		},
+		{
+			name:       "conflict",
+			err:        fmt.Errorf("username %w", storage.ErrConflict),
+			wantStatus: http.StatusConflict,
+			wantCode:   ErrCodeConflict,
+			wantError:  "Decision already exists",
+		},
+		{
+			name:       "forbidden",
+			err:        storage.ErrForbidden,
+			wantStatus: http.StatusForbidden,
+			wantCode:   ErrCodeForbidden,
+			wantError:  "Access denied",
+		},
+		{
+			// Used to be matched as "not found" by its message
+			name:       "database outage",
+			err:        errors.New("failed to get decision: host not found"),
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   ErrCodeInternalError,
+			wantError:  "Failed to retrieve decision",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := storageErrorResponse(tt.err, "Decision", "retrieve decision")
+			if status != tt.wantStatus {
+				t.Errorf("status = %d, want %d", status, tt.wantStatus)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", resp.Code, tt.wantCode)
+			}
+			if resp.Error != tt.wantError {
+				t.Errorf("error = %q, want %q", resp.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCheckOwner(t *testing.T) {
+	owner := &storage.User{ID: uuid.New()}
+
+	if err := checkOwner(owner, owner.ID); err != nil {
+		t.Errorf("checkOwner(owner) = %v, want nil", err)
+	}
+	if err := checkOwner(&storage.User{ID: uuid.New()}, owner.ID); !errors.Is(err, storage.ErrForbidden) {
+		t.Errorf("checkOwner(other user) = %v, want storage.ErrForbidden", err)
+	}
+	if err := checkOwner(nil, owner.ID); !errors.Is(err, storage.ErrForbidden) {
+		t.Errorf("checkOwner(nil) = %v, want storage.ErrForbidden", err)
+	}
+}
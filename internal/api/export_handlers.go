@@ -0,0 +1,157 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/export"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// GitHubExportResponse represents the response for
+// POST /api/decisions/{id}/export/github
+type GitHubExportResponse struct {
+	DecisionID       string         `json:"decision_id"`
+	Repository       string         `json:"repository"`
+	TrackingIssue    int            `json:"tracking_issue"`
+	TrackingIssueURL string         `json:"tracking_issue_url"`
+	Milestones       map[int]int    `json:"milestones"` // Milestone number of each phase, by phase index
+	Issues           map[string]int `json:"issues"`     // Issue number of each task, by task ID
+	Created          int            `json:"created"`    // Milestones and issues created by this export
+	Updated          int            `json:"updated"`    // Milestones and issues updated in place
+}
+
+// ExportGitHubHandler handles POST /api/decisions/{id}/export/github
+// requests, exporting the decision's execution plan and its progress to the
+// configured repository. Exporting again updates the issues of the earlier
+// export.
+func (h *Handlers) ExportGitHubHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid decision ID", "Must be a valid UUID")
+		return
+	}
+
+	user := GetUserFromContext(r)
+	if h.userRepo != nil && user == nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", "")
+		return
+	}
+
+	ctx := r.Context()
+	decision, err := h.repository.GetDecision(ctx, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision")
+		return
+	}
+	// Decisions made by a signed-in user are exported only by that user
+	if decision.UserID != nil {
+		if err := checkOwner(user, *decision.UserID); err != nil {
+			writeStorageError(w, err, "Decision", "export decision")
+			return
+		}
+	}
+
+	tasks, err := ensureTasks(ctx, h.repository, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve tasks")
+		return
+	}
+	snapshots, err := stateSnapshots(ctx, h.repository, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision state")
+		return
+	}
+	state, err := artifact.ParseState(snapshots[len(snapshots)-1].State)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to read decision state", err.Error())
+		return
+	}
+
+	repository := h.githubExporter.Repository()
+	var prior *export.GitHubMapping
+	stored, err := h.repository.GetGitHubExport(ctx, id, repository)
+	switch {
+	case err == nil:
+		prior = &export.GitHubMapping{TrackingIssue: stored.TrackingIssue, Milestones: stored.Milestones, Issues: stored.Issues}
+	case !errors.Is(err, storage.ErrNotFound):
+		writeStorageError(w, err, "GitHub export", "retrieve github export")
+		return
+	}
+
+	// Whatever was exported is recorded, even if the export failed part way,
+	// so that retrying does not duplicate it
+	result, exportErr := h.githubExporter.Export(ctx, githubPlan(decision, state, tasks), prior)
+	record := &storage.GitHubExport{
+		DecisionID:    id,
+		Repository:    repository,
+		TrackingIssue: result.Mapping.TrackingIssue,
+		Milestones:    result.Mapping.Milestones,
+		Issues:        result.Mapping.Issues,
+	}
+	if stored != nil {
+		record.CreatedAt = stored.CreatedAt
+	}
+	saveErr := h.repository.SaveGitHubExport(ctx, record)
+	if exportErr != nil {
+		writeError(w, http.StatusBadGateway, ErrCodeExportFailed, "Failed to export to GitHub Issues", exportErr.Error())
+		return
+	}
+	if saveErr != nil {
+		writeStorageError(w, saveErr, "GitHub export", "save github export")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, GitHubExportResponse{
+		DecisionID:       id.String(),
+		Repository:       repository,
+		TrackingIssue:    result.Mapping.TrackingIssue,
+		TrackingIssueURL: result.TrackingIssueURL,
+		Milestones:       result.Mapping.Milestones,
+		Issues:           result.Mapping.Issues,
+		Created:          result.Created,
+		Updated:          result.Updated,
+	})
+}
+
+// githubPlan returns the execution plan of a decision as of its latest state,
+// keyed by the IDs of its tracked tasks
+func githubPlan(d *storage.Decision, state *artifact.State, tasks []*storage.Task) *export.Plan {
+	plan := &export.Plan{
+		DecisionID: d.ID.String(),
+		Ruling:     storedVerdict(d).Ruling,
+		Execution: &agent.ExecutionOutput{
+			MVPScope:     state.MVPScope,
+			Phases:       make([]agent.Phase, len(state.Phases)),
+			DoneCriteria: make([]string, len(state.DoneCriteria)),
+		},
+		TaskIDs:           make([][]string, len(state.Phases)),
+		DoneTasks:         map[string]bool{},
+		CompletedCriteria: map[int]bool{},
+	}
+	for i, phase := range state.Phases {
+		plan.Execution.Phases[i] = agent.Phase{Name: phase.Name, Tasks: make([]string, len(phase.Tasks))}
+		plan.TaskIDs[i] = make([]string, len(phase.Tasks))
+		for j, task := range phase.Tasks {
+			plan.Execution.Phases[i].Tasks[j] = task.Text
+			plan.TaskIDs[i][j] = task.ID
+			plan.DoneTasks[task.ID] = task.Done
+		}
+	}
+	// Snapshots stored before tasks were tracked have no task IDs
+	for _, t := range tasks {
+		if t.PhaseIndex < len(plan.TaskIDs) && t.Position < len(plan.TaskIDs[t.PhaseIndex]) {
+			plan.TaskIDs[t.PhaseIndex][t.Position] = t.ID.String()
+			plan.DoneTasks[t.ID.String()] = t.Status == storage.TaskDone
+		}
+	}
+	for i, c := range state.DoneCriteria {
+		plan.Execution.DoneCriteria[i] = c.Text
+		plan.CompletedCriteria[i] = c.Completed
+	}
+	return plan
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/export"
+	"github.com/1psychoQAQ/verdict-agent/internal/export/githubtest"
+	"github.com/google/uuid"
+)
+
+func TestExportGitHubHandler(t *testing.T) {
+	server := githubtest.NewServer("secret", "acme/plans")
+	defer server.Close()
+	exporter, err := export.NewGitHubExporter(export.GitHubConfig{Token: "secret", Repository: "acme/plans", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewGitHubExporter() error = %v", err)
+	}
+
+	_, repo, _, router := newTestHandlers(t, func(cfg *RouterConfig) { cfg.GitHubExporter = exporter })
+	ctx := context.Background()
+	alice, bob := newTestSession(t, repo, "alice"), newTestSession(t, repo, "bob")
+
+	do := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	newDecision := func(t *testing.T, input string) string {
+		t.Helper()
+		rec := do(alice, http.MethodPost, "/api/verdict", `{"input": "`+input+`"}`)
+		var verdict VerdictResponse
+		if err := json.NewDecoder(rec.Body).Decode(&verdict); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("verdict: status %d, error %v", rec.Code, err)
+		}
+		return verdict.DecisionID
+	}
+	exportDecision := func(t *testing.T, id string) GitHubExportResponse {
+		t.Helper()
+		rec := do(alice, http.MethodPost, "/api/decisions/"+id+"/export/github", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var resp GitHubExportResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	id := newDecision(t, "Go or Rust?")
+	var list TaskListResponse
+	if err := json.NewDecoder(do(alice, http.MethodGet, "/api/decisions/"+id+"/tasks", "").Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	task := list.Tasks[0]
+
+	t.Run("first export", func(t *testing.T) {
+		resp := exportDecision(t, id)
+		if resp.Repository != "acme/plans" || resp.Created != 3 || resp.Updated != 0 || resp.TrackingIssueURL == "" {
+			t.Errorf("response = %+v", resp)
+		}
+		issue, ok := server.Issue(resp.Issues[task.ID])
+		if !ok || issue.Title != "Task 1" || issue.State != "open" || issue.Milestone != resp.Milestones[0] {
+			t.Errorf("task issue = %+v", issue)
+		}
+		tracking, _ := server.Issue(resp.TrackingIssue)
+		if !strings.Contains(tracking.Body, "- [ ] Parser ported\n") || tracking.Title != "Execution plan: Use Rust" {
+			t.Errorf("tracking issue = %+v", tracking)
+		}
+	})
+
+	t.Run("export again with progress", func(t *testing.T) {
+		if rec := do(alice, http.MethodPatch, "/api/decisions/"+id+"/tasks/"+task.ID, `{"status": "done"}`); rec.Code != http.StatusOK {
+			t.Fatalf("update task: status %d: %s", rec.Code, rec.Body.String())
+		}
+		resp := exportDecision(t, id)
+		if resp.Created != 0 || resp.Updated != 3 {
+			t.Errorf("created %d and updated %d, want 3 updated", resp.Created, resp.Updated)
+		}
+		if len(server.Issues()) != 2 || len(server.Milestones()) != 1 {
+			t.Errorf("export duplicated: %d issues, %d milestones", len(server.Issues()), len(server.Milestones()))
+		}
+		if issue, _ := server.Issue(resp.Issues[task.ID]); issue.State != "closed" {
+			t.Errorf("done task issue is %s", issue.State)
+		}
+	})
+
+	t.Run("failed part way", func(t *testing.T) {
+		other := newDecision(t, "Postgres or SQLite?")
+		server.FailAfter(1)
+		defer server.FailAfter(0)
+		rec := do(alice, http.MethodPost, "/api/decisions/"+other+"/export/github", "")
+		if rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), ErrCodeExportFailed) {
+			t.Errorf("expected status %d, got %d: %s", http.StatusBadGateway, rec.Code, rec.Body.String())
+		}
+		stored, err := repo.GetGitHubExport(ctx, uuid.MustParse(other), "acme/plans")
+		if err != nil || len(stored.Milestones) != 1 || len(stored.Issues) != 0 {
+			t.Errorf("expected the created milestone recorded, got %+v, %v", stored, err)
+		}
+	})
+
+	for _, tt := range []struct {
+		name, token, id string
+		status          int
+	}{
+		{"another user's decision", bob, id, http.StatusForbidden},
+		{"unknown decision", alice, uuid.NewString(), http.StatusNotFound},
+		{"invalid decision ID", alice, "not-a-uuid", http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := do(tt.token, http.MethodPost, "/api/decisions/"+tt.id+"/export/github", ""); rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
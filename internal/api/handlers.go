@@ -1,13 +1,20 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/1psychoQAQ/verdict-agent/internal/agent"
 	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/export"
+	"github.com/1psychoQAQ/verdict-agent/internal/jobs"
 	"github.com/1psychoQAQ/verdict-agent/internal/pipeline"
 	"github.com/1psychoQAQ/verdict-agent/internal/storage"
 	"github.com/go-chi/chi/v5"
@@ -19,6 +26,7 @@ type VerdictRequest struct {
 	Input         string            `json:"input"`
 	Clarification *ClarificationCtx `json:"clarification,omitempty"` // Optional clarification answers
 	SkipClarify   bool              `json:"skip_clarify,omitempty"`  // Skip clarification check
+	Force         bool              `json:"force,omitempty"`         // Run the pipeline even if a similar decision exists
 }
 
 // ClarificationCtx holds clarification answers from the user
@@ -29,17 +37,34 @@ type ClarificationCtx struct {
 // VerdictResponse represents the response for POST /api/verdict
 type VerdictResponse struct {
 	// Status indicates the response type: "clarification_needed" or "verdict"
-	Status       string          `json:"status"`
-	DecisionID   string          `json:"decision_id,omitempty"`
-	HistoryID    string          `json:"history_id,omitempty"` // User's history entry ID
-	Decision     json.RawMessage `json:"decision,omitempty"`
-	Todo         string          `json:"todo,omitempty"`         // Markdown content
-	DoneCriteria []string        `json:"done_criteria,omitempty"` // Done criteria list for tracking
+	Status       string            `json:"status"`
+	DecisionID   string            `json:"decision_id,omitempty"`
+	HistoryID    string            `json:"history_id,omitempty"` // User's history entry ID
+	Decision     json.RawMessage   `json:"decision,omitempty"`
+	Todo         string            `json:"todo,omitempty"`          // Markdown content
+	State        json.RawMessage   `json:"state,omitempty"`         // First state.json snapshot
+	DoneCriteria []string          `json:"done_criteria,omitempty"` // Done criteria list for tracking
+	Usage        *UsageDTO         `json:"usage,omitempty"`         // LLM spend of the pipeline run
+	Providers    map[string]string `json:"providers,omitempty"`     // "provider/model" that served each stage
+	ContentHash  string            `json:"content_hash,omitempty"`  // SHA-256 of decision.json and todo.md
+	Signature    string            `json:"signature,omitempty"`     // Server's Ed25519 signature of ContentHash
+	// Duplicate fields (when an earlier decision answers the same question)
+	DuplicateOf string  `json:"duplicate_of,omitempty"` // ID of the earlier decision, returned instead of a new ruling
+	Similarity  float64 `json:"similarity,omitempty"`   // Input similarity to it, up to 1
+	// Revisit field (when the verdict supersedes an earlier decision)
+	Supersedes string `json:"supersedes,omitempty"`
 	// Clarification fields (when status is "clarification_needed")
 	Questions []QuestionDTO `json:"questions,omitempty"`
 	Reason    string        `json:"reason,omitempty"`
 }
 
+// UsageDTO reports the token usage and estimated cost of a pipeline run
+type UsageDTO struct {
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
 // QuestionDTO represents a clarifying question for the API response
 type QuestionDTO struct {
 	ID       string   `json:"id"`
@@ -51,11 +76,37 @@ type QuestionDTO struct {
 
 // DecisionResponse represents the response for GET /api/decisions/{id}
 type DecisionResponse struct {
-	ID        string          `json:"id"`
-	Input     string          `json:"input"`
-	Verdict   json.RawMessage `json:"verdict"`
-	CreatedAt string          `json:"created_at"`
-	IsFinal   bool            `json:"is_final"`
+	ID        string            `json:"id"`
+	Input     string            `json:"input"`
+	Verdict   json.RawMessage   `json:"verdict"`
+	CreatedAt string            `json:"created_at"`
+	IsFinal   bool              `json:"is_final"`
+	Language  string            `json:"language,omitempty"` // Input language: "en" or "zh"
+	Usage     UsageDTO          `json:"usage"`
+	Providers map[string]string `json:"providers,omitempty"` // "provider/model" that served each stage
+
+	Supersedes   string `json:"supersedes,omitempty"`    // Decision this one replaced when revisiting it
+	SupersededBy string `json:"superseded_by,omitempty"` // Decision that replaced this one
+
+	ContentHash string `json:"content_hash,omitempty"` // SHA-256 of decision.json and todo.md
+	Signature   string `json:"signature,omitempty"`    // Server's Ed25519 signature of ContentHash
+}
+
+// DecisionListResponse represents a page of GET /api/decisions
+type DecisionListResponse struct {
+	Decisions  []DecisionResponse `json:"decisions"`
+	NextCursor string             `json:"next_cursor,omitempty"` // Pass as ?cursor= for the next page
+}
+
+// DecisionSearchResponse represents the response for GET /api/decisions/search
+type DecisionSearchResponse struct {
+	Results []DecisionSearchResult `json:"results"`
+}
+
+// DecisionSearchResult is a decision matching a search, best match first
+type DecisionSearchResult struct {
+	DecisionResponse
+	Rank float64 `json:"rank"` // Relevance, only comparable within one search
 }
 
 // TodoResponse represents the response for GET /api/todos/{id}
@@ -82,6 +133,24 @@ const (
 	ErrCodeRateLimited   = "RATE_LIMITED"
 	ErrCodeInvalidID     = "INVALID_ID"
 	ErrCodeInternalError = "INTERNAL_ERROR"
+	ErrCodeQueueFull     = "QUEUE_FULL"
+	ErrCodeJobFinished   = "JOB_FINISHED"
+	ErrCodeConflict      = "CONFLICT"
+	ErrCodeForbidden     = "FORBIDDEN"
+	ErrCodeInvalidQuery  = "INVALID_QUERY"
+	ErrCodeInvalidStatus = "INVALID_STATUS"
+	ErrCodeExportFailed  = "EXPORT_FAILED"
+	ErrCodeUnknownFormat = "UNKNOWN_FORMAT"
+
+	// LLM provider failures
+	ErrCodeLLMRateLimited  = "LLM_RATE_LIMITED"
+	ErrCodeLLMOverloaded   = "LLM_OVERLOADED"
+	ErrCodeLLMUnavailable  = "LLM_UNAVAILABLE"
+	ErrCodeLLMAuthFailed   = "LLM_AUTH_FAILED"
+	ErrCodeLLMBadRequest   = "LLM_BAD_REQUEST"
+	ErrCodeLLMTimeout      = "LLM_TIMEOUT"
+	ErrCodeContextTooLong  = "CONTEXT_TOO_LONG"
+	ErrCodeContentFiltered = "CONTENT_FILTERED"
 )
 
 // Handlers holds the dependencies for HTTP handlers
@@ -90,7 +159,10 @@ type Handlers struct {
 	generator          *artifact.Generator
 	repository         storage.Repository
 	clarificationAgent *agent.ClarificationAgent
-	memoryRepo         *storage.MemoryRepository // For history tracking
+	userRepo           storage.UserRepository // For history tracking
+	jobQueue           *jobs.Queue            // For asynchronous verdict jobs
+	duplicateThreshold float64                // Input similarity that returns an earlier decision; 0 disables
+	githubExporter     *export.GitHubExporter // For exporting execution plans to GitHub Issues
 }
 
 // NewHandlers creates a new Handlers instance
@@ -148,69 +220,33 @@ func (h *Handlers) HealthHandler(w http.ResponseWriter, r *http.Request) {
 
 // VerdictHandler handles POST /api/verdict requests
 func (h *Handlers) VerdictHandler(w http.ResponseWriter, r *http.Request) {
-	var req VerdictRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, ErrCodeInternalError, "Invalid JSON body", err.Error())
+	// Clients asking for an event stream get real-time progress instead
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		h.VerdictStreamHandler(w, r)
 		return
 	}
 
-	// Validate input
-	input := strings.TrimSpace(req.Input)
-	if input == "" {
-		writeError(w, http.StatusBadRequest, ErrCodeInputEmpty, "Input is required", "")
+	req, ok := decodeVerdictRequest(w, r)
+	if !ok {
 		return
 	}
-	if len(input) > 10000 {
-		writeError(w, http.StatusBadRequest, ErrCodeInputTooLong, "Input exceeds 10000 characters", "")
+
+	// Return the earlier decision if this question was already decided
+	if resp := h.findDuplicate(r.Context(), GetUserFromContext(r), req); resp != nil {
+		writeJSON(w, http.StatusOK, resp)
 		return
 	}
 
 	// Check if clarification is needed (if agent is available and not skipped)
-	if h.clarificationAgent != nil && !req.SkipClarify && req.Clarification == nil {
-		clarification, err := h.clarificationAgent.Analyze(r.Context(), input)
-		if err != nil {
-			// Log but continue without clarification
-			// log.Printf("Clarification analysis failed: %v", err)
-		} else if clarification != nil && clarification.NeedsClarification && len(clarification.Questions) > 0 {
-			// Return clarification questions
-			questions := make([]QuestionDTO, len(clarification.Questions))
-			for i, q := range clarification.Questions {
-				questions[i] = QuestionDTO{
-					ID:       q.ID,
-					Question: q.Question,
-					Type:     q.Type,
-					Options:  q.Options,
-					Required: q.Required,
-				}
-			}
-			writeJSON(w, http.StatusOK, VerdictResponse{
-				Status:    "clarification_needed",
-				Questions: questions,
-				Reason:    clarification.Reason,
-			})
-			return
-		}
-	}
-
-	// Build enriched input if clarification answers are provided
-	enrichedInput := input
-	if req.Clarification != nil && len(req.Clarification.Answers) > 0 {
-		enrichedInput = h.buildEnrichedInput(input, req.Clarification.Answers)
+	if resp := h.checkClarification(r.Context(), req); resp != nil {
+		writeJSON(w, http.StatusOK, resp)
+		return
 	}
 
 	// Execute pipeline
-	result, err := h.pipeline.Execute(r.Context(), enrichedInput)
+	result, err := h.pipeline.Execute(r.Context(), h.enrichedInput(req))
 	if err != nil {
-		switch {
-		case errors.Is(err, pipeline.ErrInputEmpty):
-			writeError(w, http.StatusBadRequest, ErrCodeInputEmpty, "Input is required", "")
-		case errors.Is(err, pipeline.ErrInputTooLong):
-			writeError(w, http.StatusBadRequest, ErrCodeInputTooLong, "Input exceeds 10000 characters", "")
-		case errors.Is(err, pipeline.ErrTimeout):
-			writeError(w, http.StatusGatewayTimeout, ErrCodeVerdictFailed, "Pipeline timeout", "")
-		default:
-			writeError(w, http.StatusInternalServerError, ErrCodeVerdictFailed, "Pipeline failed", err.Error())
-		}
+		writePipelineError(w, err)
 		return
 	}
 
@@ -222,12 +258,206 @@ func (h *Handlers) VerdictHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Save to database
+	historyID, err := h.saveArtifacts(r.Context(), GetUserFromContext(r), result, artifacts, nil)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to save artifacts", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, buildVerdictResponse(result, artifacts, historyID))
+}
+
+// decodeVerdictRequest parses and validates a verdict request body.
+// It writes an error response and returns false if the request is invalid.
+func decodeVerdictRequest(w http.ResponseWriter, r *http.Request) (*VerdictRequest, bool) {
+	var req VerdictRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInternalError, "Invalid JSON body", err.Error())
+		return nil, false
+	}
+
+	// Validate input
+	req.Input = strings.TrimSpace(req.Input)
+	if req.Input == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInputEmpty, "Input is required", "")
+		return nil, false
+	}
+	if len(req.Input) > 10000 {
+		writeError(w, http.StatusBadRequest, ErrCodeInputTooLong, "Input exceeds 10000 characters", "")
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// findDuplicate looks up an earlier decision whose input is at least
+// duplicateThreshold similar to the request's, unless the request forces a new
+// ruling. It returns a response carrying that decision, or nil if the pipeline
+// should run. With user accounts, only the caller's own decisions are
+// considered, and anonymous callers are never matched.
+func (h *Handlers) findDuplicate(ctx context.Context, user *storage.User, req *VerdictRequest) *VerdictResponse {
+	if h.duplicateThreshold <= 0 || req.Force {
+		return nil
+	}
+	opts := storage.SimilarOptions{MinSimilarity: h.duplicateThreshold, Limit: 1}
+	if h.userRepo != nil {
+		if user == nil {
+			return nil
+		}
+		opts.UserID = &user.ID
+	}
+
+	similar, err := h.repository.FindSimilarDecisions(ctx, h.enrichedInput(req), opts)
+	if err != nil || len(similar) == 0 {
+		// A failed lookup does not stop a new ruling
+		return nil
+	}
+
+	// A revisited decision answers with the latest of its lineage
+	d := similar[0].Decision
+	if d.SupersededBy != nil {
+		lineage, err := h.repository.GetDecisionLineage(ctx, d.ID)
+		if err != nil {
+			return nil
+		}
+		d = lineage[len(lineage)-1]
+	}
+	resp := &VerdictResponse{
+		Status:      "verdict",
+		DecisionID:  d.ID.String(),
+		Decision:    d.Verdict,
+		Providers:   d.Providers,
+		ContentHash: d.ContentHash,
+		Signature:   d.Signature,
+		DuplicateOf: d.ID.String(),
+		Similarity:  similar[0].Similarity,
+	}
+	if todo, err := h.repository.GetTodoByDecisionID(ctx, d.ID); err == nil {
+		resp.Todo = todo.Content
+	}
+	if h.userRepo != nil {
+		if history, err := h.userRepo.GetHistoryByDecisionID(ctx, d.ID); err == nil && history.UserID == user.ID {
+			resp.HistoryID = history.ID.String()
+			for _, c := range history.DoneCriteria {
+				resp.DoneCriteria = append(resp.DoneCriteria, c.Text)
+			}
+		}
+	}
+	return resp
+}
+
+// checkClarification runs the clarification agent when it is configured and the
+// request has neither answers nor an explicit skip. It returns a response with
+// the clarifying questions, or nil if the pipeline should proceed.
+func (h *Handlers) checkClarification(ctx context.Context, req *VerdictRequest) *VerdictResponse {
+	if !h.shouldClarify(req) {
+		return nil
+	}
+
+	clarification, err := h.clarificationAgent.Analyze(ctx, req.Input)
+	if err != nil {
+		// Log but continue without clarification
+		// log.Printf("Clarification analysis failed: %v", err)
+		return nil
+	}
+	if clarification == nil || !clarification.NeedsClarification || len(clarification.Questions) == 0 {
+		return nil
+	}
+
+	// Return clarification questions
+	questions := make([]QuestionDTO, len(clarification.Questions))
+	for i, q := range clarification.Questions {
+		questions[i] = QuestionDTO{
+			ID:       q.ID,
+			Question: q.Question,
+			Type:     q.Type,
+			Options:  q.Options,
+			Required: q.Required,
+		}
+	}
+	return &VerdictResponse{
+		Status:    "clarification_needed",
+		Questions: questions,
+		Reason:    clarification.Reason,
+	}
+}
+
+// shouldClarify reports whether the clarification check applies to the request
+func (h *Handlers) shouldClarify(req *VerdictRequest) bool {
+	return h.clarificationAgent != nil && !req.SkipClarify && req.Clarification == nil
+}
+
+// enrichedInput returns the pipeline input, including clarification answers if provided
+func (h *Handlers) enrichedInput(req *VerdictRequest) string {
+	if req.Clarification != nil && len(req.Clarification.Answers) > 0 {
+		return h.buildEnrichedInput(req.Input, req.Clarification.Answers)
+	}
+	return req.Input
+}
+
+// writePipelineError maps pipeline errors to HTTP error responses, passing on
+// the provider's retry hint when there is one
+func writePipelineError(w http.ResponseWriter, err error) {
+	status, resp := pipelineErrorResponse(err)
+	var perr *agent.ProviderError
+	if errors.As(err, &perr) && perr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(perr.RetryAfter.Seconds()))))
+	}
+	writeJSON(w, status, resp)
+}
+
+// pipelineErrorResponse maps pipeline errors to an HTTP status and error body
+func pipelineErrorResponse(err error) (int, ErrorResponse) {
+	switch {
+	case errors.Is(err, pipeline.ErrInputEmpty):
+		return http.StatusBadRequest, ErrorResponse{Error: "Input is required", Code: ErrCodeInputEmpty}
+	case errors.Is(err, pipeline.ErrInputTooLong):
+		return http.StatusBadRequest, ErrorResponse{Error: "Input exceeds 10000 characters", Code: ErrCodeInputTooLong}
+	case errors.Is(err, pipeline.ErrTimeout):
+		return http.StatusGatewayTimeout, ErrorResponse{Error: "Pipeline timeout", Code: ErrCodeVerdictFailed}
+	case errors.Is(err, agent.ErrContextLength):
+		return http.StatusRequestEntityTooLarge, ErrorResponse{Error: "Input is too long for the model's context window", Code: ErrCodeContextTooLong, Details: err.Error()}
+	case errors.Is(err, agent.ErrContentFiltered):
+		return http.StatusUnprocessableEntity, ErrorResponse{Error: "The model's content filter blocked the request", Code: ErrCodeContentFiltered, Details: err.Error()}
+	case errors.Is(err, agent.ErrRateLimited):
+		return http.StatusServiceUnavailable, ErrorResponse{Error: "LLM provider rate limit reached", Code: ErrCodeLLMRateLimited, Details: err.Error()}
+	case errors.Is(err, agent.ErrOverloaded):
+		return http.StatusServiceUnavailable, ErrorResponse{Error: "LLM provider is overloaded", Code: ErrCodeLLMOverloaded, Details: err.Error()}
+	case errors.Is(err, agent.ErrServerError), errors.Is(err, agent.ErrCircuitOpen):
+		return http.StatusBadGateway, ErrorResponse{Error: "LLM provider is unavailable", Code: ErrCodeLLMUnavailable, Details: err.Error()}
+	case errors.Is(err, agent.ErrAuth):
+		return http.StatusBadGateway, ErrorResponse{Error: "LLM provider rejected the credentials", Code: ErrCodeLLMAuthFailed, Details: err.Error()}
+	case errors.Is(err, agent.ErrBadRequest):
+		return http.StatusBadGateway, ErrorResponse{Error: "LLM provider rejected the request", Code: ErrCodeLLMBadRequest, Details: err.Error()}
+	case errors.Is(err, agent.ErrTimeout), errors.Is(err, agent.ErrStreamStalled):
+		return http.StatusGatewayTimeout, ErrorResponse{Error: "LLM provider timed out", Code: ErrCodeLLMTimeout, Details: err.Error()}
+	default:
+		return http.StatusInternalServerError, ErrorResponse{Error: "Pipeline failed", Code: ErrCodeVerdictFailed, Details: err.Error()}
+	}
+}
+
+// saveArtifacts persists the generated artifacts and, for authenticated users,
+// a history entry. supersedes is the decision a revisit replaces, if any. It
+// returns the history entry ID, if one was created.
+func (h *Handlers) saveArtifacts(ctx context.Context, user *storage.User, result *pipeline.PipelineResult, artifacts *artifact.Artifacts, supersedes *uuid.UUID) (string, error) {
 	decision := &storage.Decision{
 		ID:        artifacts.ID,
 		Input:     result.Input,
 		Verdict:   artifacts.DecisionJSON,
 		CreatedAt: artifacts.CreatedAt,
 		IsFinal:   true,
+		Language:  agent.DetectLanguage(result.Input),
+
+		InputTokens:  result.Usage.InputTokens,
+		OutputTokens: result.Usage.OutputTokens,
+		CostUSD:      result.CostUSD,
+		Providers:    result.Providers,
+		Supersedes:   supersedes,
+		ContentHash:  artifacts.ContentHash,
+		Signature:    artifacts.Signature,
+	}
+	if user != nil {
+		decision.UserID = &user.ID
 	}
 	todo := &storage.Todo{
 		DecisionID: artifacts.ID,
@@ -235,49 +465,63 @@ func (h *Handlers) VerdictHandler(w http.ResponseWriter, r *http.Request) {
 		CreatedAt:  artifacts.CreatedAt,
 	}
 
-	if err := h.repository.SaveArtifacts(r.Context(), decision, todo); err != nil {
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to save artifacts", err.Error())
-		return
+	if err := h.repository.SaveArtifacts(ctx, decision, todo); err != nil {
+		return "", err
+	}
+
+	// The first state snapshot can be rebuilt from todo.md if it is not saved
+	if len(artifacts.StateJSON) > 0 {
+		_ = h.repository.CreateStateSnapshot(ctx, &storage.StateSnapshot{
+			DecisionID: artifacts.ID,
+			Version:    1,
+			State:      artifacts.StateJSON,
+			CreatedAt:  artifacts.CreatedAt,
+		})
 	}
 
 	// Save to user history if authenticated
-	var historyID string
-	if h.memoryRepo != nil {
-		user := GetUserFromContext(r)
-		if user != nil {
-			// Extract done criteria from execution result
-			doneCriteria := extractDoneCriteria(result.Execution)
-
-			history := &storage.UserHistory{
-				UserID:       user.ID,
-				DecisionID:   artifacts.ID,
-				Input:        result.Input,
-				Verdict:      artifacts.DecisionJSON,
-				Todo:         string(artifacts.TodoMD),
-				DoneCriteria: doneCriteria,
-				Score:        0, // Initial score is 0
-			}
-			if err := h.memoryRepo.CreateHistory(r.Context(), history); err == nil {
-				historyID = history.ID.String()
-			}
-		}
+	if h.userRepo == nil || user == nil {
+		return "", nil
+	}
+
+	history := &storage.UserHistory{
+		UserID:       user.ID,
+		DecisionID:   artifacts.ID,
+		Input:        result.Input,
+		Verdict:      artifacts.DecisionJSON,
+		Todo:         string(artifacts.TodoMD),
+		DoneCriteria: extractDoneCriteria(result.Execution),
+		Score:        0, // Initial score is 0
 	}
+	if err := h.userRepo.CreateHistory(ctx, history); err != nil {
+		return "", nil
+	}
+	return history.ID.String(), nil
+}
 
-	// Return response
+// buildVerdictResponse assembles the response for a completed verdict
+func buildVerdictResponse(result *pipeline.PipelineResult, artifacts *artifact.Artifacts, historyID string) VerdictResponse {
 	resp := VerdictResponse{
 		Status:     "verdict",
 		DecisionID: artifacts.ID.String(),
+		HistoryID:  historyID,
 		Decision:   artifacts.DecisionJSON,
 		Todo:       string(artifacts.TodoMD),
+		State:      artifacts.StateJSON,
+		Usage: &UsageDTO{
+			InputTokens:  result.Usage.InputTokens,
+			OutputTokens: result.Usage.OutputTokens,
+			CostUSD:      result.CostUSD,
+		},
+		Providers:   result.Providers,
+		ContentHash: artifacts.ContentHash,
+		Signature:   artifacts.Signature,
 	}
 	// Add done criteria from execution result
 	if result.Execution != nil && len(result.Execution.DoneCriteria) > 0 {
 		resp.DoneCriteria = result.Execution.DoneCriteria
 	}
-	if historyID != "" {
-		resp.HistoryID = historyID
-	}
-	writeJSON(w, http.StatusOK, resp)
+	return resp
 }
 
 // buildEnrichedInput combines original input with clarification answers
@@ -306,21 +550,186 @@ func (h *Handlers) GetDecisionHandler(w http.ResponseWriter, r *http.Request) {
 
 	decision, err := h.repository.GetDecision(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, "Decision not found", "")
+		writeStorageError(w, err, "Decision", "retrieve decision")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, decisionToResponse(decision))
+}
+
+// ListDecisionsHandler handles GET /api/decisions requests. Signed-in users
+// list their own decisions; without user accounts every decision is listed.
+func (h *Handlers) ListDecisionsHandler(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidQuery, "Invalid query parameter", err.Error())
+		return
+	}
+
+	if h.userRepo != nil {
+		user := GetUserFromContext(r)
+		if user == nil {
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", "")
 			return
 		}
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to retrieve decision", err.Error())
+		opts.UserID = &user.ID
+	}
+
+	page, err := h.repository.ListDecisions(r.Context(), opts)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "list decisions")
 		return
 	}
 
-	writeJSON(w, http.StatusOK, DecisionResponse{
-		ID:        decision.ID.String(),
-		Input:     decision.Input,
-		Verdict:   decision.Verdict,
-		CreatedAt: decision.CreatedAt.Format("2006-01-02T15:04:05Z"),
-		IsFinal:   decision.IsFinal,
-	})
+	resp := DecisionListResponse{
+		Decisions:  make([]DecisionResponse, len(page.Decisions)),
+		NextCursor: page.NextCursor,
+	}
+	for i, d := range page.Decisions {
+		resp.Decisions[i] = decisionToResponse(d)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// SearchDecisionsHandler handles GET /api/decisions/search?q= requests,
+// scoped to the signed-in user like ListDecisionsHandler
+func (h *Handlers) SearchDecisionsHandler(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidQuery, "Invalid query parameter", "q: is required")
+		return
+	}
+	limit, err := parseListLimit(r.URL.Query().Get("limit"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidQuery, "Invalid query parameter", err.Error())
+		return
+	}
+	opts := storage.SearchOptions{Limit: limit}
+
+	if h.userRepo != nil {
+		user := GetUserFromContext(r)
+		if user == nil {
+			writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", "")
+			return
+		}
+		opts.UserID = &user.ID
+	}
+
+	results, err := h.repository.SearchDecisions(r.Context(), query, opts)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "search decisions")
+		return
+	}
+
+	resp := DecisionSearchResponse{Results: make([]DecisionSearchResult, len(results))}
+	for i, res := range results {
+		resp.Results[i] = DecisionSearchResult{DecisionResponse: decisionToResponse(res.Decision), Rank: res.Rank}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// decisionToResponse converts a stored decision to its API representation
+func decisionToResponse(d *storage.Decision) DecisionResponse {
+	resp := DecisionResponse{
+		ID:        d.ID.String(),
+		Input:     d.Input,
+		Verdict:   d.Verdict,
+		CreatedAt: d.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		IsFinal:   d.IsFinal,
+		Language:  d.Language,
+		Usage: UsageDTO{
+			InputTokens:  d.InputTokens,
+			OutputTokens: d.OutputTokens,
+			CostUSD:      d.CostUSD,
+		},
+		Providers:   d.Providers,
+		ContentHash: d.ContentHash,
+		Signature:   d.Signature,
+	}
+	if d.Supersedes != nil {
+		resp.Supersedes = d.Supersedes.String()
+	}
+	if d.SupersededBy != nil {
+		resp.SupersededBy = d.SupersededBy.String()
+	}
+	return resp
+}
+
+// parseListOptions reads the filters and paging of a listing from the query
+// string: from and to (RFC 3339 or YYYY-MM-DD), language, is_final,
+// min_score, max_score, order (asc or desc), cursor and limit
+func parseListOptions(r *http.Request) (storage.ListOptions, error) {
+	q := r.URL.Query()
+	opts := storage.ListOptions{
+		Language: q.Get("language"),
+		Cursor:   q.Get("cursor"),
+	}
+
+	var err error
+	if opts.From, err = parseListTime(q.Get("from")); err != nil {
+		return opts, fmt.Errorf("from: %w", err)
+	}
+	if opts.To, err = parseListTime(q.Get("to")); err != nil {
+		return opts, fmt.Errorf("to: %w", err)
+	}
+	if v := q.Get("is_final"); v != "" {
+		isFinal, err := strconv.ParseBool(v)
+		if err != nil {
+			return opts, fmt.Errorf("is_final: must be true or false")
+		}
+		opts.IsFinal = &isFinal
+	}
+	scores := []struct {
+		name string
+		dst  **float64
+	}{{"min_score", &opts.MinScore}, {"max_score", &opts.MaxScore}}
+	for _, s := range scores {
+		if v := q.Get(s.name); v != "" {
+			score, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return opts, fmt.Errorf("%s: must be a number", s.name)
+			}
+			*s.dst = &score
+		}
+	}
+	switch q.Get("order") {
+	case "", "desc":
+	case "asc":
+		opts.Ascending = true
+	default:
+		return opts, fmt.Errorf("order: must be asc or desc")
+	}
+	if opts.Limit, err = parseListLimit(q.Get("limit")); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// parseListLimit parses a page size. An empty string is zero, the default.
+func parseListLimit(v string) (int, error) {
+	if v == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > storage.MaxListLimit {
+		return 0, fmt.Errorf("limit: must be between 1 and %d", storage.MaxListLimit)
+	}
+	return limit, nil
+}
+
+// parseListTime parses an RFC 3339 timestamp or a YYYY-MM-DD date (midnight
+// UTC). An empty string is the zero time.
+func parseListTime(v string) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, v); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("must be RFC 3339 or YYYY-MM-DD")
 }
 
 // GetTodoHandler handles GET /api/todos/{id} requests
@@ -334,11 +743,7 @@ func (h *Handlers) GetTodoHandler(w http.ResponseWriter, r *http.Request) {
 
 	todo, err := h.repository.GetTodo(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			writeError(w, http.StatusNotFound, ErrCodeNotFound, "Todo not found", "")
-			return
-		}
-		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to retrieve todo", err.Error())
+		writeStorageError(w, err, "Todo", "retrieve todo")
 		return
 	}
 
@@ -3,6 +3,7 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -38,9 +39,13 @@ func (m *mockLLMClient) CompleteJSON(ctx context.Context, prompt string, result
 
 // mockRepository is a mock implementation of the storage repository
 type mockRepository struct {
+	storage.Repository // Methods not overridden below are not exercised by these tests
+
 	decisions map[uuid.UUID]*storage.Decision
 	todos     map[uuid.UUID]*storage.Todo
+	states    []*storage.StateSnapshot
 	pingErr   error
+	getErr    error // Returned by GetDecision when set
 }
 
 func newMockRepository() *mockRepository {
@@ -59,6 +64,9 @@ func (m *mockRepository) CreateDecision(ctx context.Context, d *storage.Decision
 }
 
 func (m *mockRepository) GetDecision(ctx context.Context, id uuid.UUID) (*storage.Decision, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
 	if d, ok := m.decisions[id]; ok {
 		return d, nil
 	}
@@ -102,6 +110,11 @@ func (m *mockRepository) SaveArtifacts(ctx context.Context, d *storage.Decision,
 	return nil
 }
 
+func (m *mockRepository) CreateStateSnapshot(ctx context.Context, s *storage.StateSnapshot) error {
+	m.states = append(m.states, s)
+	return nil
+}
+
 func (m *mockRepository) Ping(ctx context.Context) error {
 	return m.pingErr
 }
@@ -116,6 +129,79 @@ func (e *notFoundError) Error() string {
 	return e.message
 }
 
+func (e *notFoundError) Unwrap() error {
+	return storage.ErrNotFound
+}
+
+// newTestLLMClient returns an LLM client that always rules "Use Rust" with a
+// one-phase plan of one task, passing each verdict prompt to onVerdict if set
+func newTestLLMClient(onVerdict func(prompt string)) *mockLLMClient {
+	return &mockLLMClient{
+		completeJSONFunc: func(ctx context.Context, prompt string, result any) error {
+			switch v := result.(type) {
+			case *agent.VerdictOutput:
+				if onVerdict != nil {
+					onVerdict(prompt)
+				}
+				v.Ruling = "Use Rust"
+				v.Rationale = "The team now knows Rust"
+			case *agent.ExecutionOutput:
+				v.MVPScope = []string{"Port the parser"}
+				v.Phases = []agent.Phase{{Name: "Phase 1", Tasks: []string{"Task 1"}}}
+				v.DoneCriteria = []string{"Parser ported"}
+			}
+			return nil
+		},
+	}
+}
+
+// storeTestDecision stores a decision ruling "Use Go", without a plan
+func storeTestDecision(t *testing.T, repo storage.Repository) *storage.Decision {
+	t.Helper()
+	d := &storage.Decision{
+		Input:   "Should the parser be written in Go or Rust?",
+		Verdict: json.RawMessage(`{"input":"...","verdict":{"ruling":"Use Go","rationale":"Nobody knows Rust","rejected":[{"option":"Rust","reason":"No experience"}]},"is_final":true}`),
+		IsFinal: true,
+	}
+	if err := repo.SaveArtifacts(context.Background(), d, &storage.Todo{Content: "# Todo"}); err != nil {
+		t.Fatalf("SaveArtifacts() error = %v", err)
+	}
+	return d
+}
+
+// newTestHandlers returns a router whose pipeline answers with
+// newTestLLMClient, the generator it uses, and a memory repository with user
+// accounts and a decision stored in it. configure may change the router
+// configuration first.
+func newTestHandlers(t *testing.T, configure ...func(*RouterConfig)) (*artifact.Generator, *storage.MemoryRepository, *storage.Decision, http.Handler) {
+	t.Helper()
+	repo := storage.NewMemoryRepository()
+	decision := storeTestDecision(t, repo)
+	llmClient := newTestLLMClient(nil)
+	p := pipeline.NewPipeline(agent.NewVerdictAgent(llmClient), agent.NewExecutionAgent(llmClient), 10*time.Minute)
+
+	cfg := RouterConfig{Pipeline: p, Generator: artifact.NewGenerator(), Repository: repo, UserRepository: repo, RateLimit: 100}
+	for _, c := range configure {
+		c(&cfg)
+	}
+	return cfg.Generator, repo, decision, NewRouter(cfg)
+}
+
+// newTestSession creates a user and returns the token of a session of theirs
+func newTestSession(t *testing.T, repo *storage.MemoryRepository, username string) string {
+	t.Helper()
+	ctx := context.Background()
+	user, err := repo.CreateUser(ctx, username, "password123")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	token, err := repo.CreateSession(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("CreateSession() error = %v", err)
+	}
+	return token
+}
+
 func TestHealthHandler(t *testing.T) {
 	repo := newMockRepository()
 	handlers := NewHandlers(nil, nil, repo)
@@ -290,6 +376,117 @@ func TestVerdictHandler_Success(t *testing.T) {
 	}
 }
 
+func TestVerdictHandler_Duplicate(t *testing.T) {
+	calls := 0
+	llmClient := &mockLLMClient{
+		completeJSONFunc: func(ctx context.Context, prompt string, result any) error {
+			calls++
+			switch v := result.(type) {
+			case *agent.VerdictOutput:
+				v.Ruling = "Use Go"
+				v.Rationale = "Go is great for this project"
+			case *agent.ExecutionOutput:
+				v.MVPScope = []string{"Basic implementation"}
+				v.Phases = []agent.Phase{{Name: "Phase 1", Tasks: []string{"Task 1"}}}
+				v.DoneCriteria = []string{"All tests pass"}
+			}
+			return nil
+		},
+	}
+
+	repo := storage.NewMemoryRepository()
+	earlier := &storage.Decision{Input: "Should I use Go or Python for this project?", Verdict: json.RawMessage(`{"ruling":"Use Python"}`), IsFinal: true}
+	if err := repo.SaveArtifacts(context.Background(), earlier, &storage.Todo{Content: "# Earlier todo"}); err != nil {
+		t.Fatalf("SaveArtifacts() error = %v", err)
+	}
+	p := pipeline.NewPipeline(agent.NewVerdictAgent(llmClient), agent.NewExecutionAgent(llmClient), 10*time.Minute)
+	handlers := NewHandlers(p, artifact.NewGenerator(), repo)
+	handlers.duplicateThreshold = 0.9
+
+	verdict := func(body string) VerdictResponse {
+		req := httptest.NewRequest(http.MethodPost, "/api/verdict", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handlers.VerdictHandler(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var resp VerdictResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	resp := verdict(`{"input": "should I use Go or Python for this project"}`)
+	if resp.DuplicateOf != earlier.ID.String() || resp.DecisionID != earlier.ID.String() {
+		t.Errorf("expected duplicate of %s, got %+v", earlier.ID, resp)
+	}
+	if resp.Similarity != 1 || resp.Todo != "# Earlier todo" || string(resp.Decision) != `{"ruling":"Use Python"}` {
+		t.Errorf("expected the earlier decision, got %+v", resp)
+	}
+	if calls != 0 {
+		t.Errorf("expected no LLM calls for a duplicate, got %d", calls)
+	}
+
+	resp = verdict(`{"input": "Should I use Go or Python for this project?", "force": true}`)
+	if resp.DuplicateOf != "" || resp.DecisionID == earlier.ID.String() || calls == 0 {
+		t.Errorf("expected a new ruling when forced, got %+v after %d LLM calls", resp, calls)
+	}
+
+	// With user accounts, anonymous callers never match
+	handlers.userRepo = repo
+	resp = verdict(`{"input": "Should I use Go or Python for this project?"}`)
+	if resp.DuplicateOf != "" {
+		t.Errorf("expected a new ruling for an anonymous caller, got duplicate of %s", resp.DuplicateOf)
+	}
+}
+
+func TestVerdictHandler_ProviderErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantRetry  string
+	}{
+		{"rate limited", &agent.ProviderError{Provider: "openai", Class: agent.ErrRateLimited, StatusCode: 429, RetryAfter: 1500 * time.Millisecond}, http.StatusServiceUnavailable, ErrCodeLLMRateLimited, "2"},
+		{"overloaded", &agent.ProviderError{Provider: "anthropic", Class: agent.ErrOverloaded, StatusCode: 529}, http.StatusServiceUnavailable, ErrCodeLLMOverloaded, ""},
+		{"auth", &agent.ProviderError{Provider: "openai", Class: agent.ErrAuth, StatusCode: 401}, http.StatusBadGateway, ErrCodeLLMAuthFailed, ""},
+		{"context length", &agent.ProviderError{Provider: "openai", Class: agent.ErrContextLength, StatusCode: 400}, http.StatusRequestEntityTooLarge, ErrCodeContextTooLong, ""},
+		{"content filtered", &agent.ProviderError{Provider: "gemini", Class: agent.ErrContentFiltered}, http.StatusUnprocessableEntity, ErrCodeContentFiltered, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			llmClient := &mockLLMClient{
+				completeJSONFunc: func(ctx context.Context, prompt string, result any) error {
+					return tt.err
+				},
+			}
+			p := pipeline.NewPipeline(agent.NewVerdictAgent(llmClient), agent.NewExecutionAgent(llmClient), 10*time.Minute)
+			handlers := NewHandlers(p, artifact.NewGenerator(), newMockRepository())
+
+			req := httptest.NewRequest(http.MethodPost, "/api/verdict", strings.NewReader(`{"input": "Go or Rust?"}`))
+			rec := httptest.NewRecorder()
+			handlers.VerdictHandler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			var resp ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("expected code %s, got %s", tt.wantCode, resp.Code)
+			}
+			if got := rec.Header().Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("expected Retry-After %q, got %q", tt.wantRetry, got)
+			}
+		})
+	}
+}
+
 func TestGetDecisionHandler_Success(t *testing.T) {
 	repo := newMockRepository()
 	id := uuid.New()
@@ -351,6 +548,30 @@ func TestGetDecisionHandler_NotFound(t *testing.T) {
 	}
 }
 
+func TestGetDecisionHandler_StorageFailure(t *testing.T) {
+	repo := newMockRepository()
+	repo.getErr = errors.New("failed to get decision: connection refused")
+	router := NewRouter(RouterConfig{Repository: repo})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/decisions/"+uuid.New().String(), nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, rec.Code)
+	}
+
+	var response ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Code != ErrCodeInternalError {
+		t.Errorf("expected error code '%s', got '%s'", ErrCodeInternalError, response.Code)
+	}
+}
+
 func TestGetDecisionHandler_InvalidID(t *testing.T) {
 	repo := newMockRepository()
 	router := NewRouter(RouterConfig{Repository: repo})
@@ -374,6 +595,126 @@ func TestGetDecisionHandler_InvalidID(t *testing.T) {
 	}
 }
 
+func TestListDecisionsHandler(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, input := range []string{"Go or Rust?", "用 Go 还是 Rust？", "Tabs or spaces?"} {
+		err := repo.CreateDecision(context.Background(), &storage.Decision{
+			Input:     input,
+			Verdict:   json.RawMessage(`{"ruling":"Go"}`),
+			CreatedAt: base.Add(time.Duration(i) * 24 * time.Hour),
+			IsFinal:   true,
+			Language:  agent.DetectLanguage(input),
+		})
+		if err != nil {
+			t.Fatalf("CreateDecision() error = %v", err)
+		}
+	}
+	router := NewRouter(RouterConfig{Repository: repo, RateLimit: 100})
+
+	list := func(query string) (int, DecisionListResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/api/decisions"+query, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var resp DecisionListResponse
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	// Two pages, newest first
+	code, page := list("?limit=2")
+	if code != http.StatusOK || len(page.Decisions) != 2 || page.NextCursor == "" {
+		t.Fatalf("first page = %d, %+v", code, page)
+	}
+	if page.Decisions[0].Input != "Tabs or spaces?" {
+		t.Errorf("expected newest decision first, got %q", page.Decisions[0].Input)
+	}
+	code, page = list("?limit=2&cursor=" + page.NextCursor)
+	if code != http.StatusOK || len(page.Decisions) != 1 || page.NextCursor != "" {
+		t.Fatalf("second page = %d, %+v", code, page)
+	}
+	if page.Decisions[0].Input != "Go or Rust?" {
+		t.Errorf("expected oldest decision last, got %q", page.Decisions[0].Input)
+	}
+
+	// Filters
+	code, page = list("?language=zh")
+	if code != http.StatusOK || len(page.Decisions) != 1 || page.Decisions[0].Language != "zh" {
+		t.Errorf("language filter = %d, %+v", code, page)
+	}
+	code, page = list("?from=2025-03-02&to=2025-03-03&order=asc")
+	if code != http.StatusOK || len(page.Decisions) != 1 || page.Decisions[0].Input != "用 Go 还是 Rust？" {
+		t.Errorf("date filter = %d, %+v", code, page)
+	}
+
+	for _, query := range []string{"?limit=0", "?limit=1000", "?order=sideways", "?from=yesterday", "?is_final=maybe", "?min_score=high", "?cursor=bogus"} {
+		if code, _ := list(query); code != http.StatusBadRequest {
+			t.Errorf("GET /api/decisions%s: expected status %d, got %d", query, http.StatusBadRequest, code)
+		}
+	}
+}
+
+func TestListDecisionsHandler_RequiresAuthWithUsers(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	router := NewRouter(RouterConfig{Repository: repo, UserRepository: repo})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/decisions", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rec.Code)
+	}
+}
+
+func TestSearchDecisionsHandler(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	for _, input := range []string{"Postgres or MySQL?", "Redis or Memcached?"} {
+		d := &storage.Decision{Input: input, Verdict: json.RawMessage(`{"ruling":"Postgres"}`), IsFinal: true}
+		if err := repo.SaveArtifacts(context.Background(), d, &storage.Todo{Content: "- [ ] Provision " + input}); err != nil {
+			t.Fatalf("SaveArtifacts() error = %v", err)
+		}
+	}
+	router := NewRouter(RouterConfig{Repository: repo, RateLimit: 100})
+
+	search := func(query string) (int, DecisionSearchResponse) {
+		req := httptest.NewRequest(http.MethodGet, "/api/decisions/search"+query, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		var resp DecisionSearchResponse
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := search("?q=postgres")
+	if code != http.StatusOK || len(resp.Results) != 2 {
+		t.Fatalf("search = %d, %+v", code, resp)
+	}
+	if resp.Results[0].Input != "Postgres or MySQL?" || resp.Results[0].Rank <= resp.Results[1].Rank {
+		t.Errorf("expected the input match ranked first, got %+v", resp.Results)
+	}
+	code, resp = search("?q=memcached&limit=1")
+	if code != http.StatusOK || len(resp.Results) != 1 || resp.Results[0].Input != "Redis or Memcached?" {
+		t.Errorf("search = %d, %+v", code, resp)
+	}
+
+	for _, query := range []string{"", "?q=", "?q=+", "?q=redis&limit=0"} {
+		if code, _ := search(query); code != http.StatusBadRequest {
+			t.Errorf("GET /api/decisions/search%s: expected status %d, got %d", query, http.StatusBadRequest, code)
+		}
+	}
+}
+
 func TestGetTodoHandler_Success(t *testing.T) {
 	repo := newMockRepository()
 	decisionID := uuid.New()
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// DecisionVerificationResponse represents the response for
+// GET /api/decisions/{id}/verify
+type DecisionVerificationResponse struct {
+	DecisionID string `json:"decision_id"`
+	// Verified is true if a content hash was recorded, the stored artifacts
+	// still match it and, when the signature can be checked, it is valid
+	Verified     bool   `json:"verified"`
+	StoredHash   string `json:"stored_hash,omitempty"`
+	ComputedHash string `json:"computed_hash,omitempty"`
+	Signed       bool   `json:"signed"`
+	// SignatureValid is omitted if the decision is unsigned or the server
+	// has no signing key to check it with
+	SignatureValid *bool  `json:"signature_valid,omitempty"`
+	PublicKey      string `json:"public_key,omitempty"` // Server's base64 Ed25519 public key
+	// Drift lists every way the stored decision differs from what was recorded
+	Drift []string `json:"drift,omitempty"`
+}
+
+// VerifyDecisionHandler handles GET /api/decisions/{id}/verify requests. It
+// recomputes the content hash of the stored decision.json and todo.md and
+// reports any drift from the hash and signature recorded when the decision
+// was made. A decision that fails verification is still a 200 response.
+func (h *Handlers) VerifyDecisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid decision ID", "Must be a valid UUID")
+		return
+	}
+
+	decision, err := h.repository.GetDecision(r.Context(), id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision")
+		return
+	}
+	var todoMD []byte
+	todo, err := h.repository.GetTodoByDecisionID(r.Context(), id)
+	switch {
+	case err == nil:
+		todoMD = []byte(todo.Content)
+	case !errors.Is(err, storage.ErrNotFound):
+		writeStorageError(w, err, "Todo", "retrieve todo")
+		return
+	}
+
+	var pub ed25519.PublicKey
+	if h.generator != nil {
+		pub = h.generator.PublicKey()
+	}
+	writeJSON(w, http.StatusOK, verifyDecision(decision, todoMD, todo != nil, pub))
+}
+
+// verifyDecision checks a stored decision and its todo.md against the content
+// hash and signature recorded with it. pub is nil if signatures cannot be
+// checked.
+func verifyDecision(d *storage.Decision, todoMD []byte, hasTodo bool, pub ed25519.PublicKey) DecisionVerificationResponse {
+	resp := DecisionVerificationResponse{
+		DecisionID: d.ID.String(),
+		StoredHash: d.ContentHash,
+		Signed:     d.Signature != "",
+	}
+	if pub != nil {
+		resp.PublicKey = base64.StdEncoding.EncodeToString(pub)
+	}
+
+	if d.ContentHash == "" {
+		resp.Drift = append(resp.Drift, "no content hash was recorded for this decision")
+	}
+	if !hasTodo {
+		resp.Drift = append(resp.Drift, "todo.md is missing")
+	}
+
+	computed, err := artifact.ContentHash(d.Verdict, todoMD)
+	if err != nil {
+		resp.Drift = append(resp.Drift, "decision.json is not valid JSON")
+	} else {
+		resp.ComputedHash = computed
+		if d.ContentHash != "" && computed != d.ContentHash {
+			resp.Drift = append(resp.Drift, "decision.json or todo.md differs from the recorded content hash")
+		}
+	}
+
+	// The hash covers decision.json, not the columns stored beside it
+	var doc artifact.Decision
+	if err == nil && json.Unmarshal(d.Verdict, &doc) == nil && doc.ID != "" {
+		if doc.ID != d.ID.String() {
+			resp.Drift = append(resp.Drift, "decision.json id differs from the decision ID")
+		}
+		if doc.Input != d.Input {
+			resp.Drift = append(resp.Drift, "decision.json input differs from the stored input")
+		}
+	}
+
+	if resp.Signed && pub != nil {
+		valid := artifact.VerifySignature(pub, d.ContentHash, d.Signature)
+		resp.SignatureValid = &valid
+		if !valid {
+			resp.Drift = append(resp.Drift, "signature does not match the recorded content hash")
+		}
+	}
+
+	resp.Verified = len(resp.Drift) == 0
+	return resp
+}
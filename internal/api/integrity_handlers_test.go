@@ -0,0 +1,115 @@
+package api
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestVerifyDecisionHandler(t *testing.T) {
+	generator, repo, prior, router := newTestHandlers(t)
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	generator.SetSigningKey(key)
+
+	verify := func(t *testing.T, id string) DecisionVerificationResponse {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/decisions/"+id+"/verify", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var resp DecisionVerificationResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/verdict", strings.NewReader(`{"input": "Go or Rust?"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	var verdict VerdictResponse
+	if err := json.NewDecoder(rec.Body).Decode(&verdict); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if verdict.ContentHash == "" || verdict.Signature == "" {
+		t.Fatalf("expected a signed content hash, got %+v", verdict)
+	}
+
+	t.Run("unchanged", func(t *testing.T) {
+		resp := verify(t, verdict.DecisionID)
+		if !resp.Verified || resp.ComputedHash != verdict.ContentHash || resp.SignatureValid == nil || !*resp.SignatureValid || len(resp.Drift) != 0 {
+			t.Errorf("expected a verified decision, got %+v", resp)
+		}
+	})
+
+	id := uuid.MustParse(verdict.DecisionID)
+	d, err := repo.GetDecision(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetDecision() error = %v", err)
+	}
+	todo, err := repo.GetTodoByDecisionID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetTodoByDecisionID() error = %v", err)
+	}
+
+	t.Run("edited todo", func(t *testing.T) {
+		original := todo.Content
+		todo.Content += "\n- [ ] Rewrite in Rust"
+		defer func() { todo.Content = original }()
+
+		resp := verify(t, verdict.DecisionID)
+		if resp.Verified || resp.ComputedHash == verdict.ContentHash || len(resp.Drift) != 1 {
+			t.Errorf("expected content drift, got %+v", resp)
+		}
+		if resp.SignatureValid == nil || !*resp.SignatureValid {
+			t.Errorf("the signature still matches the recorded hash, got %+v", resp.SignatureValid)
+		}
+	})
+
+	t.Run("edited ruling and rehashed", func(t *testing.T) {
+		original, originalHash := d.Verdict, d.ContentHash
+		d.Verdict = json.RawMessage(strings.Replace(string(d.Verdict), "Use Rust", "Use Go", 1))
+		d.ContentHash = verify(t, verdict.DecisionID).ComputedHash
+		defer func() { d.Verdict, d.ContentHash = original, originalHash }()
+
+		resp := verify(t, verdict.DecisionID)
+		if resp.Verified || resp.SignatureValid == nil || *resp.SignatureValid {
+			t.Errorf("expected an invalid signature, got %+v", resp)
+		}
+	})
+
+	t.Run("edited input column", func(t *testing.T) {
+		original := d.Input
+		d.Input = "Go or Zig?"
+		defer func() { d.Input = original }()
+
+		if resp := verify(t, verdict.DecisionID); resp.Verified || len(resp.Drift) != 1 {
+			t.Errorf("expected input drift, got %+v", resp)
+		}
+	})
+
+	t.Run("no recorded hash", func(t *testing.T) {
+		resp := verify(t, prior.ID.String())
+		if resp.Verified || resp.Signed || resp.ComputedHash == "" || len(resp.Drift) != 1 {
+			t.Errorf("expected an unverified decision, got %+v", resp)
+		}
+	})
+
+	req = httptest.NewRequest(http.MethodGet, "/api/decisions/"+uuid.New().String()+"/verify", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown decision: expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/jobs"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// JobResponse represents an asynchronous verdict job in API responses
+type JobResponse struct {
+	ID         string          `json:"id"`
+	Status     string          `json:"status"`
+	Result     json.RawMessage `json:"result,omitempty"` // VerdictResponse once succeeded
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  string          `json:"created_at"`
+	UpdatedAt  string          `json:"updated_at"`
+	StartedAt  string          `json:"started_at,omitempty"`
+	FinishedAt string          `json:"finished_at,omitempty"`
+}
+
+// CreateJobHandler handles POST /api/jobs requests.
+// It accepts the same body as POST /api/verdict, enqueues a pipeline run and
+// returns the job immediately.
+func (h *Handlers) CreateJobHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeVerdictRequest(w, r)
+	if !ok {
+		return
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to encode job", err.Error())
+		return
+	}
+
+	job := &storage.Job{Request: payload}
+	if user := GetUserFromContext(r); user != nil {
+		job.UserID = &user.ID
+	}
+
+	if err := h.jobQueue.Enqueue(r.Context(), job); err != nil {
+		if errors.Is(err, jobs.ErrQueueFull) || errors.Is(err, jobs.ErrNotRunning) {
+			writeError(w, http.StatusServiceUnavailable, ErrCodeQueueFull, "Job queue is unavailable", err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to enqueue job", err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/api/jobs/"+job.ID.String())
+	writeJSON(w, http.StatusAccepted, jobToResponse(job))
+}
+
+// GetJobHandler handles GET /api/jobs/{id} requests
+func (h *Handlers) GetJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.loadJob(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, jobToResponse(job))
+}
+
+// CancelJobHandler handles DELETE /api/jobs/{id} requests
+func (h *Handlers) CancelJobHandler(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.loadJob(w, r)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobQueue.Cancel(r.Context(), job.ID)
+	if err != nil {
+		if errors.Is(err, jobs.ErrJobFinished) {
+			writeError(w, http.StatusConflict, ErrCodeJobFinished, "Job already finished", string(job.Status))
+			return
+		}
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to cancel job", err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, jobToResponse(job))
+}
+
+// loadJob fetches the job named in the URL and checks the caller may access it.
+// It writes an error response and returns false on failure.
+func (h *Handlers) loadJob(w http.ResponseWriter, r *http.Request) (*storage.Job, bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid job ID", "Must be a valid UUID")
+		return nil, false
+	}
+
+	job, err := h.repository.GetJob(r.Context(), id)
+	if err != nil {
+		writeStorageError(w, err, "Job", "retrieve job")
+		return nil, false
+	}
+
+	// Jobs submitted by a signed-in user are private to that user
+	if job.UserID != nil {
+		if err := checkOwner(GetUserFromContext(r), *job.UserID); err != nil {
+			writeStorageError(w, err, "Job", "retrieve job")
+			return nil, false
+		}
+	}
+
+	return job, true
+}
+
+// RunJob executes a queued verdict request: duplicate and clarification checks,
+// pipeline, artifact generation and persistence. It returns the VerdictResponse payload.
+func (h *Handlers) RunJob(ctx context.Context, job *storage.Job) (json.RawMessage, error) {
+	var req VerdictRequest
+	if err := json.Unmarshal(job.Request, &req); err != nil {
+		return nil, fmt.Errorf("invalid job request: %w", err)
+	}
+
+	var user *storage.User
+	if job.UserID != nil {
+		user = &storage.User{ID: *job.UserID}
+	}
+
+	// An earlier decision on the same question is returned instead of a new ruling
+	if resp := h.findDuplicate(ctx, user, &req); resp != nil {
+		return json.Marshal(resp)
+	}
+
+	// Clarification questions are a valid outcome for a job
+	if resp := h.checkClarification(ctx, &req); resp != nil {
+		return json.Marshal(resp)
+	}
+
+	result, err := h.pipeline.Execute(ctx, h.enrichedInput(&req))
+	if err != nil {
+		return nil, err
+	}
+
+	artifacts, err := h.generator.Generate(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate artifacts: %w", err)
+	}
+
+	historyID, err := h.saveArtifacts(ctx, user, result, artifacts, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save artifacts: %w", err)
+	}
+
+	return json.Marshal(buildVerdictResponse(result, artifacts, historyID))
+}
+
+// jobToResponse converts a Job to JobResponse
+func jobToResponse(j *storage.Job) JobResponse {
+	resp := JobResponse{
+		ID:        j.ID.String(),
+		Status:    string(j.Status),
+		Result:    j.Result,
+		Error:     j.Error,
+		CreatedAt: j.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: j.UpdatedAt.Format(time.RFC3339),
+	}
+	if j.StartedAt != nil {
+		resp.StartedAt = j.StartedAt.Format(time.RFC3339)
+	}
+	if j.FinishedAt != nil {
+		resp.FinishedAt = j.FinishedAt.Format(time.RFC3339)
+	}
+	return resp
+}
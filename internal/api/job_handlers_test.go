@@ -0,0 +1,151 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/jobs"
+	"github.com/1psychoQAQ/verdict-agent/internal/pipeline"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/google/uuid"
+)
+
+func newJobTestRouter(t *testing.T, llmClient agent.LLMClient) http.Handler {
+	t.Helper()
+
+	repo := storage.NewMemoryRepository()
+	p := pipeline.NewPipeline(agent.NewVerdictAgent(llmClient), agent.NewExecutionAgent(llmClient), time.Minute)
+	queue := jobs.NewQueue(repo, jobs.Config{Workers: 1})
+
+	router := NewRouter(RouterConfig{
+		Pipeline:   p,
+		Generator:  artifact.NewGenerator(),
+		Repository: repo,
+		JobQueue:   queue,
+		RateLimit:  100,
+	})
+
+	if err := queue.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start queue: %v", err)
+	}
+	t.Cleanup(queue.Stop)
+
+	return router
+}
+
+func pollJob(t *testing.T, router http.Handler, id string, want string) JobResponse {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	var job JobResponse
+	for time.Now().Before(deadline) {
+		req := httptest.NewRequest(http.MethodGet, "/api/jobs/"+id, nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		json.NewDecoder(rec.Body).Decode(&job)
+		if job.Status == want {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job did not reach status %s, last status %s", want, job.Status)
+	return job
+}
+
+func TestJobHandlers_Lifecycle(t *testing.T) {
+	router := newJobTestRouter(t, newVerdictLLMClient(nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"input": "Should I use Go?"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusAccepted, rec.Code, rec.Body.String())
+	}
+	var created JobResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.ID == "" || created.Status != string(storage.JobQueued) {
+		t.Errorf("unexpected job: %+v", created)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/api/jobs/"+created.ID {
+		t.Errorf("unexpected Location header %q", loc)
+	}
+
+	done := pollJob(t, router, created.ID, string(storage.JobSucceeded))
+	var result VerdictResponse
+	if err := json.Unmarshal(done.Result, &result); err != nil {
+		t.Fatalf("failed to decode job result: %v", err)
+	}
+	if result.Status != "verdict" || result.DecisionID == "" {
+		t.Errorf("unexpected job result: %+v", result)
+	}
+
+	// Finished jobs cannot be canceled
+	req = httptest.NewRequest(http.MethodDelete, "/api/jobs/"+created.ID, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected status %d, got %d: %s", http.StatusConflict, rec.Code, rec.Body.String())
+	}
+}
+
+func TestJobHandlers_Cancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	llmClient := &mockLLMClient{
+		completeJSONFunc: func(ctx context.Context, prompt string, result any) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}
+	router := newJobTestRouter(t, llmClient)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/jobs", strings.NewReader(`{"input": "Should I use Go?"}`))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var created JobResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	<-started
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/jobs/"+created.ID, nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	pollJob(t, router, created.ID, string(storage.JobCanceled))
+}
+
+func TestJobHandlers_NotFound(t *testing.T) {
+	router := newJobTestRouter(t, &mockLLMClient{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/jobs/"+uuid.New().String(), nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/jobs/not-a-uuid", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
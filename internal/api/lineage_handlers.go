@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RevisitRequest represents the request body for POST /api/decisions/{id}/revisit
+type RevisitRequest struct {
+	Context string `json:"context,omitempty"` // What has changed since the decision
+}
+
+// DecisionLineageResponse represents the response for GET /api/decisions/{id}/lineage
+type DecisionLineageResponse struct {
+	Lineage []DecisionResponse `json:"lineage"` // Oldest first
+	Current string             `json:"current"` // ID of the decision no other supersedes
+}
+
+// RevisitDecisionHandler handles POST /api/decisions/{id}/revisit requests.
+// It runs a new verdict with the decision's ruling as context and stores it,
+// under the decision's question, as superseding the decision, which is left
+// unchanged. Only the latest decision of a lineage can be revisited.
+func (h *Handlers) RevisitDecisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid decision ID", "Must be a valid UUID")
+		return
+	}
+
+	// The body is optional
+	var req RevisitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, ErrCodeInternalError, "Invalid JSON body", err.Error())
+		return
+	}
+
+	user := GetUserFromContext(r)
+	if h.userRepo != nil && user == nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", "")
+		return
+	}
+
+	prior, err := h.repository.GetDecision(r.Context(), id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision")
+		return
+	}
+	// Decisions made by a signed-in user are revisited only by that user
+	if prior.UserID != nil {
+		if err := checkOwner(user, *prior.UserID); err != nil {
+			writeStorageError(w, err, "Decision", "revisit decision")
+			return
+		}
+	}
+	if prior.SupersededBy != nil {
+		writeError(w, http.StatusConflict, ErrCodeConflict, "Decision already superseded",
+			"Revisit the latest decision, "+prior.SupersededBy.String())
+		return
+	}
+
+	result, err := h.pipeline.Execute(r.Context(), revisitInput(prior, strings.TrimSpace(req.Context)))
+	if err != nil {
+		writePipelineError(w, err)
+		return
+	}
+	// The prior verdict is context for the pipeline only; storing it with the
+	// question would nest it in the input of every further revisit
+	result.Input = prior.Input
+
+	artifacts, err := h.generator.Generate(result)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeVerdictFailed, "Failed to generate artifacts", err.Error())
+		return
+	}
+
+	// Fails with a conflict if another revisit superseded the decision first
+	historyID, err := h.saveArtifacts(r.Context(), user, result, artifacts, &prior.ID)
+	if err != nil {
+		writeStorageError(w, err, "Superseding decision", "save artifacts")
+		return
+	}
+
+	resp := buildVerdictResponse(result, artifacts, historyID)
+	resp.Supersedes = prior.ID.String()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetDecisionLineageHandler handles GET /api/decisions/{id}/lineage requests,
+// returning every decision of the supersession chain through the decision
+func (h *Handlers) GetDecisionLineageHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid decision ID", "Must be a valid UUID")
+		return
+	}
+
+	lineage, err := h.repository.GetDecisionLineage(r.Context(), id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision lineage")
+		return
+	}
+
+	resp := DecisionLineageResponse{
+		Lineage: make([]DecisionResponse, len(lineage)),
+		Current: lineage[len(lineage)-1].ID.String(),
+	}
+	for i, d := range lineage {
+		resp.Lineage[i] = decisionToResponse(d)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// revisitInput returns the pipeline input for revisiting a decision: its
+// question, the verdict it reached and, if given, what has changed since
+func revisitInput(prior *storage.Decision, changes string) string {
+	verdict := storedVerdict(prior)
+
+	var sb strings.Builder
+	sb.WriteString(prior.Input)
+	sb.WriteString("\n\n--- 先前裁决 / Prior Verdict ---\n")
+	sb.WriteString("- Ruling: " + verdict.Ruling + "\n")
+	sb.WriteString("- Rationale: " + verdict.Rationale + "\n")
+	for _, rejected := range verdict.Rejected {
+		sb.WriteString("- Rejected: " + rejected.Option + " (" + rejected.Reason + ")\n")
+	}
+	if changes != "" {
+		sb.WriteString("\n--- 变化 / What Has Changed ---\n")
+		sb.WriteString(changes)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// storedVerdict returns the verdict of a stored decision. Stored verdicts are
+// decision.json documents; a bare verdict is accepted too.
+func storedVerdict(d *storage.Decision) artifact.DecisionVerdict {
+	var doc artifact.Decision
+	_ = json.Unmarshal(d.Verdict, &doc)
+	verdict := doc.Verdict
+	if verdict.Ruling == "" {
+		_ = json.Unmarshal(d.Verdict, &verdict)
+	}
+	return verdict
+}
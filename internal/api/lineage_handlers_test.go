@@ -0,0 +1,224 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/pipeline"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// newRevisitTest returns handlers whose pipeline records the verdict prompts
+// it is given, and a stored decision to revisit
+func newRevisitTest(t *testing.T) (*Handlers, *storage.MemoryRepository, *storage.Decision, *[]string) {
+	t.Helper()
+	var prompts []string
+	llmClient := newTestLLMClient(func(prompt string) { prompts = append(prompts, prompt) })
+	repo := storage.NewMemoryRepository()
+	prior := storeTestDecision(t, repo)
+
+	p := pipeline.NewPipeline(agent.NewVerdictAgent(llmClient), agent.NewExecutionAgent(llmClient), 10*time.Minute)
+	return NewHandlers(p, artifact.NewGenerator(), repo), repo, prior, &prompts
+}
+
+func TestRevisitDecisionHandler(t *testing.T) {
+	handlers, repo, prior, prompts := newRevisitTest(t)
+	router := NewRouter(RouterConfig{Pipeline: handlers.pipeline, Generator: handlers.generator, Repository: repo, RateLimit: 100})
+
+	revisit := func(id, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/decisions/"+id+"/revisit", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := revisit(prior.ID.String(), `{"context": "We hired two Rust engineers"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp VerdictResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Supersedes != prior.ID.String() || resp.DecisionID == "" || resp.DecisionID == prior.ID.String() {
+		t.Errorf("expected a new decision superseding %s, got %+v", prior.ID, resp)
+	}
+	if len(*prompts) != 1 {
+		t.Fatalf("expected one verdict prompt, got %d", len(*prompts))
+	}
+	for _, want := range []string{"Should the parser be written in Go or Rust?", "Use Go", "Nobody knows Rust", "We hired two Rust engineers"} {
+		if !strings.Contains((*prompts)[0], want) {
+			t.Errorf("verdict prompt does not contain %q", want)
+		}
+	}
+
+	// The superseded decision is unchanged apart from its successor
+	got, err := repo.GetDecision(context.Background(), prior.ID)
+	if err != nil {
+		t.Fatalf("GetDecision() error = %v", err)
+	}
+	if got.SupersededBy == nil || got.SupersededBy.String() != resp.DecisionID || string(got.Verdict) != string(prior.Verdict) {
+		t.Errorf("expected the prior decision superseded by %s and unchanged, got %+v", resp.DecisionID, got)
+	}
+
+	// Only the latest decision can be revisited
+	if rec := revisit(prior.ID.String(), ""); rec.Code != http.StatusConflict {
+		t.Errorf("revisiting a superseded decision: expected status %d, got %d", http.StatusConflict, rec.Code)
+	}
+	if rec := revisit(resp.DecisionID, ""); rec.Code != http.StatusOK {
+		t.Errorf("revisiting the latest decision without a body: expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	if rec := revisit(uuid.New().String(), ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown decision: expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+	if rec := revisit("not-a-uuid", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid ID: expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestRevisitDecisionHandler_StoredInput(t *testing.T) {
+	handlers, repo, prior, prompts := newRevisitTest(t)
+	router := NewRouter(RouterConfig{Pipeline: handlers.pipeline, Generator: handlers.generator, Repository: repo, RateLimit: 100})
+
+	id := prior.ID.String()
+	for _, changes := range []string{"We hired two Rust engineers", "The Rust engineers left"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/decisions/"+id+"/revisit", strings.NewReader(`{"context": "`+changes+`"}`))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		var resp VerdictResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("revisit: status %d, error %v", rec.Code, err)
+		}
+		id = resp.DecisionID
+
+		d, err := repo.GetDecision(context.Background(), uuid.MustParse(id))
+		if err != nil {
+			t.Fatalf("GetDecision() error = %v", err)
+		}
+		var doc artifact.Decision
+		if err := json.Unmarshal(d.Verdict, &doc); err != nil {
+			t.Fatalf("failed to parse stored decision.json: %v", err)
+		}
+		if d.Input != prior.Input || doc.Input != prior.Input {
+			t.Errorf("stored input = %q, decision.json input = %q, want the question %q", d.Input, doc.Input, prior.Input)
+		}
+	}
+
+	// The second revisit's prompt holds only the verdict it revisits
+	if len(*prompts) != 2 {
+		t.Fatalf("expected two verdict prompts, got %d", len(*prompts))
+	}
+	if n := strings.Count((*prompts)[1], "Prior Verdict"); n != 1 || strings.Contains((*prompts)[1], "We hired two Rust engineers") {
+		t.Errorf("second revisit prompt has %d prior verdicts:\n%s", n, (*prompts)[1])
+	}
+}
+
+func TestRevisitDecisionHandler_Owner(t *testing.T) {
+	handlers, repo, prior, _ := newRevisitTest(t)
+	handlers.userRepo = repo
+	owner := &storage.User{ID: uuid.New()}
+	prior.UserID = &owner.ID
+
+	tests := []struct {
+		name       string
+		user       *storage.User
+		wantStatus int
+	}{
+		{"anonymous", nil, http.StatusUnauthorized},
+		{"other user", &storage.User{ID: uuid.New()}, http.StatusForbidden},
+		{"owner", owner, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/decisions/"+prior.ID.String()+"/revisit", nil)
+			if tt.user != nil {
+				req = req.WithContext(context.WithValue(req.Context(), userContextKey, tt.user))
+			}
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", prior.ID.String())
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rec := httptest.NewRecorder()
+			handlers.RevisitDecisionHandler(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestGetDecisionLineageHandler(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	ctx := context.Background()
+	var ids []string
+	var prev *uuid.UUID
+	for range 3 {
+		d := &storage.Decision{Input: "Go or Rust?", Verdict: json.RawMessage(`{"ruling":"Go"}`), IsFinal: true, Supersedes: prev}
+		if err := repo.CreateDecision(ctx, d); err != nil {
+			t.Fatalf("CreateDecision() error = %v", err)
+		}
+		ids = append(ids, d.ID.String())
+		prev = &d.ID
+	}
+	router := NewRouter(RouterConfig{Repository: repo, RateLimit: 100})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/decisions/"+ids[1]+"/lineage", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var resp DecisionLineageResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Lineage) != 3 || resp.Current != ids[2] {
+		t.Fatalf("expected a lineage of 3 ending at %s, got %+v", ids[2], resp)
+	}
+	for i, d := range resp.Lineage {
+		if d.ID != ids[i] {
+			t.Errorf("lineage[%d] = %s, want %s", i, d.ID, ids[i])
+		}
+	}
+	if resp.Lineage[1].Supersedes != ids[0] || resp.Lineage[1].SupersededBy != ids[2] {
+		t.Errorf("expected the middle decision linked to both neighbours, got %+v", resp.Lineage[1])
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/decisions/"+uuid.New().String()+"/lineage", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown decision: expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestVerdictHandler_DuplicateOfRevisited(t *testing.T) {
+	handlers, repo, prior, prompts := newRevisitTest(t)
+	handlers.duplicateThreshold = 0.9
+	latest := &storage.Decision{Input: "Revisited", Verdict: json.RawMessage(`{"ruling":"Use Rust"}`), IsFinal: true, Supersedes: &prior.ID}
+	if err := repo.SaveArtifacts(context.Background(), latest, &storage.Todo{Content: "# Latest todo"}); err != nil {
+		t.Fatalf("SaveArtifacts() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/verdict", strings.NewReader(`{"input": "`+prior.Input+`"}`))
+	rec := httptest.NewRecorder()
+	handlers.VerdictHandler(rec, req)
+
+	var resp VerdictResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.DuplicateOf != latest.ID.String() || resp.Todo != "# Latest todo" || len(*prompts) != 0 {
+		t.Errorf("expected the latest decision of the lineage, got %+v", resp)
+	}
+}
@@ -16,7 +16,7 @@ type contextKey string
 const userContextKey contextKey = "user"
 
 // AuthMiddleware creates authentication middleware
-func AuthMiddleware(repo *storage.MemoryRepository) func(http.Handler) http.Handler {
+func AuthMiddleware(repo storage.UserRepository) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token := extractBearerToken(r)
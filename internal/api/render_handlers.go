@@ -0,0 +1,92 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// RenderDecisionHandler handles GET /api/decisions/{id}/render/{format}
+// requests, rendering the stored decision and its latest state in any
+// registered format
+func (h *Handlers) RenderDecisionHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid decision ID", "Must be a valid UUID")
+		return
+	}
+	registry := h.renderers()
+	format := chi.URLParam(r, "format")
+	if _, ok := registry.Lookup(format); !ok {
+		writeError(w, http.StatusNotFound, ErrCodeUnknownFormat, "Unknown format",
+			"Available formats: "+strings.Join(registry.Formats(), ", "))
+		return
+	}
+
+	ctx := r.Context()
+	decision, err := h.repository.GetDecision(ctx, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision")
+		return
+	}
+	snapshots, err := stateSnapshots(ctx, h.repository, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision state")
+		return
+	}
+	state, err := artifact.ParseState(snapshots[len(snapshots)-1].State)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to read decision state", err.Error())
+		return
+	}
+
+	out, contentType, err := renderDecision(registry, format, decision, state)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to render decision", err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(out)
+}
+
+// renderers returns the registry the generator renders todo.md with, so that
+// configured templates apply everywhere
+func (h *Handlers) renderers() *artifact.Registry {
+	if h.generator != nil && h.generator.Renderers() != nil {
+		return h.generator.Renderers()
+	}
+	return artifact.NewRegistry()
+}
+
+// renderDecision renders a stored decision at a state in a format. Metadata
+// missing from the stored verdict, such as that of decisions stored as a bare
+// verdict, is taken from the decision record.
+func renderDecision(registry *artifact.Registry, format string, d *storage.Decision, state *artifact.State) ([]byte, string, error) {
+	data, err := artifact.NewRenderData(d.Verdict, state)
+	if err != nil {
+		return nil, "", err
+	}
+	meta := &data.Metadata
+	if meta.ID == "" {
+		meta.ID = d.ID.String()
+		meta.IsFinal = d.IsFinal
+	}
+	if meta.CreatedAt == "" {
+		meta.CreatedAt = d.CreatedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if meta.Input == "" {
+		meta.Input = d.Input
+	}
+	if meta.Models == nil {
+		meta.Models = d.Providers
+	}
+	meta.ContentHash = d.ContentHash
+	meta.Signature = d.Signature
+
+	return registry.Render(format, data)
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/google/uuid"
+)
+
+func TestRenderDecisionHandler(t *testing.T) {
+	generator, repo, prior, router := newTestHandlers(t)
+	registry := artifact.NewRegistry()
+	report, err := artifact.NewTemplateRenderer("report", `<h1>{{.Ruling}}</h1>{{range .Phases}}<h2>{{.Name}}</h2>{{end}}`, "text/html; charset=utf-8")
+	if err != nil {
+		t.Fatalf("NewTemplateRenderer() error = %v", err)
+	}
+	registry.Register("report", report)
+	generator.SetRenderers(registry)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/verdict", `{"input": "Go or Rust?"}`)
+	var verdict VerdictResponse
+	if err := json.NewDecoder(rec.Body).Decode(&verdict); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("verdict: status %d, error %v", rec.Code, err)
+	}
+	renderPath := "/api/decisions/" + verdict.DecisionID + "/render/"
+
+	t.Run("todo matches the stored todo.md", func(t *testing.T) {
+		rec := do(http.MethodGet, renderPath+"todo", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		todo, err := repo.GetTodoByDecisionID(context.Background(), uuid.MustParse(verdict.DecisionID))
+		if err != nil {
+			t.Fatalf("GetTodoByDecisionID() error = %v", err)
+		}
+		if rec.Body.String() != todo.Content || rec.Header().Get("Content-Type") != "text/markdown; charset=utf-8" {
+			t.Errorf("rendered todo (%s) =\n%s\nwant\n%s", rec.Header().Get("Content-Type"), rec.Body.String(), todo.Content)
+		}
+	})
+
+	t.Run("registered template", func(t *testing.T) {
+		rec := do(http.MethodGet, renderPath+"report", "")
+		if rec.Code != http.StatusOK || rec.Body.String() != "<h1>Use Rust</h1><h2>Phase 1</h2>" {
+			t.Errorf("status %d: %s", rec.Code, rec.Body.String())
+		}
+		if got := rec.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+			t.Errorf("Content-Type = %q", got)
+		}
+	})
+
+	t.Run("json includes record metadata", func(t *testing.T) {
+		rec := do(http.MethodGet, renderPath+"json", "")
+		var data artifact.RenderData
+		if err := json.NewDecoder(rec.Body).Decode(&data); err != nil || rec.Code != http.StatusOK {
+			t.Fatalf("status %d, error %v", rec.Code, err)
+		}
+		if data.Metadata.ID != verdict.DecisionID || data.Metadata.ContentHash == "" || len(data.Criteria) != 1 {
+			t.Errorf("render data = %+v", data)
+		}
+	})
+
+	t.Run("decision stored without an id", func(t *testing.T) {
+		rec := do(http.MethodGet, "/api/decisions/"+prior.ID.String()+"/render/decision", "")
+		body := rec.Body.String()
+		if rec.Code != http.StatusOK || !strings.Contains(body, "# Decision: Use Go\n") || !strings.Contains(body, "Decision ID: "+prior.ID.String()) {
+			t.Errorf("status %d:\n%s", rec.Code, body)
+		}
+	})
+
+	for _, tt := range []struct {
+		name, path string
+		status     int
+		code       string
+	}{
+		{"unknown format", renderPath + "pdf", http.StatusNotFound, ErrCodeUnknownFormat},
+		{"unknown decision", "/api/decisions/" + uuid.NewString() + "/render/todo", http.StatusNotFound, ErrCodeNotFound},
+		{"invalid decision ID", "/api/decisions/not-a-uuid/render/todo", http.StatusBadRequest, ErrCodeInvalidID},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := do(http.MethodGet, tt.path, "")
+			if rec.Code != tt.status || !strings.Contains(rec.Body.String(), tt.code) {
+				t.Errorf("expected status %d and %s, got %d: %s", tt.status, tt.code, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
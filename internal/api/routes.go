@@ -6,6 +6,8 @@ import (
 
 	"github.com/1psychoQAQ/verdict-agent/internal/agent"
 	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/export"
+	"github.com/1psychoQAQ/verdict-agent/internal/jobs"
 	"github.com/1psychoQAQ/verdict-agent/internal/pipeline"
 	"github.com/1psychoQAQ/verdict-agent/internal/storage"
 	"github.com/go-chi/chi/v5"
@@ -17,8 +19,11 @@ type RouterConfig struct {
 	Pipeline           *pipeline.Pipeline
 	Generator          *artifact.Generator
 	Repository         storage.Repository
-	MemoryRepository   *storage.MemoryRepository // In-memory repo for auth/history
+	UserRepository     storage.UserRepository    // Optional: enables user accounts and history
 	ClarificationAgent *agent.ClarificationAgent // Optional: enables clarification flow
+	JobQueue           *jobs.Queue               // Optional: enables asynchronous verdict jobs
+	GitHubExporter     *export.GitHubExporter    // Optional: enables exporting execution plans to GitHub Issues
+	DuplicateThreshold float64                   // Input similarity (0-1] that returns an earlier decision; 0 disables
 	RateLimit          int                       // Requests per minute per IP (default: 10)
 	Timeout            time.Duration             // Request timeout (default: 10 minutes)
 	CORSConfig         CORSConfig
@@ -55,15 +60,25 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 		handlers = NewHandlers(cfg.Pipeline, cfg.Generator, cfg.Repository)
 	}
 
-	// Set memory repository for history tracking
-	if cfg.MemoryRepository != nil {
-		handlers.memoryRepo = cfg.MemoryRepository
+	handlers.duplicateThreshold = cfg.DuplicateThreshold
+	handlers.githubExporter = cfg.GitHubExporter
+
+	// Set user repository for history tracking
+	if cfg.UserRepository != nil {
+		handlers.userRepo = cfg.UserRepository
+	}
+
+	// Route queued jobs through the same verdict flow as synchronous requests
+	if cfg.JobQueue != nil {
+		handlers.jobQueue = cfg.JobQueue
+		cfg.JobQueue.SetRunner(handlers.RunJob)
 	}
 
-	// Create auth handlers if memory repo is available
+	// Create auth handlers if a user repo is available
 	var authHandlers *AuthHandlers
-	if cfg.MemoryRepository != nil {
-		authHandlers = NewAuthHandlers(cfg.MemoryRepository)
+	if cfg.UserRepository != nil {
+		authHandlers = NewAuthHandlers(cfg.UserRepository)
+		authHandlers.decisions = cfg.Repository
 	}
 
 	// Create rate limiter (10 requests per minute per IP)
@@ -77,14 +92,19 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 	r.Use(middleware.Timeout(cfg.Timeout))
 	r.Use(CORS(cfg.CORSConfig))
 
-	// Add auth middleware if memory repo is available
-	if cfg.MemoryRepository != nil {
-		r.Use(AuthMiddleware(cfg.MemoryRepository))
+	// Add auth middleware if a user repo is available
+	if cfg.UserRepository != nil {
+		r.Use(AuthMiddleware(cfg.UserRepository))
 	}
 
 	// Health check (not rate limited)
 	r.Get("/health", handlers.HealthHandler)
 
+	// Job status polling (not rate limited, so clients can poll frequently)
+	if cfg.JobQueue != nil {
+		r.Get("/api/jobs/{id}", handlers.GetJobHandler)
+	}
+
 	// API routes with rate limiting
 	r.Route("/api", func(r chi.Router) {
 		// Apply rate limiting to API routes
@@ -93,12 +113,53 @@ func NewRouter(cfg RouterConfig) *chi.Mux {
 		// POST /api/verdict - Submit idea, receive verdict + todo
 		r.Post("/verdict", handlers.VerdictHandler)
 
+		// POST /api/verdict/stream - Submit idea, receive progress events (SSE)
+		r.Post("/verdict/stream", handlers.VerdictStreamHandler)
+
+		// GET /api/decisions - List decisions, filtered and paginated
+		r.Get("/decisions", handlers.ListDecisionsHandler)
+
+		// GET /api/decisions/search?q= - Full-text search over decisions and todos
+		r.Get("/decisions/search", handlers.SearchDecisionsHandler)
+
 		// GET /api/decisions/{id} - Retrieve decision by ID
 		r.Get("/decisions/{id}", handlers.GetDecisionHandler)
 
+		// POST /api/decisions/{id}/revisit - New verdict superseding a decision
+		r.Post("/decisions/{id}/revisit", handlers.RevisitDecisionHandler)
+
+		// GET /api/decisions/{id}/lineage - Supersession chain through a decision
+		r.Get("/decisions/{id}/lineage", handlers.GetDecisionLineageHandler)
+
+		// GET /api/decisions/{id}/verify - Check a decision against its content hash
+		r.Get("/decisions/{id}/verify", handlers.VerifyDecisionHandler)
+
+		// GET /api/decisions/{id}/state - Latest state.json and its earlier versions
+		r.Get("/decisions/{id}/state", handlers.GetDecisionStateHandler)
+
+		// GET /api/decisions/{id}/render/{format} - Decision rendered in a registered format
+		r.Get("/decisions/{id}/render/{format}", handlers.RenderDecisionHandler)
+
+		// GET /api/decisions/{id}/tasks - Tracked tasks of the execution plan
+		r.Get("/decisions/{id}/tasks", handlers.ListTasksHandler)
+
+		// PATCH /api/decisions/{id}/tasks/{taskId} - Update a task's status or notes
+		r.Patch("/decisions/{id}/tasks/{taskId}", handlers.UpdateTaskHandler)
+
+		// POST /api/decisions/{id}/export/github - Export the execution plan to GitHub Issues
+		if cfg.GitHubExporter != nil {
+			r.Post("/decisions/{id}/export/github", handlers.ExportGitHubHandler)
+		}
+
 		// GET /api/todos/{id} - Retrieve todo by ID
 		r.Get("/todos/{id}", handlers.GetTodoHandler)
 
+		// Asynchronous verdict jobs (if a job queue is configured)
+		if cfg.JobQueue != nil {
+			r.Post("/jobs", handlers.CreateJobHandler)
+			r.Delete("/jobs/{id}", handlers.CancelJobHandler)
+		}
+
 		// Auth routes (if auth handlers available)
 		if authHandlers != nil {
 			r.Route("/auth", func(r chi.Router) {
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxStateAttempts bounds the retries of a state update that raced another
+// update for the same version
+const maxStateAttempts = 3
+
+// DecisionStateResponse represents the response for GET /api/decisions/{id}/state
+type DecisionStateResponse struct {
+	DecisionID string                  `json:"decision_id"`
+	State      json.RawMessage         `json:"state"`   // Latest state.json
+	History    []StateSnapshotResponse `json:"history"` // Every version, oldest first
+}
+
+// StateSnapshotResponse represents one version of a decision's state.json
+type StateSnapshotResponse struct {
+	Version   int             `json:"version"`
+	CreatedAt string          `json:"created_at"`
+	State     json.RawMessage `json:"state"`
+}
+
+// GetDecisionStateHandler handles GET /api/decisions/{id}/state requests,
+// returning the decision's latest state.json and every earlier version
+func (h *Handlers) GetDecisionStateHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid decision ID", "Must be a valid UUID")
+		return
+	}
+
+	snapshots, err := stateSnapshots(r.Context(), h.repository, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision state")
+		return
+	}
+
+	resp := DecisionStateResponse{
+		DecisionID: id.String(),
+		State:      snapshots[len(snapshots)-1].State,
+		History:    make([]StateSnapshotResponse, len(snapshots)),
+	}
+	for i, s := range snapshots {
+		resp.History[i] = StateSnapshotResponse{
+			Version:   s.Version,
+			CreatedAt: s.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			State:     s.State,
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// stateSnapshots returns every version of a decision's state, oldest first.
+// A decision stored before state.json was generated has no snapshots; its
+// first one is then built from its todo.md and returned unsaved, with a nil
+// ID.
+func stateSnapshots(ctx context.Context, repo storage.Repository, decisionID uuid.UUID) ([]*storage.StateSnapshot, error) {
+	snapshots, err := repo.ListStateSnapshots(ctx, decisionID)
+	if err != nil || len(snapshots) > 0 {
+		return snapshots, err
+	}
+
+	d, err := repo.GetDecision(ctx, decisionID)
+	if err != nil {
+		return nil, err
+	}
+	var todoMD []byte
+	todo, err := repo.GetTodoByDecisionID(ctx, decisionID)
+	switch {
+	case err == nil:
+		todoMD = []byte(todo.Content)
+	case !errors.Is(err, storage.ErrNotFound):
+		return nil, err
+	}
+
+	state, err := artifact.StateFromTodoMD(todoMD, d.ID, d.CreatedAt).JSON()
+	if err != nil {
+		return nil, err
+	}
+	return []*storage.StateSnapshot{{DecisionID: d.ID, Version: 1, State: state, CreatedAt: d.CreatedAt}}, nil
+}
+
+// recordState stores the next version of a decision's state, as changed by
+// update. If another update stored that version first, it is retried on top
+// of that one.
+func recordState(ctx context.Context, repo storage.Repository, decisionID uuid.UUID, update func(*artifact.State)) (*storage.StateSnapshot, error) {
+	for attempt := 1; ; attempt++ {
+		snapshots, err := stateSnapshots(ctx, repo, decisionID)
+		if err != nil {
+			return nil, err
+		}
+		latest := snapshots[len(snapshots)-1]
+
+		// Keep the rebuilt first version of an older decision in its history
+		if latest.ID == uuid.Nil {
+			err = repo.CreateStateSnapshot(ctx, latest)
+		}
+		var next *storage.StateSnapshot
+		if err == nil {
+			next, err = nextStateSnapshot(latest, update)
+		}
+		if err == nil {
+			err = repo.CreateStateSnapshot(ctx, next)
+		}
+		if errors.Is(err, storage.ErrConflict) && attempt < maxStateAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return next, nil
+	}
+}
+
+// nextStateSnapshot returns the snapshot following latest, as changed by update
+func nextStateSnapshot(latest *storage.StateSnapshot, update func(*artifact.State)) (*storage.StateSnapshot, error) {
+	prev, err := artifact.ParseState(latest.State)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	next := prev.Next(now)
+	update(next)
+	state, err := next.JSON()
+	if err != nil {
+		return nil, err
+	}
+	return &storage.StateSnapshot{DecisionID: latest.DecisionID, Version: next.Version, State: state, CreatedAt: now}, nil
+}
+
+// withDoneCriteria returns a state update that sets the completion of the
+// done criteria to that of criteria, matched by index
+func withDoneCriteria(criteria []storage.DoneCriterion) func(*artifact.State) {
+	completed := make(map[int]bool, len(criteria))
+	for _, c := range criteria {
+		completed[c.Index] = c.Completed
+	}
+	return func(s *artifact.State) {
+		for i, c := range s.DoneCriteria {
+			if done, ok := completed[c.Index]; ok {
+				s.DoneCriteria[i].Completed = done
+			}
+		}
+	}
+}
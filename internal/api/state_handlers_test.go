@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/google/uuid"
+)
+
+func TestDecisionState(t *testing.T) {
+	_, repo, prior, router := newTestHandlers(t)
+	ctx := context.Background()
+	token := newTestSession(t, repo, "alice")
+
+	do := func(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s: expected status %d, got %d: %s", method, path, http.StatusOK, rec.Code, rec.Body.String())
+		}
+		return rec
+	}
+	getState := func(t *testing.T, id string) (DecisionStateResponse, *artifact.State) {
+		t.Helper()
+		var resp DecisionStateResponse
+		if err := json.NewDecoder(do(t, http.MethodGet, "/api/decisions/"+id+"/state", "").Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		state, err := artifact.ParseState(resp.State)
+		if err != nil {
+			t.Fatalf("ParseState() error = %v", err)
+		}
+		return resp, state
+	}
+
+	var verdict VerdictResponse
+	if err := json.NewDecoder(do(t, http.MethodPost, "/api/verdict", `{"input": "Go or Rust?"}`).Body).Decode(&verdict); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	t.Run("generated with the decision", func(t *testing.T) {
+		resp, state := getState(t, verdict.DecisionID)
+		if len(resp.History) != 1 || state.Version != 1 || state.CurrentPhase != 1 || state.CriteriaMet {
+			t.Errorf("expected the first snapshot, got %+v", resp)
+		}
+		if string(resp.State) != string(verdict.State) {
+			t.Errorf("state = %s, want the verdict's %s", resp.State, verdict.State)
+		}
+	})
+
+	t.Run("versioned on progress", func(t *testing.T) {
+		history, err := repo.GetHistoryByDecisionID(ctx, uuid.MustParse(verdict.DecisionID))
+		if err != nil {
+			t.Fatalf("GetHistoryByDecisionID() error = %v", err)
+		}
+		do(t, http.MethodPut, "/api/history/"+history.ID.String()+"/criteria",
+			`{"done_criteria": [{"index": 0, "text": "Parser ported", "completed": true}]}`)
+
+		resp, state := getState(t, verdict.DecisionID)
+		if len(resp.History) != 2 || resp.History[0].Version != 1 || resp.History[1].Version != 2 {
+			t.Fatalf("expected versions 1 and 2, got %+v", resp.History)
+		}
+		if state.Version != 2 || !state.CriteriaMet || !state.DoneCriteria[0].Completed {
+			t.Errorf("expected the criterion met in version 2, got %+v", state)
+		}
+	})
+
+	t.Run("decision without snapshots", func(t *testing.T) {
+		legacy := &storage.Decision{Input: prior.Input, Verdict: prior.Verdict, IsFinal: true}
+		todo := &storage.Todo{Content: "# Todo\n\n## Phases\n\n### Phase 1: Port\n- [x] Parser\n- [ ] Lexer\n\n## Done Criteria\n- Ported\n"}
+		if err := repo.SaveArtifacts(ctx, legacy, todo); err != nil {
+			t.Fatalf("SaveArtifacts() error = %v", err)
+		}
+
+		resp, state := getState(t, legacy.ID.String())
+		if len(resp.History) != 1 || state.Version != 1 || len(state.Phases) != 1 || !state.Phases[0].Tasks[0].Done {
+			t.Errorf("expected a snapshot rebuilt from todo.md, got %+v", state)
+		}
+		if stored, _ := repo.ListStateSnapshots(ctx, legacy.ID); len(stored) != 0 {
+			t.Errorf("reading the state stored %d snapshots", len(stored))
+		}
+
+		// The first update keeps the rebuilt version
+		if _, err := recordState(ctx, repo, legacy.ID, withDoneCriteria([]storage.DoneCriterion{{Index: 0, Completed: true}})); err != nil {
+			t.Fatalf("recordState() error = %v", err)
+		}
+		if stored, _ := repo.ListStateSnapshots(ctx, legacy.ID); len(stored) != 2 || stored[1].Version != 2 {
+			t.Errorf("expected versions 1 and 2 stored, got %d snapshots", len(stored))
+		}
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/decisions/"+uuid.New().String()+"/state", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown decision: expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
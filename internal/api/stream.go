@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/pipeline"
+)
+
+// SSE event names sent by VerdictStreamHandler
+const (
+	sseEventProgress = "progress" // A pipeline stage started, streamed output, completed, failed or was skipped
+	sseEventResult   = "result"   // Final VerdictResponse (verdict or clarification questions)
+	sseEventError    = "error"    // Terminal ErrorResponse
+)
+
+// sseWriter writes Server-Sent Events to a flushable response
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// newSSEWriter prepares the response for streaming. It returns false if the
+// underlying ResponseWriter cannot flush.
+func newSSEWriter(w http.ResponseWriter) (*sseWriter, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &sseWriter{w: w, flusher: flusher}, true
+}
+
+// send writes a single named event with a JSON payload and flushes it
+func (s *sseWriter) send(event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// progress sends a progress event for the given stage
+func (s *sseWriter) progress(stage pipeline.Stage, status string, data any) {
+	s.send(sseEventProgress, pipeline.Event{Stage: stage, Status: status, Data: data})
+}
+
+// fail sends a failed progress event followed by the terminal error event
+func (s *sseWriter) fail(stage pipeline.Stage, resp ErrorResponse) {
+	s.send(sseEventProgress, pipeline.Event{Stage: stage, Status: pipeline.StatusFailed, Error: resp.Error})
+	s.send(sseEventError, resp)
+}
+
+// VerdictStreamHandler handles POST /api/verdict/stream requests.
+// It runs the same flow as VerdictHandler but reports each stage as a
+// Server-Sent Event carrying the stage's partial output, so the ruling can be
+// shown before the execution plan is ready. The stream ends with a single
+// "result" or "error" event.
+func (h *Handlers) VerdictStreamHandler(w http.ResponseWriter, r *http.Request) {
+	req, ok := decodeVerdictRequest(w, r)
+	if !ok {
+		return
+	}
+
+	stream, ok := newSSEWriter(w)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Streaming not supported", "")
+		return
+	}
+
+	// An earlier decision on the same question ends the stream at once
+	if resp := h.findDuplicate(r.Context(), GetUserFromContext(r), req); resp != nil {
+		stream.send(sseEventResult, resp)
+		return
+	}
+
+	// Check if clarification is needed
+	if h.shouldClarify(req) {
+		stream.progress(pipeline.StageClarification, pipeline.StatusStarted, nil)
+		if resp := h.checkClarification(r.Context(), req); resp != nil {
+			stream.progress(pipeline.StageClarification, pipeline.StatusCompleted, resp)
+			stream.send(sseEventResult, resp)
+			return
+		}
+		stream.progress(pipeline.StageClarification, pipeline.StatusCompleted, nil)
+	} else {
+		stream.progress(pipeline.StageClarification, pipeline.StatusSkipped, nil)
+	}
+
+	// Execute pipeline, forwarding its progress events
+	result, err := h.pipeline.ExecuteWithProgress(r.Context(), h.enrichedInput(req), func(e pipeline.Event) {
+		stream.send(sseEventProgress, e)
+	})
+	if err != nil {
+		_, resp := pipelineErrorResponse(err)
+		stream.send(sseEventError, resp)
+		return
+	}
+
+	// Generate artifacts
+	stream.progress(pipeline.StageArtifacts, pipeline.StatusStarted, nil)
+	artifacts, err := h.generator.Generate(result)
+	if err != nil {
+		stream.fail(pipeline.StageArtifacts, ErrorResponse{Error: "Failed to generate artifacts", Code: ErrCodeVerdictFailed, Details: err.Error()})
+		return
+	}
+	stream.progress(pipeline.StageArtifacts, pipeline.StatusCompleted, map[string]any{
+		"decision_id": artifacts.ID.String(),
+		"decision":    json.RawMessage(artifacts.DecisionJSON),
+		"todo":        string(artifacts.TodoMD),
+	})
+
+	// Save to database
+	stream.progress(pipeline.StagePersistence, pipeline.StatusStarted, nil)
+	historyID, err := h.saveArtifacts(r.Context(), GetUserFromContext(r), result, artifacts, nil)
+	if err != nil {
+		stream.fail(pipeline.StagePersistence, ErrorResponse{Error: "Failed to save artifacts", Code: ErrCodeInternalError, Details: err.Error()})
+		return
+	}
+	stream.progress(pipeline.StagePersistence, pipeline.StatusCompleted, map[string]string{
+		"decision_id": artifacts.ID.String(),
+		"history_id":  historyID,
+	})
+
+	stream.send(sseEventResult, buildVerdictResponse(result, artifacts, historyID))
+}
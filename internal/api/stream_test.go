@@ -0,0 +1,205 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/pipeline"
+)
+
+// sseEvent is a parsed Server-Sent Event
+type sseEvent struct {
+	name string
+	data string
+}
+
+// parseSSE splits a response body into events
+func parseSSE(t *testing.T, body string) []sseEvent {
+	t.Helper()
+
+	var events []sseEvent
+	var current sseEvent
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			current.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			current.data = strings.TrimPrefix(line, "data: ")
+		case line == "":
+			if current.name != "" {
+				events = append(events, current)
+			}
+			current = sseEvent{}
+		}
+	}
+	return events
+}
+
+// newVerdictLLMClient returns a mock client producing a valid verdict and plan,
+// or failing the verdict stage with verdictErr
+func newVerdictLLMClient(verdictErr error) *mockLLMClient {
+	return &mockLLMClient{
+		completeJSONFunc: func(ctx context.Context, prompt string, result any) error {
+			switch v := result.(type) {
+			case *agent.VerdictOutput:
+				if verdictErr != nil {
+					return verdictErr
+				}
+				v.Ruling = "Use Go"
+				v.Rationale = "Go is great for this project"
+			case *agent.ExecutionOutput:
+				v.MVPScope = []string{"Basic implementation"}
+				v.Phases = []agent.Phase{
+					{Name: "Phase 1", Tasks: []string{"Task 1"}},
+				}
+				v.DoneCriteria = []string{"All tests pass"}
+			}
+			return nil
+		},
+	}
+}
+
+func newStreamTestHandlers(verdictErr error) *Handlers {
+	llmClient := newVerdictLLMClient(verdictErr)
+	p := pipeline.NewPipeline(agent.NewVerdictAgent(llmClient), agent.NewExecutionAgent(llmClient), 10*time.Minute)
+	return NewHandlers(p, artifact.NewGenerator(), newMockRepository())
+}
+
+func TestVerdictStreamHandler_Success(t *testing.T) {
+	handlers := newStreamTestHandlers(nil)
+
+	body := `{"input": "Should I use Go or Python for this project?"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/verdict/stream", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handlers.VerdictStreamHandler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", ct)
+	}
+
+	events := parseSSE(t, rec.Body.String())
+	if len(events) == 0 {
+		t.Fatal("expected events, got none")
+	}
+
+	// Collect progress in order and check the verdict arrives before the execution plan
+	var stages []string
+	verdictSeen := false
+	for _, e := range events[:len(events)-1] {
+		if e.name != sseEventProgress {
+			t.Fatalf("expected progress event, got %q", e.name)
+		}
+		var ev pipeline.Event
+		if err := json.Unmarshal([]byte(e.data), &ev); err != nil {
+			t.Fatalf("failed to decode event: %v", err)
+		}
+		stages = append(stages, string(ev.Stage)+":"+ev.Status)
+		if ev.Stage == pipeline.StageVerdict && ev.Status == pipeline.StatusCompleted {
+			if !strings.Contains(e.data, "Use Go") {
+				t.Errorf("expected verdict event to carry the ruling, got %s", e.data)
+			}
+			verdictSeen = true
+		}
+		if ev.Stage == pipeline.StageExecution && ev.Status == pipeline.StatusStarted && !verdictSeen {
+			t.Error("execution started before verdict was reported")
+		}
+	}
+
+	want := []string{
+		"clarification:skipped",
+		"search:skipped",
+		"verdict:started",
+		"verdict:completed",
+		"execution:started",
+		"execution:completed",
+		"artifacts:started",
+		"artifacts:completed",
+		"persistence:started",
+		"persistence:completed",
+	}
+	if strings.Join(stages, ",") != strings.Join(want, ",") {
+		t.Errorf("unexpected stage sequence:\n got %v\nwant %v", stages, want)
+	}
+
+	last := events[len(events)-1]
+	if last.name != sseEventResult {
+		t.Fatalf("expected final result event, got %q", last.name)
+	}
+	var resp VerdictResponse
+	if err := json.Unmarshal([]byte(last.data), &resp); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if resp.Status != "verdict" || resp.DecisionID == "" || resp.Todo == "" {
+		t.Errorf("unexpected result: %+v", resp)
+	}
+}
+
+func TestVerdictStreamHandler_PipelineError(t *testing.T) {
+	handlers := newStreamTestHandlers(context.DeadlineExceeded)
+
+	body := `{"input": "Should I use Go or Python?"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/verdict/stream", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handlers.VerdictStreamHandler(rec, req)
+
+	events := parseSSE(t, rec.Body.String())
+	if len(events) == 0 {
+		t.Fatal("expected events, got none")
+	}
+
+	last := events[len(events)-1]
+	if last.name != sseEventError {
+		t.Fatalf("expected final error event, got %q", last.name)
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal([]byte(last.data), &resp); err != nil {
+		t.Fatalf("failed to decode error: %v", err)
+	}
+	if resp.Code != ErrCodeVerdictFailed {
+		t.Errorf("expected code %s, got %s", ErrCodeVerdictFailed, resp.Code)
+	}
+}
+
+func TestVerdictStreamHandler_EmptyInput(t *testing.T) {
+	handlers := NewHandlers(nil, nil, newMockRepository())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/verdict/stream", strings.NewReader(`{"input": ""}`))
+	rec := httptest.NewRecorder()
+
+	handlers.VerdictStreamHandler(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestVerdictHandler_AcceptEventStream(t *testing.T) {
+	handlers := newStreamTestHandlers(nil)
+
+	body := `{"input": "Should I use Go or Python?"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/verdict", strings.NewReader(body))
+	req.Header.Set("Accept", "text/event-stream")
+	rec := httptest.NewRecorder()
+
+	handlers.VerdictHandler(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected Content-Type text/event-stream, got %q", ct)
+	}
+}
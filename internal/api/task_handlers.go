@@ -0,0 +1,317 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// TaskResponse represents a tracked task of a decision's execution plan
+type TaskResponse struct {
+	ID          string `json:"id"`
+	DecisionID  string `json:"decision_id"`
+	PhaseIndex  int    `json:"phase_index"` // 0-based; the phase numbered PhaseIndex+1 in todo.md
+	Position    int    `json:"position"`    // 0-based position within the phase
+	Text        string `json:"text"`
+	Status      string `json:"status"`
+	Notes       string `json:"notes"`
+	CreatedAt   string `json:"created_at"`
+	UpdatedAt   string `json:"updated_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+}
+
+// TaskListResponse represents the response for GET /api/decisions/{id}/tasks
+type TaskListResponse struct {
+	DecisionID string         `json:"decision_id"`
+	Tasks      []TaskResponse `json:"tasks"`
+}
+
+// UpdateTaskRequest represents the request body for
+// PATCH /api/decisions/{id}/tasks/{taskId}; omitted fields are unchanged
+type UpdateTaskRequest struct {
+	Status *string `json:"status,omitempty"` // "todo", "in_progress", "done" or "blocked"
+	Notes  *string `json:"notes,omitempty"`
+}
+
+// UpdateTaskResponse represents the response for
+// PATCH /api/decisions/{id}/tasks/{taskId}
+type UpdateTaskResponse struct {
+	Task  TaskResponse    `json:"task"`
+	State json.RawMessage `json:"state"` // The state.json version recording the update
+	Todo  string          `json:"todo"`  // todo.md regenerated from that state
+}
+
+// ListTasksHandler handles GET /api/decisions/{id}/tasks requests. Tasks not
+// yet tracked are listed from the latest state snapshot, without storing them.
+func (h *Handlers) ListTasksHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid decision ID", "Must be a valid UUID")
+		return
+	}
+
+	ctx := r.Context()
+	decision, err := h.repository.GetDecision(ctx, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision")
+		return
+	}
+	// The progress of a signed-in user's decision is private to that user
+	if decision.UserID != nil {
+		if err := checkOwner(GetUserFromContext(r), *decision.UserID); err != nil {
+			writeStorageError(w, err, "Task", "retrieve tasks")
+			return
+		}
+	}
+
+	tasks, err := listTasks(ctx, h.repository, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve tasks")
+		return
+	}
+
+	resp := TaskListResponse{DecisionID: id.String(), Tasks: make([]TaskResponse, len(tasks))}
+	for i, t := range tasks {
+		resp.Tasks[i] = taskToResponse(t)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// UpdateTaskHandler handles PATCH /api/decisions/{id}/tasks/{taskId}
+// requests. The update is recorded as a new state.json version, from which
+// todo.md is regenerated.
+func (h *Handlers) UpdateTaskHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid decision ID", "Must be a valid UUID")
+		return
+	}
+	taskID, err := uuid.Parse(chi.URLParam(r, "taskId"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidID, "Invalid task ID", "Must be a valid UUID")
+		return
+	}
+
+	var req UpdateTaskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInternalError, "Invalid JSON body", err.Error())
+		return
+	}
+	if req.Status != nil && !storage.TaskStatus(*req.Status).IsValid() {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidStatus, "Invalid task status",
+			"Must be one of todo, in_progress, done or blocked")
+		return
+	}
+
+	user := GetUserFromContext(r)
+	if h.userRepo != nil && user == nil {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Authentication required", "")
+		return
+	}
+
+	ctx := r.Context()
+	decision, err := h.repository.GetDecision(ctx, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve decision")
+		return
+	}
+	// Progress on a signed-in user's decision is tracked only by that user
+	if decision.UserID != nil {
+		if err := checkOwner(user, *decision.UserID); err != nil {
+			writeStorageError(w, err, "Task", "update task")
+			return
+		}
+	}
+
+	tasks, err := ensureTasks(ctx, h.repository, id)
+	if err != nil {
+		writeStorageError(w, err, "Decision", "retrieve tasks")
+		return
+	}
+	var task *storage.Task
+	for _, t := range tasks {
+		if t.ID == taskID {
+			task = t
+		}
+	}
+	if task == nil {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "Task not found", "")
+		return
+	}
+
+	if req.Status != nil {
+		task.Status = storage.TaskStatus(*req.Status)
+	}
+	if req.Notes != nil {
+		task.Notes = *req.Notes
+	}
+	if err := h.repository.UpdateTask(ctx, task); err != nil {
+		writeStorageError(w, err, "Task", "update task")
+		return
+	}
+
+	// Only this task is applied, so that concurrent updates of other tasks
+	// recorded in the meantime are kept
+	snapshot, err := recordState(ctx, h.repository, id, withTasks([]*storage.Task{task}))
+	if err != nil {
+		writeStorageError(w, err, "Decision", "record decision state")
+		return
+	}
+	state, err := artifact.ParseState(snapshot.State)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to read decision state", err.Error())
+		return
+	}
+	todoMD, _, err := renderDecision(h.renderers(), artifact.FormatTodo, decision, state)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternalError, "Failed to regenerate todo.md", err.Error())
+		return
+	}
+
+	// The stored todo.md is covered by the content hash and stays as
+	// generated; the history entry shows the regenerated one
+	if h.userRepo != nil {
+		if history, err := h.userRepo.GetHistoryByDecisionID(ctx, id); err == nil {
+			history.Todo = string(todoMD)
+			_ = h.userRepo.UpdateHistory(ctx, history)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, UpdateTaskResponse{
+		Task:  taskToResponse(task),
+		State: snapshot.State,
+		Todo:  string(todoMD),
+	})
+}
+
+// taskToResponse converts a stored task to its API representation
+func taskToResponse(t *storage.Task) TaskResponse {
+	resp := TaskResponse{
+		ID:         t.ID.String(),
+		DecisionID: t.DecisionID.String(),
+		PhaseIndex: t.PhaseIndex,
+		Position:   t.Position,
+		Text:       t.Text,
+		Status:     string(t.Status),
+		Notes:      t.Notes,
+		CreatedAt:  t.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:  t.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+	if t.CompletedAt != nil {
+		resp.CompletedAt = t.CompletedAt.Format("2006-01-02T15:04:05Z")
+	}
+	return resp
+}
+
+// listTasks returns the tracked tasks of a decision or, before any are
+// tracked, those of its latest state snapshot, unsaved
+func listTasks(ctx context.Context, repo storage.Repository, decisionID uuid.UUID) ([]*storage.Task, error) {
+	tasks, err := repo.ListTasks(ctx, decisionID)
+	if err != nil || len(tasks) > 0 {
+		return tasks, err
+	}
+	snapshots, err := stateSnapshots(ctx, repo, decisionID)
+	if err != nil {
+		return nil, err
+	}
+	return tasksFromState(snapshots[len(snapshots)-1])
+}
+
+// ensureTasks returns the tracked tasks of a decision. Tasks are stored the
+// first time they are needed, from the latest state snapshot, keeping the
+// task IDs of that snapshot.
+func ensureTasks(ctx context.Context, repo storage.Repository, decisionID uuid.UUID) ([]*storage.Task, error) {
+	for attempt := 1; ; attempt++ {
+		tasks, err := repo.ListTasks(ctx, decisionID)
+		if err != nil || len(tasks) > 0 {
+			return tasks, err
+		}
+
+		snapshots, err := stateSnapshots(ctx, repo, decisionID)
+		if err != nil {
+			return nil, err
+		}
+		latest := snapshots[len(snapshots)-1]
+		tasks, err = tasksFromState(latest)
+		if err != nil || len(tasks) == 0 {
+			return tasks, err
+		}
+
+		// Keep the task IDs of a rebuilt first version
+		if latest.ID == uuid.Nil {
+			err = repo.CreateStateSnapshot(ctx, latest)
+		}
+		if err == nil {
+			err = repo.CreateTasks(ctx, tasks)
+		}
+		if errors.Is(err, storage.ErrConflict) && attempt < maxStateAttempts {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return tasks, nil
+	}
+}
+
+// tasksFromState returns the tasks of the phases of a state snapshot, as of
+// the time it was taken
+func tasksFromState(snapshot *storage.StateSnapshot) ([]*storage.Task, error) {
+	state, err := artifact.ParseState(snapshot.State)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []*storage.Task{}
+	for i, phase := range state.Phases {
+		for j, ts := range phase.Tasks {
+			task := &storage.Task{
+				DecisionID: snapshot.DecisionID,
+				PhaseIndex: i,
+				Position:   j,
+				Text:       ts.Text,
+				Status:     storage.TaskStatus(ts.Status),
+				Notes:      ts.Notes,
+				CreatedAt:  snapshot.CreatedAt,
+				UpdatedAt:  snapshot.CreatedAt,
+			}
+			if id, err := uuid.Parse(ts.ID); err == nil {
+				task.ID = id
+			} else {
+				task.ID = artifact.TaskID(snapshot.DecisionID, i, j)
+			}
+			if !task.Status.IsValid() {
+				task.Status = storage.TaskTodo
+				if ts.Done {
+					task.Status = storage.TaskDone
+				}
+			}
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+// withTasks returns a state update that sets the ID, status, notes and
+// completion of the state's tasks to those of tasks, matched by phase and
+// position
+func withTasks(tasks []*storage.Task) func(*artifact.State) {
+	return func(s *artifact.State) {
+		for _, t := range tasks {
+			if t.PhaseIndex >= len(s.Phases) || t.Position >= len(s.Phases[t.PhaseIndex].Tasks) {
+				continue
+			}
+			ts := &s.Phases[t.PhaseIndex].Tasks[t.Position]
+			ts.ID = t.ID.String()
+			ts.Status = string(t.Status)
+			ts.Notes = t.Notes
+			ts.Done = t.Status == storage.TaskDone
+		}
+	}
+}
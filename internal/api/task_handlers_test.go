@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/1psychoQAQ/verdict-agent/internal/artifact"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/google/uuid"
+)
+
+func TestTaskHandlers(t *testing.T) {
+	_, repo, prior, router := newTestHandlers(t)
+	ctx := context.Background()
+	alice, bob := newTestSession(t, repo, "alice"), newTestSession(t, repo, "bob")
+
+	do := func(token, method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do(alice, http.MethodPost, "/api/verdict", `{"input": "Go or Rust?"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("verdict: expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var verdict VerdictResponse
+	if err := json.NewDecoder(rec.Body).Decode(&verdict); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	tasksPath := "/api/decisions/" + verdict.DecisionID + "/tasks"
+
+	rec = do(alice, http.MethodGet, tasksPath, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list tasks: expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	var list TaskListResponse
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(list.Tasks) != 1 || list.Tasks[0].Text != "Task 1" || list.Tasks[0].Status != "todo" {
+		t.Fatalf("expected the plan's one open task, got %+v", list.Tasks)
+	}
+	task := list.Tasks[0]
+	state, err := artifact.ParseState(verdict.State)
+	if err != nil {
+		t.Fatalf("ParseState() error = %v", err)
+	}
+	if task.ID != state.Phases[0].Tasks[0].ID {
+		t.Errorf("task ID = %s, want the state's %s", task.ID, state.Phases[0].Tasks[0].ID)
+	}
+	// Listing stores nothing
+	if stored, err := repo.ListTasks(ctx, uuid.MustParse(verdict.DecisionID)); err != nil || len(stored) != 0 {
+		t.Errorf("listing stored tasks %v, %v", stored, err)
+	}
+	for _, token := range []string{"", bob} {
+		if rec := do(token, http.MethodGet, tasksPath, ""); rec.Code != http.StatusForbidden {
+			t.Errorf("listing another user's tasks: expected status %d, got %d", http.StatusForbidden, rec.Code)
+		}
+	}
+
+	patch := func(t *testing.T, body string) UpdateTaskResponse {
+		t.Helper()
+		rec := do(alice, http.MethodPatch, tasksPath+"/"+task.ID, body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		var resp UpdateTaskResponse
+		if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return resp
+	}
+
+	t.Run("in progress with notes", func(t *testing.T) {
+		resp := patch(t, `{"status": "in_progress", "notes": "Started on the lexer"}`)
+		if resp.Task.Status != "in_progress" || resp.Task.Notes != "Started on the lexer" || resp.Task.CompletedAt != "" {
+			t.Errorf("task = %+v", resp.Task)
+		}
+		if !strings.Contains(resp.Todo, "- [ ] Task 1 (in progress)\n  - Note: Started on the lexer\n") {
+			t.Errorf("todo.md not regenerated:\n%s", resp.Todo)
+		}
+		state, err := artifact.ParseState(resp.State)
+		if err != nil {
+			t.Fatalf("ParseState() error = %v", err)
+		}
+		if state.Version != 2 || state.Phases[0].Tasks[0].Status != "in_progress" || state.Phases[0].Tasks[0].Done {
+			t.Errorf("expected version 2 with the task in progress, got %+v", state)
+		}
+
+		history, err := repo.GetHistoryByDecisionID(ctx, uuid.MustParse(verdict.DecisionID))
+		if err != nil {
+			t.Fatalf("GetHistoryByDecisionID() error = %v", err)
+		}
+		if history.Todo != resp.Todo {
+			t.Errorf("history todo = %q, want the regenerated one", history.Todo)
+		}
+		// The stored todo.md stays as generated
+		if todo, _ := repo.GetTodoByDecisionID(ctx, uuid.MustParse(verdict.DecisionID)); todo.Content != verdict.Todo {
+			t.Errorf("stored todo.md changed to %q", todo.Content)
+		}
+	})
+
+	t.Run("done", func(t *testing.T) {
+		resp := patch(t, `{"status": "done"}`)
+		if resp.Task.Status != "done" || resp.Task.Notes != "Started on the lexer" || resp.Task.CompletedAt == "" {
+			t.Errorf("task = %+v", resp.Task)
+		}
+		if !strings.Contains(resp.Todo, "- [x] Task 1\n") {
+			t.Errorf("expected the task checked:\n%s", resp.Todo)
+		}
+		state, err := artifact.ParseState(resp.State)
+		if err != nil {
+			t.Fatalf("ParseState() error = %v", err)
+		}
+		if state.Version != 3 || !state.Phases[0].Complete || state.CurrentPhase != 0 {
+			t.Errorf("expected every phase complete in version 3, got %+v", state)
+		}
+	})
+
+	t.Run("decision without tasks", func(t *testing.T) {
+		rec := do("", http.MethodGet, "/api/decisions/"+prior.ID.String()+"/tasks", "")
+		var list TaskListResponse
+		if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if rec.Code != http.StatusOK || len(list.Tasks) != 0 {
+			t.Errorf("expected no tasks, got %d: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	for _, tt := range []struct {
+		name, token, path, body string
+		status                  int
+	}{
+		{"invalid status", alice, tasksPath + "/" + task.ID, `{"status": "finished"}`, http.StatusBadRequest},
+		{"unauthenticated", "", tasksPath + "/" + task.ID, `{"status": "todo"}`, http.StatusUnauthorized},
+		{"another user's decision", bob, tasksPath + "/" + task.ID, `{"status": "todo"}`, http.StatusForbidden},
+		{"unknown task", alice, tasksPath + "/" + uuid.NewString(), `{"status": "todo"}`, http.StatusNotFound},
+		{"unknown decision", alice, "/api/decisions/" + uuid.NewString() + "/tasks/" + task.ID, `{"status": "todo"}`, http.StatusNotFound},
+		{"invalid task ID", alice, tasksPath + "/not-a-uuid", `{"status": "todo"}`, http.StatusBadRequest},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := do(tt.token, http.MethodPatch, tt.path, tt.body); rec.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+// interleavingRepository runs before the first time a task is updated, as a
+// concurrent request would
+type interleavingRepository struct {
+	*storage.MemoryRepository
+	before func()
+}
+
+func (r *interleavingRepository) UpdateTask(ctx context.Context, t *storage.Task) error {
+	if before := r.before; before != nil {
+		r.before = nil
+		before()
+	}
+	return r.MemoryRepository.UpdateTask(ctx, t)
+}
+
+func TestUpdateTaskHandler_ConcurrentUpdates(t *testing.T) {
+	repo := &interleavingRepository{}
+	_, _, decision, router := newTestHandlers(t, func(cfg *RouterConfig) {
+		repo.MemoryRepository = cfg.Repository.(*storage.MemoryRepository)
+		cfg.Repository = repo
+	})
+
+	// A decision with a plan of two tasks
+	execution := &agent.ExecutionOutput{Phases: []agent.Phase{{Name: "Build", Tasks: []string{"Lexer", "Parser"}}}, DoneCriteria: []string{"Parses"}}
+	state, err := artifact.NewState(execution, decision.ID, decision.CreatedAt).JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	if err := repo.CreateStateSnapshot(context.Background(), &storage.StateSnapshot{DecisionID: decision.ID, Version: 1, State: state, CreatedAt: decision.CreatedAt}); err != nil {
+		t.Fatalf("CreateStateSnapshot() error = %v", err)
+	}
+	tasks, err := ensureTasks(context.Background(), repo, decision.ID)
+	if err != nil || len(tasks) != 2 {
+		t.Fatalf("ensureTasks() = %v, %v", tasks, err)
+	}
+	token := newTestSession(t, repo.MemoryRepository, "alice")
+
+	patch := func(task *storage.Task) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPatch, "/api/decisions/"+decision.ID.String()+"/tasks/"+task.ID.String(), strings.NewReader(`{"status": "done"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+	}
+
+	// The second task is completed while the first one's update is under way
+	repo.before = func() { patch(tasks[1]) }
+	patch(tasks[0])
+
+	snapshots, err := repo.ListStateSnapshots(context.Background(), decision.ID)
+	if err != nil {
+		t.Fatalf("ListStateSnapshots() error = %v", err)
+	}
+	latest, err := artifact.ParseState(snapshots[len(snapshots)-1].State)
+	if err != nil {
+		t.Fatalf("ParseState() error = %v", err)
+	}
+	for _, task := range latest.Phases[0].Tasks {
+		if !task.Done {
+			t.Errorf("task %q not done in the latest state: %+v", task.Text, latest.Phases[0].Tasks)
+		}
+	}
+}
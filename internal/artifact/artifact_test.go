@@ -132,7 +132,7 @@ func TestGenerateDecisionJSON(t *testing.T) {
 	id := uuid.New()
 	createdAt := time.Date(2025, 12, 22, 3, 28, 32, 0, time.UTC)
 
-	jsonBytes, err := generateDecisionJSON(input, verdict, id, createdAt)
+	jsonBytes, err := generateDecisionJSON(input, verdict, decisionMetadata{}, id, createdAt)
 	if err != nil {
 		t.Fatalf("generateDecisionJSON() error = %v", err)
 	}
@@ -162,8 +162,12 @@ func TestGenerateDecisionJSON(t *testing.T) {
 	if len(decision.Verdict.Rejected) != 1 {
 		t.Errorf("Rejected count = %v, want 1", len(decision.Verdict.Rejected))
 	}
-	if len(decision.Verdict.Ranking) != 2 {
-		t.Errorf("Ranking count = %v, want 2", len(decision.Verdict.Ranking))
+	ranking, ok := decision.Verdict.Ranking.([]interface{})
+	if !ok {
+		t.Fatalf("Ranking type = %T, want []interface{}", decision.Verdict.Ranking)
+	}
+	if len(ranking) != 2 {
+		t.Errorf("Ranking count = %v, want 2", len(ranking))
 	}
 	if !decision.IsFinal {
 		t.Error("IsFinal = false, want true")
@@ -185,7 +189,7 @@ func TestGenerateDecisionJSON_EmptyRejected(t *testing.T) {
 	id := uuid.New()
 	createdAt := time.Now()
 
-	jsonBytes, err := generateDecisionJSON("test input", verdict, id, createdAt)
+	jsonBytes, err := generateDecisionJSON("test input", verdict, decisionMetadata{}, id, createdAt)
 	if err != nil {
 		t.Fatalf("generateDecisionJSON() error = %v", err)
 	}
@@ -204,6 +208,56 @@ func TestGenerateDecisionJSON_EmptyRejected(t *testing.T) {
 	}
 }
 
+func TestGenerateDecisionJSON_QualityFlags(t *testing.T) {
+	verdict := &agent.VerdictOutput{
+		Ruling:    "Maybe use Go",
+		Rationale: "Fast builds",
+		Rejected:  []agent.RejectedOption{},
+	}
+	flags := []agent.QualityFlag{{Code: agent.QualityHedging, Field: "ruling", Phrase: "Maybe", Message: "ruling uses hedging language"}}
+
+	jsonBytes, err := generateDecisionJSON("test input", verdict, decisionMetadata{QualityFlags: flags}, uuid.New(), time.Now())
+	if err != nil {
+		t.Fatalf("generateDecisionJSON() error = %v", err)
+	}
+
+	var decision Decision
+	if err := json.Unmarshal(jsonBytes, &decision); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if len(decision.QualityFlags) != 1 || decision.QualityFlags[0].Code != agent.QualityHedging {
+		t.Errorf("QualityFlags = %+v, want one hedging flag", decision.QualityFlags)
+	}
+
+	// Clean verdicts omit the field
+	jsonBytes, _ = generateDecisionJSON("test input", verdict, decisionMetadata{}, uuid.New(), time.Now())
+	if strings.Contains(string(jsonBytes), "quality_flags") {
+		t.Error("expected quality_flags to be omitted when empty")
+	}
+}
+
+func TestGenerateDecisionJSON_Models(t *testing.T) {
+	verdict := &agent.VerdictOutput{
+		Ruling:    "Use Go",
+		Rationale: "Fast builds",
+		Rejected:  []agent.RejectedOption{},
+	}
+	models := map[string]string{"verdict": "anthropic/claude-sonnet-4-20250514", "execution": "local/llama3.1"}
+
+	jsonBytes, err := generateDecisionJSON("test input", verdict, decisionMetadata{Models: models}, uuid.New(), time.Now())
+	if err != nil {
+		t.Fatalf("generateDecisionJSON() error = %v", err)
+	}
+
+	var decision Decision
+	if err := json.Unmarshal(jsonBytes, &decision); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if decision.Models["verdict"] != models["verdict"] || decision.Models["execution"] != models["execution"] {
+		t.Errorf("Models = %v, want %v", decision.Models, models)
+	}
+}
+
 func TestGenerateTodoMD(t *testing.T) {
 	verdict := &agent.VerdictOutput{
 		Ruling:    "Build API service",
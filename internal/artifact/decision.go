@@ -15,6 +15,17 @@ type Decision struct {
 	Input     string          `json:"input"`
 	Verdict   DecisionVerdict `json:"verdict"`
 	IsFinal   bool            `json:"is_final"`
+
+	// Violations of the "judge, not consultant" contract found by the hedging linter
+	QualityFlags []agent.QualityFlag `json:"quality_flags,omitempty"`
+	// Provider and model that served each pipeline stage, e.g. "verdict": "anthropic/claude-sonnet-4-20250514"
+	Models map[string]string `json:"models,omitempty"`
+}
+
+// decisionMetadata is the run information recorded alongside the verdict
+type decisionMetadata struct {
+	QualityFlags []agent.QualityFlag
+	Models       map[string]string
 }
 
 // DecisionVerdict represents the verdict portion of the decision
@@ -32,7 +43,7 @@ type RejectedOption struct {
 }
 
 // generateDecisionJSON creates the decision.json artifact
-func generateDecisionJSON(input string, verdict *agent.VerdictOutput, id uuid.UUID, createdAt time.Time) ([]byte, error) {
+func generateDecisionJSON(input string, verdict *agent.VerdictOutput, meta decisionMetadata, id uuid.UUID, createdAt time.Time) ([]byte, error) {
 	decision := Decision{
 		ID:        id.String(),
 		CreatedAt: createdAt.UTC().Format(time.RFC3339),
@@ -43,7 +54,9 @@ func generateDecisionJSON(input string, verdict *agent.VerdictOutput, id uuid.UU
 			Rejected:  convertRejectedOptions(verdict.Rejected),
 			Ranking:   verdict.Ranking,
 		},
-		IsFinal: true,
+		IsFinal:      true,
+		QualityFlags: meta.QualityFlags,
+		Models:       meta.Models,
 	}
 
 	// Marshal with indentation for readability
@@ -1,6 +1,7 @@
 package artifact
 
 import (
+	"crypto/ed25519"
 	"fmt"
 	"time"
 
@@ -9,23 +10,59 @@ import (
 )
 
 // Generator generates decision and todo artifacts from pipeline results
-type Generator struct{}
+type Generator struct {
+	signingKey ed25519.PrivateKey // Signs content hashes if set
+	renderers  *Registry          // Renders todo.md
+}
 
-// Artifacts contains the generated decision.json and todo.md artifacts
+// Artifacts contains the generated decision.json, todo.md and state.json
+// artifacts
 type Artifacts struct {
 	DecisionJSON []byte
 	TodoMD       []byte
+	StateJSON    []byte // First snapshot; later versions are stored as progress is made
 	ID           uuid.UUID
 	CreatedAt    time.Time
+
+	// Tamper evidence: the ContentHash of both artifacts and, if the
+	// generator has a signing key, its Ed25519 signature
+	ContentHash string
+	Signature   string
 }
 
 // NewGenerator creates a new artifact generator
 func NewGenerator() *Generator {
-	return &Generator{}
+	return &Generator{renderers: NewRegistry()}
+}
+
+// SetRenderers makes the generator render todo.md with the todo format of a
+// registry, e.g. one with templates loaded from a directory
+func (g *Generator) SetRenderers(r *Registry) {
+	g.renderers = r
+}
+
+// Renderers returns the registry the generator renders with
+func (g *Generator) Renderers() *Registry {
+	return g.renderers
+}
+
+// SetSigningKey makes the generator sign the content hash of every artifact
+// pair it generates. A nil key disables signing.
+func (g *Generator) SetSigningKey(key ed25519.PrivateKey) {
+	g.signingKey = key
+}
+
+// PublicKey returns the public key that verifies the generator's signatures,
+// or nil if it does not sign
+func (g *Generator) PublicKey() ed25519.PublicKey {
+	if g.signingKey == nil {
+		return nil
+	}
+	return g.signingKey.Public().(ed25519.PublicKey)
 }
 
-// Generate creates both decision.json and todo.md artifacts from pipeline result
-// Generation is atomic - both artifacts are created or an error is returned
+// Generate creates the decision.json, todo.md and state.json artifacts from pipeline result
+// Generation is atomic - all artifacts are created or an error is returned
 func (g *Generator) Generate(result *pipeline.PipelineResult) (*Artifacts, error) {
 	if result == nil {
 		return nil, fmt.Errorf("pipeline result cannot be nil")
@@ -42,22 +79,49 @@ func (g *Generator) Generate(result *pipeline.PipelineResult) (*Artifacts, error
 	createdAt := time.Now()
 
 	// Generate decision.json
-	decisionJSON, err := generateDecisionJSON(result.Input, result.Verdict, id, createdAt)
+	decisionJSON, err := generateDecisionJSON(result.Input, result.Verdict, decisionMetadata{
+		QualityFlags: result.QualityFlags,
+		Models:       result.Providers,
+	}, id, createdAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate decision.json: %w", err)
 	}
 
-	// Generate todo.md
-	todoMD, err := generateTodoMD(result.Verdict, result.Execution, id, createdAt)
+	// Generate todo.md from the decision and its first state
+	state := NewState(result.Execution, id, createdAt)
+	data, err := NewRenderData(decisionJSON, state)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate todo.md: %w", err)
 	}
+	todoMD, _, err := g.renderers.Render(FormatTodo, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate todo.md: %w", err)
+	}
+
+	// Generate state.json
+	stateJSON, err := state.JSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate state.json: %w", err)
+	}
+
+	// The hash covers the immutable artifacts only, not the changing state
+	contentHash, err := ContentHash(decisionJSON, todoMD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash artifacts: %w", err)
+	}
+	var signature string
+	if g.signingKey != nil {
+		signature = Sign(g.signingKey, contentHash)
+	}
 
-	// Both artifacts generated successfully - atomic operation complete
+	// All artifacts generated successfully - atomic operation complete
 	return &Artifacts{
 		DecisionJSON: decisionJSON,
 		TodoMD:       todoMD,
+		StateJSON:    stateJSON,
 		ID:           id,
 		CreatedAt:    createdAt,
+		ContentHash:  contentHash,
+		Signature:    signature,
 	}, nil
 }
@@ -0,0 +1,85 @@
+package artifact
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// ContentHash returns the hex SHA-256 of the canonical JSON document
+// {"decision": <decision.json>, "todo": <todo.md>}.
+//
+// decision.json is canonicalized - object keys sorted, insignificant
+// whitespace removed, numbers re-encoded - so that the hash survives storage
+// that reformats JSON (e.g. Postgres JSONB) but not a change to any value.
+func ContentHash(decisionJSON, todoMD []byte) (string, error) {
+	var decision any
+	if err := json.Unmarshal(decisionJSON, &decision); err != nil {
+		return "", fmt.Errorf("failed to parse decision.json: %w", err)
+	}
+
+	canonical, err := canonicalJSON(map[string]any{
+		"decision": decision,
+		"todo":     string(todoMD),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// canonicalJSON encodes v with sorted object keys, no insignificant whitespace
+// and no HTML escaping
+func canonicalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode canonical JSON: %w", err)
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// ParseSigningKey parses a base64 Ed25519 private key, given either as its
+// 32-byte seed or as the 64-byte key. A 64-byte key must end with the public
+// key of its seed, or nothing it signs would verify.
+func ParseSigningKey(s string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("signing key is not valid base64: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		key := ed25519.NewKeyFromSeed(raw[:ed25519.SeedSize])
+		if !bytes.Equal(key, raw) {
+			return nil, fmt.Errorf("signing key does not contain the public key of its seed")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("signing key must be %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+	}
+}
+
+// Sign returns the base64 Ed25519 signature of a content hash, as returned
+// by ContentHash
+func Sign(key ed25519.PrivateKey, contentHash string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(contentHash)))
+}
+
+// VerifySignature reports whether signature is a valid Sign signature of
+// contentHash by the private key of pub
+func VerifySignature(pub ed25519.PublicKey, contentHash, signature string) bool {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(pub, []byte(contentHash), sig)
+}
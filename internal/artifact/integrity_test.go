@@ -0,0 +1,129 @@
+package artifact
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/1psychoQAQ/verdict-agent/internal/pipeline"
+)
+
+func TestContentHash(t *testing.T) {
+	decision := []byte(`{
+  "id": "1",
+  "verdict": {"ruling": "Use Go", "rejected": []},
+  "score": 1.50,
+  "note": "<b> & </b>"
+}`)
+	todo := []byte("# Todo\n- [ ] Ship it")
+
+	want, err := ContentHash(decision, todo)
+	if err != nil {
+		t.Fatalf("ContentHash() error = %v", err)
+	}
+	if len(want) != 64 {
+		t.Errorf("ContentHash() = %q, want 64 hex digits", want)
+	}
+
+	tests := []struct {
+		name     string
+		decision string
+		todo     string
+		same     bool
+	}{
+		{"reformatted and reordered", `{"score":1.5,"note":"<b> & </b>","verdict":{"rejected":[],"ruling":"Use Go"},"id":"1"}`, string(todo), true},
+		{"changed value", `{"id":"1","verdict":{"ruling":"Use Rust","rejected":[]},"score":1.5,"note":"<b> & </b>"}`, string(todo), false},
+		{"changed todo", string(decision), "# Todo\n- [x] Ship it", false},
+		{"todo moved into decision", `{"id":"1","verdict":{"ruling":"Use Go","rejected":[]},"score":1.5,"note":"<b> & </b>","todo":"# Todo\n- [ ] Ship it"}`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ContentHash([]byte(tt.decision), []byte(tt.todo))
+			if err != nil {
+				t.Fatalf("ContentHash() error = %v", err)
+			}
+			if (got == want) != tt.same {
+				t.Errorf("ContentHash() = %s, original %s, want same = %v", got, want, tt.same)
+			}
+		})
+	}
+
+	if _, err := ContentHash([]byte(`{"id":`), todo); err == nil {
+		t.Error("ContentHash() of invalid JSON: expected an error")
+	}
+}
+
+func TestParseSigningKey(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	key := ed25519.NewKeyFromSeed(seed)
+
+	for _, s := range []string{base64.StdEncoding.EncodeToString(seed), base64.StdEncoding.EncodeToString(key)} {
+		got, err := ParseSigningKey(s)
+		if err != nil {
+			t.Fatalf("ParseSigningKey() error = %v", err)
+		}
+		if !got.Equal(key) {
+			t.Errorf("ParseSigningKey(%q) returned a different key", s)
+		}
+	}
+
+	// A 64-byte key whose public half belongs to another seed
+	mismatched := append(append([]byte{}, seed...), ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey)...)
+
+	for _, s := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short")), base64.StdEncoding.EncodeToString(mismatched)} {
+		if _, err := ParseSigningKey(s); err == nil {
+			t.Errorf("ParseSigningKey(%q): expected an error", s)
+		}
+	}
+}
+
+func TestGenerator_Signing(t *testing.T) {
+	result := &pipeline.PipelineResult{
+		Input:   "test",
+		Verdict: &agent.VerdictOutput{Ruling: "test ruling", Rationale: "test rationale"},
+		Execution: &agent.ExecutionOutput{
+			Phases:       []agent.Phase{{Name: "phase", Tasks: []string{"task"}}},
+			DoneCriteria: []string{"done"},
+		},
+	}
+
+	g := NewGenerator()
+	if g.PublicKey() != nil {
+		t.Error("PublicKey() of a generator without a signing key should be nil")
+	}
+	unsigned, err := g.Generate(result)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	hash, err := ContentHash(unsigned.DecisionJSON, unsigned.TodoMD)
+	if err != nil {
+		t.Fatalf("ContentHash() error = %v", err)
+	}
+	if unsigned.ContentHash != hash || unsigned.Signature != "" {
+		t.Errorf("unsigned artifacts: ContentHash = %q, Signature = %q, want %q and none", unsigned.ContentHash, unsigned.Signature, hash)
+	}
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	g.SetSigningKey(key)
+	signed, err := g.Generate(result)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !VerifySignature(g.PublicKey(), signed.ContentHash, signed.Signature) {
+		t.Error("Signature does not verify with the generator's public key")
+	}
+	if VerifySignature(g.PublicKey(), strings.Repeat("0", 64), signed.Signature) {
+		t.Error("Signature verifies for a different content hash")
+	}
+	if VerifySignature(g.PublicKey(), signed.ContentHash, "not base64!") {
+		t.Error("malformed signature verifies")
+	}
+}
@@ -0,0 +1,377 @@
+package artifact
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+)
+
+// Built-in formats
+const (
+	FormatTodo     = "todo"     // The todo.md artifact
+	FormatDecision = "decision" // Markdown summary of the verdict
+	FormatJSON     = "json"     // The render data itself, as JSON
+)
+
+// ErrUnknownFormat is returned when rendering a format that is not registered
+var ErrUnknownFormat = errors.New("unknown format")
+
+// formatName matches the names formats can be registered under from a
+// template directory; they appear in URLs
+var formatName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// RenderData is the data model templates are executed against. Field names
+// are the template names, e.g. {{.Ruling}} or {{range .Phases}}; the JSON
+// format renders it with the snake_case keys of its tags.
+type RenderData struct {
+	Ruling    string           `json:"ruling"`
+	Rationale string           `json:"rationale"`
+	Rejected  []RejectedOption `json:"rejected"` // .Option and .Reason of each
+
+	// Execution plan, with the progress of its latest state
+	MVPScope     []string          `json:"mvp_scope"`
+	Phases       []RenderPhase     `json:"phases"`
+	Criteria     []RenderCriterion `json:"criteria"`
+	CurrentPhase int               `json:"current_phase"` // Number of the first phase with open tasks; 0 once all are done
+	CriteriaMet  bool              `json:"criteria_met"`
+
+	Metadata RenderMetadata `json:"metadata"`
+}
+
+// RenderPhase is a phase of the execution plan
+type RenderPhase struct {
+	Number   int          `json:"number"` // From 1
+	Name     string       `json:"name"`
+	Tasks    []RenderTask `json:"tasks"`
+	Complete bool         `json:"complete"`
+}
+
+// RenderTask is a task of a phase
+type RenderTask struct {
+	ID     string `json:"id,omitempty"`
+	Text   string `json:"text"`
+	Done   bool   `json:"done"`
+	Status string `json:"status,omitempty"` // "todo", "in_progress", "done" or "blocked"; empty until tracked
+	Label  string `json:"label,omitempty"`  // Status worth showing after the task: "in progress" or "blocked"
+	Notes  string `json:"notes,omitempty"`  // Task notes on one line
+}
+
+// RenderCriterion is a done criterion
+type RenderCriterion struct {
+	Index     int    `json:"index"` // From 0
+	Text      string `json:"text"`
+	Completed bool   `json:"completed"`
+}
+
+// RenderMetadata describes the decision itself
+type RenderMetadata struct {
+	ID           string              `json:"id"`
+	CreatedAt    string              `json:"created_at"` // RFC 3339, UTC
+	Input        string              `json:"input"`      // The question decided
+	IsFinal      bool                `json:"is_final"`
+	QualityFlags []agent.QualityFlag `json:"quality_flags,omitempty"`
+	Models       map[string]string   `json:"models,omitempty"`
+	ContentHash  string              `json:"content_hash,omitempty"`
+	Signature    string              `json:"signature,omitempty"`
+	StateVersion int                 `json:"state_version"` // Version of the state the progress is from
+}
+
+// NewRenderData returns the render data of a decision from its decision.json,
+// which may also be a bare verdict, and its latest state
+func NewRenderData(decisionJSON []byte, s *State) (*RenderData, error) {
+	var doc Decision
+	if err := json.Unmarshal(decisionJSON, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse decision: %w", err)
+	}
+	if doc.Verdict.Ruling == "" {
+		_ = json.Unmarshal(decisionJSON, &doc.Verdict)
+	}
+
+	data := &RenderData{
+		Ruling:    doc.Verdict.Ruling,
+		Rationale: doc.Verdict.Rationale,
+		Rejected:  doc.Verdict.Rejected,
+		Metadata: RenderMetadata{
+			ID:           doc.ID,
+			CreatedAt:    doc.CreatedAt,
+			Input:        doc.Input,
+			IsFinal:      doc.IsFinal,
+			QualityFlags: doc.QualityFlags,
+			Models:       doc.Models,
+		},
+	}
+	if data.Rejected == nil {
+		data.Rejected = []RejectedOption{}
+	}
+	if s != nil {
+		data.setState(s)
+	}
+	return data, nil
+}
+
+// setState fills the execution plan and its progress from a state snapshot
+func (d *RenderData) setState(s *State) {
+	d.MVPScope = s.MVPScope
+	d.Phases = make([]RenderPhase, len(s.Phases))
+	for i, phase := range s.Phases {
+		d.Phases[i] = RenderPhase{
+			Number:   phase.Number,
+			Name:     phase.Name,
+			Tasks:    make([]RenderTask, len(phase.Tasks)),
+			Complete: phase.Complete,
+		}
+		for j, task := range phase.Tasks {
+			d.Phases[i].Tasks[j] = RenderTask{
+				ID:     task.ID,
+				Text:   task.Text,
+				Done:   task.Done,
+				Status: task.Status,
+				Notes:  strings.Join(strings.Fields(task.Notes), " "),
+			}
+			if task.Status != "" && task.Status != "todo" && task.Status != "done" {
+				d.Phases[i].Tasks[j].Label = strings.ReplaceAll(task.Status, "_", " ")
+			}
+		}
+	}
+	d.Criteria = make([]RenderCriterion, len(s.DoneCriteria))
+	for i, c := range s.DoneCriteria {
+		d.Criteria[i] = RenderCriterion{Index: c.Index, Text: c.Text, Completed: c.Completed}
+	}
+	d.CurrentPhase = s.CurrentPhase
+	d.CriteriaMet = s.CriteriaMet
+	d.Metadata.StateVersion = s.Version
+}
+
+// Renderer renders decisions in one format
+type Renderer interface {
+	ContentType() string
+	Render(w io.Writer, data *RenderData) error
+}
+
+// executor is a parsed text/template or html/template
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// TemplateRenderer renders a Go template, parsed once
+type TemplateRenderer struct {
+	tmpl        executor
+	contentType string
+}
+
+// markupTypes are the content types browsers render as markup, able to run
+// scripts, so templates for them escape their data
+var markupTypes = []string{"text/html", "application/xhtml+xml", "image/svg+xml", "text/xml", "application/xml"}
+
+// NewTemplateRenderer parses a template for the given content type. HTML,
+// XHTML, SVG and XML content is rendered with html/template, so data is
+// escaped; anything else with text/template.
+func NewTemplateRenderer(name, text, contentType string) (*TemplateRenderer, error) {
+	var tmpl executor
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if slices.Contains(markupTypes, mediaType) {
+		tmpl, err = htmltemplate.New(name).Parse(text)
+	} else {
+		tmpl, err = template.New(name).Parse(text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", name, err)
+	}
+	return &TemplateRenderer{tmpl: tmpl, contentType: contentType}, nil
+}
+
+// ContentType returns the content type of the rendered output
+func (t *TemplateRenderer) ContentType() string {
+	return t.contentType
+}
+
+// Render executes the template
+func (t *TemplateRenderer) Render(w io.Writer, data *RenderData) error {
+	if err := t.tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
+
+// jsonRenderer renders the render data as JSON
+type jsonRenderer struct{}
+
+func (jsonRenderer) ContentType() string {
+	return "application/json"
+}
+
+func (jsonRenderer) Render(w io.Writer, data *RenderData) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(data)
+}
+
+const decisionTemplate = `# Decision: {{.Ruling}}
+
+Decided: {{.Metadata.CreatedAt}}
+Decision ID: {{.Metadata.ID}}
+{{with .Metadata.Input}}
+## Question
+
+{{.}}
+{{end}}
+## Ruling
+
+{{.Ruling}}
+
+## Rationale
+
+{{.Rationale}}
+{{with .Rejected}}
+## Rejected Options
+{{range .}}
+- **{{.Option}}**: {{.Reason}}
+{{- end}}
+{{end}}`
+
+// Built-in renderers, shared by every registry
+var (
+	todoRenderer     = mustTemplateRenderer(FormatTodo, todoTemplate, "text/markdown; charset=utf-8")
+	decisionRenderer = mustTemplateRenderer(FormatDecision, decisionTemplate, "text/markdown; charset=utf-8")
+)
+
+func mustTemplateRenderer(name, text, contentType string) *TemplateRenderer {
+	r, err := NewTemplateRenderer(name, text, contentType)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Registry holds the renderers of each format. It is safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	renderers map[string]Renderer
+}
+
+// NewRegistry returns a registry with the built-in formats: todo, decision
+// and json
+func NewRegistry() *Registry {
+	return &Registry{renderers: map[string]Renderer{
+		FormatTodo:     todoRenderer,
+		FormatDecision: decisionRenderer,
+		FormatJSON:     jsonRenderer{},
+	}}
+}
+
+// Register adds a format, replacing any renderer it already has
+func (r *Registry) Register(format string, renderer Renderer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.renderers[format] = renderer
+}
+
+// Lookup returns the renderer of a format
+func (r *Registry) Lookup(format string) (Renderer, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	renderer, ok := r.renderers[format]
+	return renderer, ok
+}
+
+// Formats returns the registered formats, sorted
+func (r *Registry) Formats() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	formats := make([]string, 0, len(r.renderers))
+	for format := range r.renderers {
+		formats = append(formats, format)
+	}
+	slices.Sort(formats)
+	return formats
+}
+
+// Render renders data in a format, returning the output and its content type.
+// Nothing is returned if rendering fails part way.
+func (r *Registry) Render(format string, data *RenderData) ([]byte, string, error) {
+	renderer, ok := r.Lookup(format)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	var buf bytes.Buffer
+	if err := renderer.Render(&buf, data); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), renderer.ContentType(), nil
+}
+
+// LoadDir registers a template for each <format>.<ext>.tmpl file in dir, e.g.
+// todo.md.tmpl overrides the todo format and report.html.tmpl adds a report
+// format. The extension sets the content type; templates of HTML, XHTML,
+// SVG and XML escape their data. Other files are ignored. It returns the formats loaded, and loads
+// none if any template is invalid.
+func (r *Registry) LoadDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template directory: %w", err)
+	}
+
+	loaded := map[string]Renderer{}
+	var formats []string
+	for _, entry := range entries {
+		base, ok := strings.CutSuffix(entry.Name(), ".tmpl")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		format, _, _ := strings.Cut(base, ".")
+		ext := strings.TrimPrefix(filepath.Ext(base), ".")
+		if !formatName.MatchString(format) {
+			return nil, fmt.Errorf("invalid format name %q in template %s", format, entry.Name())
+		}
+		if _, exists := loaded[format]; exists {
+			return nil, fmt.Errorf("more than one template for format %q", format)
+		}
+
+		text, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", entry.Name(), err)
+		}
+		renderer, err := NewTemplateRenderer(entry.Name(), string(text), contentTypeFor(ext))
+		if err != nil {
+			return nil, err
+		}
+		loaded[format] = renderer
+		formats = append(formats, format)
+	}
+
+	for format, renderer := range loaded {
+		r.Register(format, renderer)
+	}
+	return formats, nil
+}
+
+// contentTypeFor returns the content type of a template's output from the
+// extension in its file name
+func contentTypeFor(ext string) string {
+	switch ext {
+	case "md", "markdown":
+		return "text/markdown; charset=utf-8"
+	case "xhtml":
+		return "application/xhtml+xml"
+	case "":
+		return "text/plain; charset=utf-8"
+	}
+	if contentType := mime.TypeByExtension("." + ext); contentType != "" {
+		return contentType
+	}
+	return "text/plain; charset=utf-8"
+}
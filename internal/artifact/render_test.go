@@ -0,0 +1,204 @@
+package artifact
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/1psychoQAQ/verdict-agent/internal/pipeline"
+	"github.com/google/uuid"
+)
+
+// renderTodo renders the built-in todo format of a decision at state s
+func renderTodo(verdict *agent.VerdictOutput, s *State, id uuid.UUID, createdAt time.Time) ([]byte, error) {
+	decisionJSON, err := generateDecisionJSON("", verdict, decisionMetadata{}, id, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	data, err := NewRenderData(decisionJSON, s)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := NewRegistry().Render(FormatTodo, data)
+	return out, err
+}
+
+// generateTodoMD renders the todo.md generated with a decision
+func generateTodoMD(verdict *agent.VerdictOutput, execution *agent.ExecutionOutput, id uuid.UUID, createdAt time.Time) ([]byte, error) {
+	return renderTodo(verdict, NewState(execution, id, createdAt), id, createdAt)
+}
+
+func testRenderData(t *testing.T) *RenderData {
+	t.Helper()
+	id := uuid.New()
+	createdAt := time.Date(2025, 12, 22, 3, 28, 32, 0, time.UTC)
+	decisionJSON, err := generateDecisionJSON("Go or Rust?", &agent.VerdictOutput{
+		Ruling:    "Use Go",
+		Rationale: "The team knows it",
+		Rejected:  []agent.RejectedOption{{Option: "Rust", Reason: "Slower to ship"}},
+	}, decisionMetadata{Models: map[string]string{"verdict": "openai/gpt-4o"}}, id, createdAt)
+	if err != nil {
+		t.Fatalf("generateDecisionJSON() error = %v", err)
+	}
+	data, err := NewRenderData(decisionJSON, NewState(testExecution(), id, createdAt))
+	if err != nil {
+		t.Fatalf("NewRenderData() error = %v", err)
+	}
+	return data
+}
+
+func TestNewRenderData(t *testing.T) {
+	data := testRenderData(t)
+	if data.Ruling != "Use Go" || data.Rationale != "The team knows it" || len(data.Rejected) != 1 {
+		t.Errorf("verdict = %q, %q, %v", data.Ruling, data.Rationale, data.Rejected)
+	}
+	if len(data.Phases) != 2 || data.Phases[0].Number != 1 || data.Phases[0].Tasks[0].ID == "" || len(data.Criteria) != 2 {
+		t.Errorf("plan = %+v, criteria %+v", data.Phases, data.Criteria)
+	}
+	if data.Metadata.Input != "Go or Rust?" || data.Metadata.CreatedAt != "2025-12-22T03:28:32Z" ||
+		data.Metadata.Models["verdict"] != "openai/gpt-4o" || data.Metadata.StateVersion != 1 {
+		t.Errorf("metadata = %+v", data.Metadata)
+	}
+
+	// Decisions stored as a bare verdict
+	bare, err := NewRenderData([]byte(`{"ruling": "Use Go", "rationale": "Fast"}`), nil)
+	if err != nil || bare.Ruling != "Use Go" || bare.Rationale != "Fast" {
+		t.Errorf("NewRenderData(bare verdict) = %+v, %v", bare, err)
+	}
+	if _, err := NewRenderData([]byte("not json"), nil); err == nil {
+		t.Error("NewRenderData(invalid) succeeded")
+	}
+}
+
+func TestRegistry_Render(t *testing.T) {
+	registry := NewRegistry()
+	data := testRenderData(t)
+
+	if got := registry.Formats(); !slices.Equal(got, []string{"decision", "json", "todo"}) {
+		t.Errorf("Formats() = %v", got)
+	}
+
+	out, contentType, err := registry.Render(FormatDecision, data)
+	if err != nil {
+		t.Fatalf("Render(decision) error = %v", err)
+	}
+	for _, want := range []string{"# Decision: Use Go\n", "## Question\n\nGo or Rust?\n", "## Rationale\n\nThe team knows it\n", "- **Rust**: Slower to ship\n"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("decision missing %q:\n%s", want, out)
+		}
+	}
+	if contentType != "text/markdown; charset=utf-8" {
+		t.Errorf("content type = %q", contentType)
+	}
+
+	out, contentType, err = registry.Render(FormatJSON, data)
+	if err != nil {
+		t.Fatalf("Render(json) error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(out, &decoded); err != nil || contentType != "application/json" {
+		t.Fatalf("json output %s (%s): %v", out, contentType, err)
+	}
+	for _, key := range []string{"ruling", "rationale", "rejected", "phases", "criteria", "metadata"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("json output missing %q", key)
+		}
+	}
+
+	if _, _, err := registry.Render("pdf", data); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("Render(pdf) error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestRegistry_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"todo.md.tmpl":     "Plan for {{.Ruling}}\n",
+		"report.html.tmpl": "<h1>{{.Ruling}}</h1><p>{{.Metadata.Input}}</p>",
+		"README.md":        "not a template",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := NewRegistry()
+	formats, err := registry.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	slices.Sort(formats)
+	if !slices.Equal(formats, []string{"report", "todo"}) {
+		t.Errorf("LoadDir() = %v", formats)
+	}
+
+	data := testRenderData(t)
+	data.Ruling = "Use <Go>"
+	if out, _, _ := registry.Render(FormatTodo, data); string(out) != "Plan for Use <Go>\n" {
+		t.Errorf("overridden todo = %q", out)
+	}
+	out, contentType, err := registry.Render("report", data)
+	if err != nil || string(out) != "<h1>Use &lt;Go&gt;</h1><p>Go or Rust?</p>" || contentType != "text/html; charset=utf-8" {
+		t.Errorf("report = %q (%s), %v", out, contentType, err)
+	}
+
+	// The generator renders todo.md with the overridden template
+	generator := NewGenerator()
+	generator.SetRenderers(registry)
+	artifacts, err := generator.Generate(&pipeline.PipelineResult{
+		Input:     "Go or Rust?",
+		Verdict:   &agent.VerdictOutput{Ruling: "Use Go"},
+		Execution: testExecution(),
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if !strings.HasPrefix(string(artifacts.TodoMD), "Plan for ") {
+		t.Errorf("TodoMD = %q", artifacts.TodoMD)
+	}
+
+	t.Run("markup templates escape data", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, name := range []string{"page.xhtml.tmpl", "badge.svg.tmpl", "feed.xml.tmpl"} {
+			os.WriteFile(filepath.Join(dir, name), []byte("<p>{{.Ruling}}</p>"), 0o644)
+		}
+		registry := NewRegistry()
+		if _, err := registry.LoadDir(dir); err != nil {
+			t.Fatalf("LoadDir() error = %v", err)
+		}
+		data := testRenderData(t)
+		data.Ruling = "<script>alert(1)</script>"
+		for _, format := range []string{"page", "badge", "feed"} {
+			out, contentType, err := registry.Render(format, data)
+			if err != nil || strings.Contains(string(out), "<script>") {
+				t.Errorf("%s (%s) = %q, %v", format, contentType, out, err)
+			}
+		}
+	})
+
+	t.Run("invalid template loads nothing", func(t *testing.T) {
+		bad := t.TempDir()
+		os.WriteFile(filepath.Join(bad, "summary.txt.tmpl"), []byte("{{.Ruling}}"), 0o644)
+		os.WriteFile(filepath.Join(bad, "todo.md.tmpl"), []byte("{{.Ruling"), 0o644)
+		registry := NewRegistry()
+		if _, err := registry.LoadDir(bad); err == nil {
+			t.Fatal("LoadDir() succeeded")
+		}
+		if _, ok := registry.Lookup("summary"); ok {
+			t.Error("summary registered despite the invalid todo template")
+		}
+	})
+
+	t.Run("invalid directory", func(t *testing.T) {
+		if _, err := NewRegistry().LoadDir(filepath.Join(dir, "missing")); err == nil {
+			t.Error("LoadDir() succeeded")
+		}
+	})
+}
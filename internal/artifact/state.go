@@ -0,0 +1,198 @@
+package artifact
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/google/uuid"
+)
+
+// State represents the state.json artifact: which phase execution of a
+// decision has reached and whether its done criteria are met. Unlike
+// decision.json and todo.md it changes with progress, so each change is a
+// new snapshot with the next version.
+type State struct {
+	DecisionID   string           `json:"decision_id"`
+	Version      int              `json:"version"` // 1 for the snapshot generated with the decision
+	UpdatedAt    string           `json:"updated_at"`
+	CurrentPhase int              `json:"current_phase"` // Number of the first phase with open tasks; 0 once all are done
+	MVPScope     []string         `json:"mvp_scope,omitempty"`
+	Phases       []PhaseState     `json:"phases"`
+	DoneCriteria []CriterionState `json:"done_criteria"`
+	CriteriaMet  bool             `json:"criteria_met"` // There are done criteria and all are completed
+}
+
+// PhaseState represents the progress of an execution phase
+type PhaseState struct {
+	Number   int         `json:"number"`
+	Name     string      `json:"name"`
+	Tasks    []TaskState `json:"tasks"`
+	Complete bool        `json:"complete"`
+}
+
+// TaskState represents the progress of a task of a phase
+type TaskState struct {
+	ID   string `json:"id,omitempty"` // Stable ID of the tracked task
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+
+	// Tracked status: "todo", "in_progress", "done" or "blocked"; empty
+	// until the task is tracked
+	Status string `json:"status,omitempty"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+// CriterionState represents the completion of a done criterion
+type CriterionState struct {
+	Index     int    `json:"index"`
+	Text      string `json:"text"`
+	Completed bool   `json:"completed"`
+}
+
+// NewState returns the first snapshot of an execution plan, with nothing done
+func NewState(execution *agent.ExecutionOutput, id uuid.UUID, createdAt time.Time) *State {
+	s := &State{
+		DecisionID:   id.String(),
+		Version:      1,
+		UpdatedAt:    createdAt.UTC().Format(time.RFC3339),
+		MVPScope:     execution.MVPScope,
+		Phases:       make([]PhaseState, len(execution.Phases)),
+		DoneCriteria: make([]CriterionState, len(execution.DoneCriteria)),
+	}
+	for i, phase := range execution.Phases {
+		s.Phases[i] = PhaseState{Number: i + 1, Name: phase.Name, Tasks: make([]TaskState, len(phase.Tasks))}
+		for j, task := range phase.Tasks {
+			s.Phases[i].Tasks[j] = TaskState{ID: uuid.NewString(), Text: task}
+		}
+	}
+	for i, criterion := range execution.DoneCriteria {
+		s.DoneCriteria[i] = CriterionState{Index: i, Text: criterion}
+	}
+	s.evaluate()
+	return s
+}
+
+// TaskID returns the ID of a task of a decision whose snapshot did not record
+// one, from its phase and position, so that it is the same every time the
+// snapshot is read
+func TaskID(decisionID uuid.UUID, phaseIndex, position int) uuid.UUID {
+	return uuid.NewSHA1(decisionID, fmt.Appendf(nil, "%d.%d", phaseIndex, position))
+}
+
+// StateFromTodoMD returns the first snapshot of the execution plan in a
+// todo.md, for decisions stored before state.json was generated. Tasks
+// checked in the markdown are done, and task IDs are derived from the
+// decision ID, so rebuilding the snapshot again gives the same IDs.
+func StateFromTodoMD(todoMD []byte, id uuid.UUID, createdAt time.Time) *State {
+	s := &State{
+		DecisionID:   id.String(),
+		Version:      1,
+		UpdatedAt:    createdAt.UTC().Format(time.RFC3339),
+		Phases:       []PhaseState{},
+		DoneCriteria: []CriterionState{},
+	}
+
+	var section string
+	scanner := bufio.NewScanner(bytes.NewReader(todoMD))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "## "):
+			section = strings.TrimPrefix(line, "## ")
+		case section == "MVP Scope" && strings.HasPrefix(line, "- "):
+			s.MVPScope = append(s.MVPScope, strings.TrimPrefix(line, "- "))
+		case section == "Phases" && strings.HasPrefix(line, "### Phase "):
+			name := strings.TrimPrefix(line, "### Phase ")
+			if _, after, ok := strings.Cut(name, ": "); ok {
+				name = after
+			}
+			s.Phases = append(s.Phases, PhaseState{Number: len(s.Phases) + 1, Name: name, Tasks: []TaskState{}})
+		case section == "Phases" && len(s.Phases) > 0 && strings.HasPrefix(line, "- ["):
+			task, done := parseTodoTask(line)
+			phase := &s.Phases[len(s.Phases)-1]
+			taskID := TaskID(id, len(s.Phases)-1, len(phase.Tasks))
+			phase.Tasks = append(phase.Tasks, TaskState{ID: taskID.String(), Text: task, Done: done})
+		case section == "Done Criteria" && strings.HasPrefix(line, "- "):
+			s.DoneCriteria = append(s.DoneCriteria, CriterionState{Index: len(s.DoneCriteria), Text: strings.TrimPrefix(line, "- ")})
+		}
+	}
+	s.evaluate()
+	return s
+}
+
+// parseTodoTask returns the text of a markdown task line and whether it is
+// checked
+func parseTodoTask(line string) (string, bool) {
+	if len(line) < 6 || line[4] != ']' {
+		return strings.TrimPrefix(line, "- "), false
+	}
+	return strings.TrimSpace(line[5:]), line[3] == 'x' || line[3] == 'X'
+}
+
+// ParseState parses a state.json artifact
+func ParseState(data []byte) (*State, error) {
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse state.json: %w", err)
+	}
+	return &s, nil
+}
+
+// Next returns a copy of the snapshot as the next version, updated at the
+// given time. Change its tasks and criteria, then encode it with JSON.
+func (s *State) Next(at time.Time) *State {
+	next := *s
+	next.Version++
+	next.UpdatedAt = at.UTC().Format(time.RFC3339)
+	next.Phases = make([]PhaseState, len(s.Phases))
+	for i, phase := range s.Phases {
+		next.Phases[i] = phase
+		next.Phases[i].Tasks = slices.Clone(phase.Tasks)
+	}
+	next.MVPScope = slices.Clone(s.MVPScope)
+	next.DoneCriteria = slices.Clone(s.DoneCriteria)
+	return &next
+}
+
+// JSON recomputes the phase completion, current phase and criteria fields of
+// the snapshot from its tasks and criteria, and encodes it as state.json
+func (s *State) JSON() ([]byte, error) {
+	s.evaluate()
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal state.json: %w", err)
+	}
+	return data, nil
+}
+
+// evaluate recomputes the fields derived from task and criteria completion
+func (s *State) evaluate() {
+	s.CurrentPhase = 0
+	for i := range s.Phases {
+		phase := &s.Phases[i]
+		phase.Complete = true
+		for _, task := range phase.Tasks {
+			if !task.Done {
+				phase.Complete = false
+				break
+			}
+		}
+		if !phase.Complete && s.CurrentPhase == 0 {
+			s.CurrentPhase = phase.Number
+		}
+	}
+
+	s.CriteriaMet = len(s.DoneCriteria) > 0
+	for _, c := range s.DoneCriteria {
+		if !c.Completed {
+			s.CriteriaMet = false
+			break
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package artifact
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/google/uuid"
+)
+
+func testExecution() *agent.ExecutionOutput {
+	return &agent.ExecutionOutput{
+		MVPScope: []string{"Parser"},
+		Phases: []agent.Phase{
+			{Name: "Foundation", Tasks: []string{"Setup project", "Configure CI"}},
+			{Name: "Launch", Tasks: []string{"Deploy"}},
+		},
+		DoneCriteria: []string{"Parser handles all fixtures", "Deployed"},
+	}
+}
+
+func TestNewState(t *testing.T) {
+	id := uuid.New()
+	s := NewState(testExecution(), id, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	if s.DecisionID != id.String() || s.Version != 1 || s.UpdatedAt != "2026-01-02T03:04:05Z" {
+		t.Errorf("NewState() = %+v", s)
+	}
+	if len(s.Phases) != 2 || s.Phases[1].Number != 2 || s.Phases[1].Name != "Launch" || len(s.Phases[0].Tasks) != 2 {
+		t.Errorf("Phases = %+v", s.Phases)
+	}
+	if s.CurrentPhase != 1 || s.CriteriaMet || len(s.DoneCriteria) != 2 || s.DoneCriteria[1].Index != 1 {
+		t.Errorf("expected phase 1 with no criteria met, got %+v", s)
+	}
+}
+
+func TestState_Next(t *testing.T) {
+	first := NewState(testExecution(), uuid.New(), time.Now())
+
+	second := first.Next(time.Now())
+	second.Phases[0].Tasks[0].Done = true
+	second.Phases[0].Tasks[1].Done = true
+	second.DoneCriteria[0].Completed = true
+	if _, err := second.JSON(); err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	if second.Version != 2 || second.CurrentPhase != 2 || !second.Phases[0].Complete || second.CriteriaMet {
+		t.Errorf("expected version 2 in phase 2, got %+v", second)
+	}
+
+	// The earlier snapshot is unchanged
+	if first.Phases[0].Tasks[0].Done || first.DoneCriteria[0].Completed || first.CurrentPhase != 1 {
+		t.Errorf("Next() changed the earlier snapshot: %+v", first)
+	}
+
+	third := second.Next(time.Now())
+	third.Phases[1].Tasks[0].Done = true
+	third.DoneCriteria[1].Completed = true
+	data, err := third.JSON()
+	if err != nil {
+		t.Fatalf("JSON() error = %v", err)
+	}
+	parsed, err := ParseState(data)
+	if err != nil {
+		t.Fatalf("ParseState() error = %v", err)
+	}
+	if parsed.Version != 3 || parsed.CurrentPhase != 0 || !parsed.CriteriaMet {
+		t.Errorf("expected every phase complete and criteria met, got %+v", parsed)
+	}
+}
+
+func TestStateFromTodoMD(t *testing.T) {
+	id := uuid.New()
+	createdAt := time.Now()
+	execution := testExecution()
+	todoMD, err := generateTodoMD(&agent.VerdictOutput{Ruling: "Use Go"}, execution, id, createdAt)
+	if err != nil {
+		t.Fatalf("generateTodoMD() error = %v", err)
+	}
+
+	// Task IDs are compared separately
+	withoutTaskIDs := func(s *State) []byte {
+		t.Helper()
+		for i := range s.Phases {
+			for j := range s.Phases[i].Tasks {
+				if s.Phases[i].Tasks[j].ID == "" {
+					t.Errorf("task %d.%d has no ID", i, j)
+				}
+				s.Phases[i].Tasks[j].ID = ""
+			}
+		}
+		data, err := s.JSON()
+		if err != nil {
+			t.Fatalf("JSON() error = %v", err)
+		}
+		return data
+	}
+	want := withoutTaskIDs(NewState(execution, id, createdAt))
+	first, again := StateFromTodoMD(todoMD, id, createdAt), StateFromTodoMD(todoMD, id, createdAt)
+	if firstID, againID := first.Phases[1].Tasks[0].ID, again.Phases[1].Tasks[0].ID; firstID != againID || firstID != TaskID(id, 1, 0).String() {
+		t.Errorf("rebuilt task IDs %s and %s differ", firstID, againID)
+	}
+	if other := StateFromTodoMD(todoMD, uuid.New(), createdAt); other.Phases[1].Tasks[0].ID == first.Phases[1].Tasks[0].ID {
+		t.Error("task IDs of another decision are the same")
+	}
+	got := withoutTaskIDs(first)
+	if string(got) != string(want) {
+		t.Errorf("StateFromTodoMD() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Checked tasks are done
+	checked := StateFromTodoMD([]byte("## Phases\n\n### Phase 1: Only\n- [x] Done\n- [ ] Open\n"), id, createdAt)
+	if !checked.Phases[0].Tasks[0].Done || checked.Phases[0].Tasks[1].Done || checked.Phases[0].Tasks[1].Text != "Open" {
+		t.Errorf("checked tasks = %+v", checked.Phases[0].Tasks)
+	}
+
+	empty := StateFromTodoMD(nil, id, createdAt)
+	if len(empty.Phases) != 0 || empty.CurrentPhase != 0 || empty.CriteriaMet {
+		t.Errorf("StateFromTodoMD(nil) = %+v", empty)
+	}
+}
+
+func TestRenderTodoFromState(t *testing.T) {
+	id := uuid.New()
+	createdAt := time.Now()
+	execution := testExecution()
+	verdict := &agent.VerdictOutput{Ruling: "Use Go"}
+
+	// Nothing done regenerates the todo.md generated with the decision
+	want, err := generateTodoMD(verdict, execution, id, createdAt)
+	if err != nil {
+		t.Fatalf("generateTodoMD() error = %v", err)
+	}
+	got, err := renderTodo(verdict, NewState(execution, id, createdAt), id, createdAt)
+	if err != nil {
+		t.Fatalf("renderTodo() error = %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("renderTodo() =\n%s\nwant\n%s", got, want)
+	}
+
+	s := NewState(execution, id, createdAt).Next(createdAt)
+	s.Phases[0].Tasks[0] = TaskState{Text: "Setup project", Done: true, Status: "done"}
+	s.Phases[0].Tasks[1] = TaskState{Text: "Configure CI", Status: "in_progress", Notes: "Waiting on\n  runner access"}
+	s.Phases[1].Tasks[0] = TaskState{Text: "Deploy", Status: "blocked"}
+	got, err = renderTodo(verdict, s, id, createdAt)
+	if err != nil {
+		t.Fatalf("renderTodo() error = %v", err)
+	}
+	for _, line := range []string{
+		"- [x] Setup project\n",
+		"- [ ] Configure CI (in progress)\n  - Note: Waiting on runner access\n",
+		"- [ ] Deploy (blocked)\n",
+	} {
+		if !strings.Contains(string(got), line) {
+			t.Errorf("todo.md missing %q:\n%s", line, got)
+		}
+	}
+
+	// The regenerated todo.md parses back to the same progress
+	parsed := StateFromTodoMD(got, id, createdAt)
+	if !parsed.Phases[0].Tasks[0].Done || parsed.Phases[0].Tasks[1].Done || parsed.CurrentPhase != 1 {
+		t.Errorf("StateFromTodoMD() = %+v", parsed)
+	}
+}
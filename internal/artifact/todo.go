@@ -1,19 +1,11 @@
 package artifact
 
-import (
-	"bytes"
-	"fmt"
-	"text/template"
-	"time"
-
-	"github.com/1psychoQAQ/verdict-agent/internal/agent"
-	"github.com/google/uuid"
-)
-
+// todoTemplate is the built-in template of the todo format, which generates
+// the todo.md artifact
 const todoTemplate = `# Execution Plan: {{.Ruling}}
 
-Generated: {{.Timestamp}}
-Decision ID: {{.ID}}
+Generated: {{.Metadata.CreatedAt}}
+Decision ID: {{.Metadata.ID}}
 
 ## MVP Scope
 {{range .MVPScope -}}
@@ -23,61 +15,11 @@ Decision ID: {{.ID}}
 {{range .Phases}}
 ### Phase {{.Number}}: {{.Name}}
 {{range .Tasks -}}
-- [ ] {{.}}
-{{end}}
+- [{{if .Done}}x{{else}} {{end}}] {{.Text}}{{with .Label}} ({{.}}){{end}}
+{{with .Notes}}  - Note: {{.}}
+{{end}}{{end}}
 {{end -}}
 ## Done Criteria
-{{range .DoneCriteria -}}
-- {{.}}
+{{range .Criteria -}}
+- {{.Text}}
 {{end}}`
-
-// todoData represents the data structure for the todo template
-type todoData struct {
-	Ruling       string
-	Timestamp    string
-	ID           string
-	MVPScope     []string
-	Phases       []phaseData
-	DoneCriteria []string
-}
-
-// phaseData represents a phase for template rendering
-type phaseData struct {
-	Number int
-	Name   string
-	Tasks  []string
-}
-
-// generateTodoMD creates the todo.md artifact
-func generateTodoMD(verdict *agent.VerdictOutput, execution *agent.ExecutionOutput, id uuid.UUID, createdAt time.Time) ([]byte, error) {
-	// Prepare phase data with numbering
-	phases := make([]phaseData, len(execution.Phases))
-	for i, phase := range execution.Phases {
-		phases[i] = phaseData{
-			Number: i + 1,
-			Name:   phase.Name,
-			Tasks:  phase.Tasks,
-		}
-	}
-
-	data := todoData{
-		Ruling:       verdict.Ruling,
-		Timestamp:    createdAt.UTC().Format(time.RFC3339),
-		ID:           id.String(),
-		MVPScope:     execution.MVPScope,
-		Phases:       phases,
-		DoneCriteria: execution.DoneCriteria,
-	}
-
-	tmpl, err := template.New("todo").Parse(todoTemplate)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse template: %w", err)
-	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return nil, fmt.Errorf("failed to execute template: %w", err)
-	}
-
-	return buf.Bytes(), nil
-}
@@ -10,17 +10,50 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	DatabaseURL      string
-	OpenAIAPIKey     string
-	AnthropicAPIKey  string
-	GeminiAPIKey     string
-	LLMProvider      string
+	DatabaseURL     string
+	DBAutoMigrate   bool // Apply pending schema migrations on startup
+	OpenAIAPIKey    string
+	AnthropicAPIKey string
+	GeminiAPIKey    string
+	LocalAPIKey     string // Optional key for an OpenAI-compatible local server
+	LLMProvider     string
+	LLMModel        string // Overrides the provider's default model; required for "local"
+	// Per-provider API base URLs (empty uses the provider default)
+	OpenAIBaseURL    string
+	AnthropicBaseURL string
+	GeminiBaseURL    string
+	LocalBaseURL     string
+	// Fallback chain configuration
+	LLMFallbacks     string // Comma-separated providers tried after LLM_PROVIDER, e.g. "anthropic,local:llama3.1"
+	CircuitThreshold int    // Consecutive failures that open a provider's circuit
+	CircuitCooldown  int    // Seconds an open circuit skips its provider
+	// Per-stage "provider:model" overrides (empty uses LLM_PROVIDER and LLM_MODEL)
+	ClarificationLLM string
+	VerdictLLM       string
+	ExecutionLLM     string
+	LLMPrices        string // JSON price overrides in USD per million tokens
+	MaxRepairs       int    // Corrective turns when agent output fails validation
+	HedgingMode      string // off, flag or reask
+	HedgingPhrases   string // JSON per-language hedging phrase overrides
 	Port             int
 	// Search configuration
-	SearchProvider   string
-	TavilyAPIKey     string
-	GoogleSearchKey  string
-	SearchEnabled    bool
+	SearchProvider  string
+	TavilyAPIKey    string
+	GoogleSearchKey string
+	SearchEnabled   bool
+	// Job queue configuration
+	JobWorkers   int
+	JobQueueSize int
+	// Duplicate detection
+	DuplicateThreshold float64 // Input similarity (0-1] that returns an earlier decision; 0 disables
+	// Artifact signing
+	SigningKey string // Base64 Ed25519 seed that signs artifact content hashes; empty disables signing
+	// GitHub Issues export (disabled unless both token and repository are set)
+	GitHubToken      string
+	GitHubRepository string // "owner/name" execution plans are exported to
+	GitHubAPIURL     string // REST API root, for GitHub Enterprise Server
+	// Artifact rendering
+	TemplateDir string // Directory of <format>.<ext>.tmpl templates overriding or adding render formats; empty for the built-ins only
 }
 
 // Load reads configuration from environment variables
@@ -30,16 +63,46 @@ func Load() (*Config, error) {
 
 	cfg := &Config{
 		DatabaseURL:      getEnv("DATABASE_URL", ""),
+		DBAutoMigrate:    getEnvAsBool("DB_AUTO_MIGRATE", true),
 		OpenAIAPIKey:     getEnv("OPENAI_API_KEY", ""),
 		AnthropicAPIKey:  getEnv("ANTHROPIC_API_KEY", ""),
 		GeminiAPIKey:     getEnv("GEMINI_API_KEY", ""),
+		LocalAPIKey:      getEnv("LOCAL_API_KEY", ""),
 		LLMProvider:      getEnv("LLM_PROVIDER", "openai"),
+		LLMModel:         getEnv("LLM_MODEL", ""),
+		OpenAIBaseURL:    getEnv("OPENAI_BASE_URL", ""),
+		AnthropicBaseURL: getEnv("ANTHROPIC_BASE_URL", ""),
+		GeminiBaseURL:    getEnv("GEMINI_BASE_URL", ""),
+		LocalBaseURL:     getEnv("LOCAL_BASE_URL", "http://localhost:11434/v1"),
+		LLMFallbacks:     getEnv("LLM_FALLBACKS", ""),
+		CircuitThreshold: getEnvAsInt("LLM_CIRCUIT_THRESHOLD", 3),
+		CircuitCooldown:  getEnvAsInt("LLM_CIRCUIT_COOLDOWN", 30),
+		ClarificationLLM: getEnv("CLARIFICATION_LLM", ""),
+		VerdictLLM:       getEnv("VERDICT_LLM", ""),
+		ExecutionLLM:     getEnv("EXECUTION_LLM", ""),
+		LLMPrices:        getEnv("LLM_PRICES", ""),
+		MaxRepairs:       getEnvAsInt("AGENT_MAX_REPAIRS", 2),
+		HedgingMode:      getEnv("HEDGING_MODE", "flag"),
+		HedgingPhrases:   getEnv("HEDGING_PHRASES", ""),
 		Port:             getEnvAsInt("PORT", 8080),
 		// Search configuration
-		SearchProvider:   getEnv("SEARCH_PROVIDER", ""),
-		TavilyAPIKey:     getEnv("TAVILY_API_KEY", ""),
-		GoogleSearchKey:  getEnv("GOOGLE_SEARCH_API_KEY", ""),
-		SearchEnabled:    getEnvAsBool("SEARCH_ENABLED", true),
+		SearchProvider:  getEnv("SEARCH_PROVIDER", ""),
+		TavilyAPIKey:    getEnv("TAVILY_API_KEY", ""),
+		GoogleSearchKey: getEnv("GOOGLE_SEARCH_API_KEY", ""),
+		SearchEnabled:   getEnvAsBool("SEARCH_ENABLED", true),
+		// Job queue configuration
+		JobWorkers:   getEnvAsInt("JOB_WORKERS", 2),
+		JobQueueSize: getEnvAsInt("JOB_QUEUE_SIZE", 100),
+		// Duplicate detection
+		DuplicateThreshold: getEnvAsFloat("DUPLICATE_THRESHOLD", 0.9),
+		// Artifact signing
+		SigningKey: getEnv("SIGNING_KEY", ""),
+		// GitHub Issues export
+		GitHubToken:      getEnv("GITHUB_TOKEN", ""),
+		GitHubRepository: getEnv("GITHUB_REPOSITORY", ""),
+		GitHubAPIURL:     getEnv("GITHUB_API_URL", "https://api.github.com"),
+		// Artifact rendering
+		TemplateDir: getEnv("TEMPLATE_DIR", ""),
 	}
 
 	// Validate required fields
@@ -47,9 +110,13 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
 
+	if cfg.DuplicateThreshold < 0 || cfg.DuplicateThreshold > 1 {
+		return nil, fmt.Errorf("DUPLICATE_THRESHOLD must be between 0 and 1")
+	}
+
 	// Validate LLM provider
-	if cfg.LLMProvider != "openai" && cfg.LLMProvider != "anthropic" && cfg.LLMProvider != "gemini" {
-		return nil, fmt.Errorf("LLM_PROVIDER must be 'openai', 'anthropic', or 'gemini'")
+	if cfg.LLMProvider != "openai" && cfg.LLMProvider != "anthropic" && cfg.LLMProvider != "gemini" && cfg.LLMProvider != "local" {
+		return nil, fmt.Errorf("LLM_PROVIDER must be 'openai', 'anthropic', 'gemini', or 'local'")
 	}
 
 	// Validate API key based on provider
@@ -62,10 +129,20 @@ func Load() (*Config, error) {
 	if cfg.LLMProvider == "gemini" && cfg.GeminiAPIKey == "" {
 		return nil, fmt.Errorf("GEMINI_API_KEY is required when LLM_PROVIDER is 'gemini'")
 	}
+	if cfg.LLMProvider == "local" && cfg.LLMModel == "" {
+		return nil, fmt.Errorf("LLM_MODEL is required when LLM_PROVIDER is 'local'")
+	}
 
 	return cfg, nil
 }
 
+// LoadDatabaseURL reads DATABASE_URL without validating the rest of the
+// configuration, for commands that only need the database
+func LoadDatabaseURL() string {
+	_ = godotenv.Load()
+	return getEnv("DATABASE_URL", "")
+}
+
 // getEnv retrieves an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -84,6 +161,16 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsFloat retrieves an environment variable as a float or returns a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvAsBool retrieves an environment variable as a boolean or returns a default value
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
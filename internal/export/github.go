@@ -0,0 +1,340 @@
+// Package export publishes execution plans to external trackers
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+)
+
+// DefaultGitHubBaseURL is the root of the GitHub REST API
+const DefaultGitHubBaseURL = "https://api.github.com"
+
+// GitHubConfig holds the configuration for exporting to GitHub Issues
+type GitHubConfig struct {
+	Token      string // Token allowed to write issues of the repository
+	Repository string // "owner/name"
+	BaseURL    string // REST API root (default: https://api.github.com)
+	Timeout    time.Duration
+}
+
+// GitHubExporter exports execution plans to the issues of a GitHub repository:
+// a milestone per phase, an issue per task and a tracking issue with the done
+// criteria as a checklist
+type GitHubExporter struct {
+	token      string
+	repository string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGitHubExporter creates a GitHub Issues exporter
+func NewGitHubExporter(cfg GitHubConfig) (*GitHubExporter, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("GITHUB_TOKEN is required for exporting to GitHub Issues")
+	}
+	owner, name, ok := strings.Cut(cfg.Repository, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("GitHub repository must be \"owner/name\", got %q", cfg.Repository)
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = DefaultGitHubBaseURL
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+
+	return &GitHubExporter{
+		token:      cfg.Token,
+		repository: cfg.Repository,
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}, nil
+}
+
+// Repository returns the "owner/name" of the repository plans are exported to
+func (e *GitHubExporter) Repository() string {
+	return e.repository
+}
+
+// Plan is the execution plan of a decision, with its progress
+type Plan struct {
+	DecisionID string
+	Ruling     string
+	Execution  *agent.ExecutionOutput
+
+	// TaskIDs[i][j] is the stable ID of Execution.Phases[i].Tasks[j]. Tasks
+	// without one are identified by their position, "phase.task" from 1.
+	TaskIDs [][]string
+
+	DoneTasks         map[string]bool // IDs of completed tasks, exported as closed issues
+	CompletedCriteria map[int]bool    // Indexes of completed done criteria, checked in the checklist
+}
+
+// taskID returns the ID of task j of phase i
+func (p *Plan) taskID(i, j int) string {
+	if i < len(p.TaskIDs) && j < len(p.TaskIDs[i]) && p.TaskIDs[i][j] != "" {
+		return p.TaskIDs[i][j]
+	}
+	return fmt.Sprintf("%d.%d", i+1, j+1)
+}
+
+// GitHubMapping records the milestones and issues a plan was exported to
+type GitHubMapping struct {
+	TrackingIssue int            `json:"tracking_issue,omitempty"`
+	Milestones    map[int]int    `json:"milestones"` // Milestone number of each phase, by phase index
+	Issues        map[string]int `json:"issues"`     // Issue number of each task, by task ID
+}
+
+// GitHubResult represents the outcome of an export
+type GitHubResult struct {
+	Mapping          GitHubMapping
+	TrackingIssueURL string
+	Created          int // Milestones and issues created
+	Updated          int // Milestones and issues updated in place
+}
+
+// Export creates the milestones and issues of a plan, or updates those in
+// prior from an earlier export of it, so exporting again does not duplicate
+// them. A milestone or issue that no longer exists is created again.
+//
+// If the export fails part way, the result still maps everything exported so
+// far; store it so the next attempt updates those instead of duplicating them.
+func (e *GitHubExporter) Export(ctx context.Context, plan *Plan, prior *GitHubMapping) (*GitHubResult, error) {
+	result := &GitHubResult{Mapping: GitHubMapping{Milestones: map[int]int{}, Issues: map[string]int{}}}
+	if prior != nil {
+		result.Mapping.TrackingIssue = prior.TrackingIssue
+		maps.Copy(result.Mapping.Milestones, prior.Milestones)
+		maps.Copy(result.Mapping.Issues, prior.Issues)
+	}
+	mapping := &result.Mapping
+	execution := plan.Execution
+	if execution == nil {
+		execution = &agent.ExecutionOutput{}
+	}
+
+	// Task issues, each in the milestone of its phase
+	tasks := make([][]int, len(execution.Phases))
+	for i, phase := range execution.Phases {
+		closed := len(phase.Tasks) > 0
+		for j := range phase.Tasks {
+			closed = closed && plan.DoneTasks[plan.taskID(i, j)]
+		}
+		milestone, err := e.upsert(ctx, "milestones", mapping.Milestones[i], result, milestonePayload{
+			Title:       milestoneTitle(plan, i, phase.Name),
+			Description: fmt.Sprintf("Phase %d of the execution plan of decision %s", i+1, plan.DecisionID),
+			State:       state(closed),
+		})
+		if milestone != nil {
+			mapping.Milestones[i] = milestone.Number
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to export phase %d: %w", i+1, err)
+		}
+
+		tasks[i] = make([]int, len(phase.Tasks))
+		for j, task := range phase.Tasks {
+			id := plan.taskID(i, j)
+			issue, err := e.upsert(ctx, "issues", mapping.Issues[id], result, issuePayload{
+				Title:     task,
+				Body:      fmt.Sprintf("Phase %d: %s\n\nTask of the execution plan of decision `%s`.\n", i+1, phase.Name, plan.DecisionID),
+				Milestone: &milestone.Number,
+				State:     state(plan.DoneTasks[id]),
+			})
+			if issue != nil {
+				mapping.Issues[id] = issue.Number
+			}
+			if err != nil {
+				return result, fmt.Errorf("failed to export task %d.%d: %w", i+1, j+1, err)
+			}
+			tasks[i][j] = issue.Number
+		}
+	}
+
+	// Tracking issue, closed once every done criterion is completed
+	met := len(execution.DoneCriteria) > 0
+	for i := range execution.DoneCriteria {
+		met = met && plan.CompletedCriteria[i]
+	}
+	tracking, err := e.upsert(ctx, "issues", mapping.TrackingIssue, result, issuePayload{
+		Title: "Execution plan: " + plan.Ruling,
+		Body:  trackingIssueBody(plan, execution, tasks),
+		State: state(met),
+	})
+	if tracking != nil {
+		mapping.TrackingIssue = tracking.Number
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to export tracking issue: %w", err)
+	}
+	result.TrackingIssueURL = tracking.HTMLURL
+
+	return result, nil
+}
+
+// milestoneTitle returns the title of the milestone of phase i. Milestone
+// titles are unique in a repository, so it names the decision.
+func milestoneTitle(plan *Plan, i int, name string) string {
+	short := plan.DecisionID
+	if len(short) > 8 {
+		short = short[:8]
+	}
+	return fmt.Sprintf("Phase %d: %s (%s)", i+1, name, short)
+}
+
+// trackingIssueBody returns the markdown body of the tracking issue: the
+// ruling, the MVP scope, the task issues of each phase and the done criteria
+// as a checklist
+func trackingIssueBody(plan *Plan, execution *agent.ExecutionOutput, tasks [][]int) string {
+	var sb strings.Builder
+	sb.WriteString("**Decision:** `" + plan.DecisionID + "`\n\n")
+	sb.WriteString("**Ruling:** " + plan.Ruling + "\n")
+
+	if len(execution.MVPScope) > 0 {
+		sb.WriteString("\n## MVP Scope\n\n")
+		for _, item := range execution.MVPScope {
+			sb.WriteString("- " + item + "\n")
+		}
+	}
+
+	if len(execution.Phases) > 0 {
+		sb.WriteString("\n## Phases\n")
+		for i, phase := range execution.Phases {
+			fmt.Fprintf(&sb, "\n### Phase %d: %s\n\n", i+1, phase.Name)
+			for _, number := range tasks[i] {
+				fmt.Fprintf(&sb, "- #%d\n", number)
+			}
+		}
+	}
+
+	sb.WriteString("\n## Done Criteria\n\n")
+	for i, criterion := range execution.DoneCriteria {
+		mark := " "
+		if plan.CompletedCriteria[i] {
+			mark = "x"
+		}
+		sb.WriteString("- [" + mark + "] " + criterion + "\n")
+	}
+	return sb.String()
+}
+
+// state returns the GitHub state of a milestone or issue
+func state(closed bool) string {
+	if closed {
+		return "closed"
+	}
+	return "open"
+}
+
+type milestonePayload struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+}
+
+type issuePayload struct {
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Milestone *int   `json:"milestone,omitempty"`
+	State     string `json:"state"`
+}
+
+// githubObject is the part of a created or updated milestone or issue the
+// exporter reads
+type githubObject struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// GitHubError is a failed GitHub REST API request
+type GitHubError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *GitHubError) Error() string {
+	return fmt.Sprintf("GitHub API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// upsert updates milestone or issue number of a collection ("milestones" or
+// "issues") with payload, or creates one if number is 0 or no longer exists,
+// counting the change in result. A created object is returned even if a
+// later step fails, so that it is mapped.
+func (e *GitHubExporter) upsert(ctx context.Context, collection string, number int, result *GitHubResult, payload any) (*githubObject, error) {
+	path := "/repos/" + e.repository + "/" + collection
+	if number != 0 {
+		obj, err := e.do(ctx, http.MethodPatch, fmt.Sprintf("%s/%d", path, number), payload)
+		var apiErr *GitHubError
+		if !errors.As(err, &apiErr) || (apiErr.StatusCode != http.StatusNotFound && apiErr.StatusCode != http.StatusGone) {
+			if err == nil {
+				result.Updated++
+			}
+			return obj, err
+		}
+	}
+
+	// A new issue cannot be created closed; close it with an update
+	var closing any
+	if p, ok := payload.(issuePayload); ok && p.State == "closed" {
+		p.State = "open"
+		closing, payload = issuePayload{State: "closed"}, p
+	}
+	obj, err := e.do(ctx, http.MethodPost, path, payload)
+	if err != nil {
+		return nil, err
+	}
+	result.Created++
+	if closing != nil {
+		if _, err := e.do(ctx, http.MethodPatch, fmt.Sprintf("%s/%d", path, obj.Number), closing); err != nil {
+			return obj, err
+		}
+	}
+	return obj, nil
+}
+
+// do sends a request to the GitHub REST API and decodes the milestone or
+// issue it returns
+func (e *GitHubExporter) do(ctx context.Context, method, path string, payload any) (*githubObject, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, e.baseURL+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+e.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, &GitHubError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var obj githubObject
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &obj, nil
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/agent"
+	"github.com/1psychoQAQ/verdict-agent/internal/export/githubtest"
+)
+
+func testPlan() *Plan {
+	return &Plan{
+		DecisionID: "0b9f3a52-1c1e-4d7e-9a55-7f2b1c3d4e5f",
+		Ruling:     "Use Go",
+		Execution: &agent.ExecutionOutput{
+			MVPScope: []string{"Parser"},
+			Phases: []agent.Phase{
+				{Name: "Foundation", Tasks: []string{"Setup project", "Configure CI"}},
+				{Name: "Launch", Tasks: []string{"Deploy"}},
+			},
+			DoneCriteria: []string{"Parser handles all fixtures", "Deployed"},
+		},
+		TaskIDs: [][]string{{"setup", "ci"}, {"deploy"}},
+	}
+}
+
+func newTestExporter(t *testing.T) (*GitHubExporter, *githubtest.Server) {
+	t.Helper()
+	server := githubtest.NewServer("secret", "acme/plans")
+	t.Cleanup(server.Close)
+	exporter, err := NewGitHubExporter(GitHubConfig{Token: "secret", Repository: "acme/plans", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewGitHubExporter() error = %v", err)
+	}
+	return exporter, server
+}
+
+func TestNewGitHubExporter(t *testing.T) {
+	for _, repository := range []string{"", "acme", "/plans", "acme/", "acme/plans/extra"} {
+		if _, err := NewGitHubExporter(GitHubConfig{Token: "secret", Repository: repository}); err == nil {
+			t.Errorf("NewGitHubExporter(%q) succeeded", repository)
+		}
+	}
+	if _, err := NewGitHubExporter(GitHubConfig{Repository: "acme/plans"}); err == nil {
+		t.Error("NewGitHubExporter() without a token succeeded")
+	}
+}
+
+func TestGitHubExporter_Export(t *testing.T) {
+	exporter, server := newTestExporter(t)
+	ctx := context.Background()
+	plan := testPlan()
+
+	result, err := exporter.Export(ctx, plan, nil)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	mapping := result.Mapping
+	if result.Created != 6 || result.Updated != 0 {
+		t.Errorf("created %d and updated %d, want 6 created", result.Created, result.Updated)
+	}
+	if len(mapping.Milestones) != 2 || len(mapping.Issues) != 3 || mapping.TrackingIssue == 0 {
+		t.Fatalf("Mapping = %+v", mapping)
+	}
+	if len(server.Milestones()) != 2 || len(server.Issues()) != 4 {
+		t.Fatalf("server has %d milestones and %d issues", len(server.Milestones()), len(server.Issues()))
+	}
+
+	deploy, _ := server.Issue(mapping.Issues["deploy"])
+	if deploy.Title != "Deploy" || deploy.Milestone != mapping.Milestones[1] || deploy.State != "open" {
+		t.Errorf("task issue = %+v", deploy)
+	}
+	tracking, _ := server.Issue(mapping.TrackingIssue)
+	if tracking.Title != "Execution plan: Use Go" || !strings.HasSuffix(result.TrackingIssueURL, fmt.Sprintf("/issues/%d", mapping.TrackingIssue)) {
+		t.Errorf("tracking issue = %+v at %s", tracking, result.TrackingIssueURL)
+	}
+	for _, want := range []string{"- [ ] Parser handles all fixtures\n", "- [ ] Deployed\n", fmt.Sprintf("### Phase 2: Launch\n\n- #%d\n", mapping.Issues["deploy"])} {
+		if !strings.Contains(tracking.Body, want) {
+			t.Errorf("tracking issue body missing %q:\n%s", want, tracking.Body)
+		}
+	}
+
+	t.Run("re-export updates in place", func(t *testing.T) {
+		plan.DoneTasks = map[string]bool{"setup": true, "ci": true}
+		plan.CompletedCriteria = map[int]bool{0: true}
+		again, err := exporter.Export(ctx, plan, &mapping)
+		if err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		if again.Created != 0 || again.Updated != 6 || again.Mapping.TrackingIssue != mapping.TrackingIssue {
+			t.Errorf("created %d and updated %d, want 6 updated", again.Created, again.Updated)
+		}
+		if len(server.Milestones()) != 2 || len(server.Issues()) != 4 {
+			t.Errorf("re-export duplicated: %d milestones and %d issues", len(server.Milestones()), len(server.Issues()))
+		}
+		setup, _ := server.Issue(mapping.Issues["setup"])
+		tracking, _ := server.Issue(mapping.TrackingIssue)
+		if setup.State != "closed" || tracking.State != "open" || !strings.Contains(tracking.Body, "- [x] Parser handles all fixtures\n") {
+			t.Errorf("progress not exported: task %s, tracking %s:\n%s", setup.State, tracking.State, tracking.Body)
+		}
+		for _, m := range server.Milestones() {
+			if want := map[bool]string{true: "closed", false: "open"}[m.Number == mapping.Milestones[0]]; m.State != want {
+				t.Errorf("milestone %q is %s, want %s", m.Title, m.State, want)
+			}
+		}
+	})
+
+	t.Run("deleted milestone is recreated", func(t *testing.T) {
+		server.DeleteMilestone(mapping.Milestones[1])
+		again, err := exporter.Export(ctx, plan, &mapping)
+		if err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+		if again.Created != 1 || again.Mapping.Milestones[1] == mapping.Milestones[1] {
+			t.Errorf("created %d, milestones %v", again.Created, again.Mapping.Milestones)
+		}
+		deploy, _ := server.Issue(mapping.Issues["deploy"])
+		if deploy.Milestone != again.Mapping.Milestones[1] {
+			t.Errorf("task issue in milestone %d, want %d", deploy.Milestone, again.Mapping.Milestones[1])
+		}
+	})
+}
+
+func TestGitHubExporter_ExportPartialFailure(t *testing.T) {
+	exporter, server := newTestExporter(t)
+	ctx := context.Background()
+	plan := testPlan()
+
+	// The first milestone and the first task issue are created
+	server.FailAfter(2)
+	result, err := exporter.Export(ctx, plan, nil)
+	if err == nil {
+		t.Fatal("Export() succeeded")
+	}
+	if len(result.Mapping.Milestones) != 1 || len(result.Mapping.Issues) != 1 || result.Mapping.TrackingIssue != 0 {
+		t.Fatalf("Mapping = %+v, want the exported milestone and issue", result.Mapping)
+	}
+
+	// Retrying with the partial mapping completes the export without duplicates
+	server.FailAfter(0)
+	retried, err := exporter.Export(ctx, plan, &result.Mapping)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if retried.Created != 4 || retried.Updated != 2 {
+		t.Errorf("created %d and updated %d, want 4 created and 2 updated", retried.Created, retried.Updated)
+	}
+	if len(server.Milestones()) != 2 || len(server.Issues()) != 4 {
+		t.Errorf("server has %d milestones and %d issues", len(server.Milestones()), len(server.Issues()))
+	}
+}
+
+func TestGitHubExporter_ExportUnauthorized(t *testing.T) {
+	server := githubtest.NewServer("secret", "acme/plans")
+	defer server.Close()
+	exporter, err := NewGitHubExporter(GitHubConfig{Token: "wrong", Repository: "acme/plans", BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewGitHubExporter() error = %v", err)
+	}
+
+	_, err = exporter.Export(context.Background(), testPlan(), nil)
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Errorf("Export() error = %v, want a 401", err)
+	}
+}
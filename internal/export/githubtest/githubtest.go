@@ -0,0 +1,261 @@
+// Package githubtest provides an in-memory stand-in for the parts of the
+// GitHub REST API the exporter uses, for tests
+package githubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Milestone is a milestone stored by the server
+type Milestone struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+	HTMLURL     string `json:"html_url"`
+}
+
+// Issue is an issue stored by the server
+type Issue struct {
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Milestone int    `json:"-"` // Number of the issue's milestone; 0 for none
+	State     string `json:"state"`
+	HTMLURL   string `json:"html_url"`
+}
+
+// Server serves the milestones and issues of one repository. Requests must
+// carry its token.
+type Server struct {
+	*httptest.Server
+	Token      string
+	Repository string // "owner/name"
+
+	mu         sync.Mutex
+	milestones map[int]*Milestone
+	issues     map[int]*Issue
+	next       int // Issues and milestones are numbered apart on GitHub; one counter keeps tests simple
+	requests   int
+	failAfter  int // Requests served before every request fails; 0 for none
+}
+
+// NewServer starts a server for a repository; close it when done
+func NewServer(token, repository string) *Server {
+	s := &Server{
+		Token:      token,
+		Repository: repository,
+		milestones: make(map[int]*Milestone),
+		issues:     make(map[int]*Issue),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// Milestones returns copies of the stored milestones
+func (s *Server) Milestones() []Milestone {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Milestone, 0, len(s.milestones))
+	for _, m := range s.milestones {
+		result = append(result, *m)
+	}
+	return result
+}
+
+// Issues returns copies of the stored issues
+func (s *Server) Issues() []Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Issue, 0, len(s.issues))
+	for _, i := range s.issues {
+		result = append(result, *i)
+	}
+	return result
+}
+
+// Issue returns a copy of an issue, if it exists
+func (s *Server) Issue(number int) (Issue, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i, ok := s.issues[number]; ok {
+		return *i, true
+	}
+	return Issue{}, false
+}
+
+// FailAfter makes every request after the next n fail with 503 Service
+// Unavailable
+func (s *Server) FailAfter(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests, s.failAfter = 0, n
+}
+
+// DeleteMilestone deletes a milestone, as a repository maintainer might
+func (s *Server) DeleteMilestone(number int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.milestones, number)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	if s.failAfter > 0 && s.requests > s.failAfter {
+		writeMessage(w, http.StatusServiceUnavailable, "Service Unavailable")
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		writeMessage(w, http.StatusUnauthorized, "Bad credentials")
+		return
+	}
+
+	// /repos/{owner}/{name}/{collection}[/{number}]
+	rest, ok := strings.CutPrefix(r.URL.Path, "/repos/"+s.Repository+"/")
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	collection, num, hasNumber := strings.Cut(rest, "/")
+	number, err := strconv.Atoi(num)
+	if hasNumber && err != nil {
+		writeMessage(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	var fields map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeMessage(w, http.StatusBadRequest, "Problems parsing JSON")
+		return
+	}
+
+	switch {
+	case collection == "milestones" && r.Method == http.MethodPost && !hasNumber:
+		s.createMilestone(w, fields)
+	case collection == "milestones" && r.Method == http.MethodPatch && hasNumber:
+		s.updateMilestone(w, number, fields)
+	case collection == "issues" && r.Method == http.MethodPost && !hasNumber:
+		s.createIssue(w, fields)
+	case collection == "issues" && r.Method == http.MethodPatch && hasNumber:
+		s.updateIssue(w, number, fields)
+	default:
+		writeMessage(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) createMilestone(w http.ResponseWriter, fields map[string]any) {
+	title, _ := fields["title"].(string)
+	if title == "" {
+		writeMessage(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	for _, m := range s.milestones {
+		if m.Title == title {
+			writeMessage(w, http.StatusUnprocessableEntity, "Validation Failed: title already_exists")
+			return
+		}
+	}
+	s.next++
+	m := &Milestone{Number: s.next, State: "open", HTMLURL: s.url("milestone", s.next)}
+	applyMilestone(m, fields)
+	s.milestones[m.Number] = m
+	writeJSON(w, http.StatusCreated, m)
+}
+
+func (s *Server) updateMilestone(w http.ResponseWriter, number int, fields map[string]any) {
+	m, ok := s.milestones[number]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	applyMilestone(m, fields)
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, fields map[string]any) {
+	title, _ := fields["title"].(string)
+	if title == "" {
+		writeMessage(w, http.StatusUnprocessableEntity, "Validation Failed")
+		return
+	}
+	s.next++
+	i := &Issue{Number: s.next, State: "open", HTMLURL: s.url("issues", s.next)}
+	// Issues are created open
+	delete(fields, "state")
+	if !s.applyIssue(w, i, fields) {
+		return
+	}
+	s.issues[i.Number] = i
+	writeJSON(w, http.StatusCreated, i)
+}
+
+func (s *Server) updateIssue(w http.ResponseWriter, number int, fields map[string]any) {
+	i, ok := s.issues[number]
+	if !ok {
+		writeMessage(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	updated := *i
+	if !s.applyIssue(w, &updated, fields) {
+		return
+	}
+	*i = updated
+	writeJSON(w, http.StatusOK, i)
+}
+
+func applyMilestone(m *Milestone, fields map[string]any) {
+	if v, ok := fields["title"].(string); ok {
+		m.Title = v
+	}
+	if v, ok := fields["description"].(string); ok {
+		m.Description = v
+	}
+	if v, ok := fields["state"].(string); ok {
+		m.State = v
+	}
+}
+
+// applyIssue sets the given fields of an issue, failing the request if it
+// names a milestone that does not exist
+func (s *Server) applyIssue(w http.ResponseWriter, i *Issue, fields map[string]any) bool {
+	if v, ok := fields["title"].(string); ok {
+		i.Title = v
+	}
+	if v, ok := fields["body"].(string); ok {
+		i.Body = v
+	}
+	if v, ok := fields["state"].(string); ok {
+		i.State = v
+	}
+	if v, ok := fields["milestone"].(float64); ok {
+		if _, exists := s.milestones[int(v)]; !exists {
+			writeMessage(w, http.StatusUnprocessableEntity, "Validation Failed: milestone invalid")
+			return false
+		}
+		i.Milestone = int(v)
+	}
+	return true
+}
+
+func (s *Server) url(kind string, number int) string {
+	return fmt.Sprintf("https://github.com/%s/%s/%d", s.Repository, kind, number)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
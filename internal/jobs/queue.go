@@ -0,0 +1,282 @@
+// Package jobs runs verdict pipelines asynchronously on a bounded worker pool
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/google/uuid"
+)
+
+// Error types
+var (
+	ErrQueueFull      = errors.New("job queue is full")
+	ErrNotRunning     = errors.New("job queue is not running")
+	ErrNoRunner       = errors.New("job runner is not set")
+	ErrJobFinished    = errors.New("job already finished")
+	ErrAlreadyStarted = errors.New("job queue already started")
+)
+
+// RunFunc executes a job and returns its result payload.
+// The context is canceled when the job is canceled or the queue stops.
+type RunFunc func(ctx context.Context, job *storage.Job) (json.RawMessage, error)
+
+// Config holds the worker pool configuration
+type Config struct {
+	Workers   int // Concurrent pipeline runs (default 2)
+	QueueSize int // Jobs waiting for a worker (default 100)
+}
+
+// Queue is a bounded worker pool in front of the pipeline.
+// Job state is persisted through storage.Repository so queued and interrupted
+// jobs are picked up again after a restart.
+type Queue struct {
+	repo   storage.Repository
+	run    RunFunc
+	config Config
+
+	pending chan uuid.UUID
+
+	mu      sync.Mutex
+	cancels map[uuid.UUID]context.CancelFunc // running job -> cancel
+	ctx     context.Context
+	stop    context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+// NewQueue creates a new job queue. Set the runner with SetRunner before Start.
+func NewQueue(repo storage.Repository, cfg Config) *Queue {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 2
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+
+	return &Queue{
+		repo:    repo,
+		config:  cfg,
+		pending: make(chan uuid.UUID, cfg.QueueSize),
+		cancels: make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+// SetRunner sets the function that executes jobs
+func (q *Queue) SetRunner(run RunFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.run = run
+}
+
+// Start recovers unfinished jobs from the repository and launches the workers
+func (q *Queue) Start(ctx context.Context) error {
+	q.mu.Lock()
+	if q.run == nil {
+		q.mu.Unlock()
+		return ErrNoRunner
+	}
+	if q.ctx != nil {
+		q.mu.Unlock()
+		return ErrAlreadyStarted
+	}
+	q.ctx, q.stop = context.WithCancel(ctx)
+	q.mu.Unlock()
+
+	// Jobs left running by a previous process were interrupted; run them again
+	unfinished, err := q.repo.ListJobsByStatus(ctx, storage.JobQueued, storage.JobRunning)
+	if err != nil {
+		q.stop()
+		return fmt.Errorf("failed to recover jobs: %w", err)
+	}
+	for _, job := range unfinished {
+		if job.Status == storage.JobRunning {
+			job.Status = storage.JobQueued
+			job.StartedAt = nil
+			if err := q.repo.UpdateJob(ctx, job); err != nil {
+				log.Printf("Failed to requeue job %s: %v", job.ID, err)
+				continue
+			}
+		}
+	}
+
+	for i := 0; i < q.config.Workers; i++ {
+		q.wg.Add(1)
+		go q.worker()
+	}
+
+	// Feed recovered jobs without blocking startup if they exceed the queue size
+	if len(unfinished) > 0 {
+		log.Printf("Recovered %d unfinished job(s)", len(unfinished))
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			for _, job := range unfinished {
+				select {
+				case q.pending <- job.ID:
+				case <-q.ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	return nil
+}
+
+// Stop cancels running jobs and waits for the workers to exit.
+// Canceled jobs remain queued in the repository and resume on the next Start.
+func (q *Queue) Stop() {
+	q.mu.Lock()
+	stop := q.stop
+	q.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+	stop()
+	q.wg.Wait()
+}
+
+// Enqueue persists a new job and schedules it for execution
+func (q *Queue) Enqueue(ctx context.Context, job *storage.Job) error {
+	q.mu.Lock()
+	running := q.ctx != nil && q.ctx.Err() == nil
+	q.mu.Unlock()
+	if !running {
+		return ErrNotRunning
+	}
+
+	// Reject early rather than persisting a job that cannot be scheduled
+	if len(q.pending) >= cap(q.pending) {
+		return ErrQueueFull
+	}
+
+	job.Status = storage.JobQueued
+	if err := q.repo.CreateJob(ctx, job); err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	select {
+	case q.pending <- job.ID:
+		return nil
+	default:
+		// Lost a race for the last slot; record the rejection
+		q.finish(context.Background(), job, nil, ErrQueueFull)
+		return ErrQueueFull
+	}
+}
+
+// Cancel stops a queued or running job
+func (q *Queue) Cancel(ctx context.Context, id uuid.UUID) (*storage.Job, error) {
+	job, err := q.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status.IsTerminal() {
+		return job, ErrJobFinished
+	}
+
+	// Mark canceled first so a worker picking the job up skips it
+	now := time.Now()
+	job.Status = storage.JobCanceled
+	job.FinishedAt = &now
+	if err := q.repo.UpdateJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+
+	q.mu.Lock()
+	if cancel, running := q.cancels[id]; running {
+		cancel()
+	}
+	q.mu.Unlock()
+
+	return job, nil
+}
+
+// worker executes pending jobs until the queue stops
+func (q *Queue) worker() {
+	defer q.wg.Done()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case id := <-q.pending:
+			q.execute(id)
+		}
+	}
+}
+
+// execute runs a single job and records its outcome
+func (q *Queue) execute(id uuid.UUID) {
+	// Register the cancel function before loading the job so a concurrent
+	// Cancel always reaches the run
+	jobCtx, cancel := context.WithCancel(q.ctx)
+	defer cancel()
+
+	q.mu.Lock()
+	q.cancels[id] = cancel
+	q.mu.Unlock()
+	defer func() {
+		q.mu.Lock()
+		delete(q.cancels, id)
+		q.mu.Unlock()
+	}()
+
+	job, err := q.repo.GetJob(q.ctx, id)
+	if err != nil {
+		log.Printf("Failed to load job %s: %v", id, err)
+		return
+	}
+	// Canceled while waiting in the queue
+	if job.Status != storage.JobQueued {
+		return
+	}
+
+	now := time.Now()
+	job.Status = storage.JobRunning
+	job.StartedAt = &now
+	if err := q.repo.UpdateJob(q.ctx, job); err != nil {
+		log.Printf("Failed to mark job %s running: %v", id, err)
+		return
+	}
+
+	result, runErr := q.run(jobCtx, job)
+
+	// Shutting down: leave the job for recovery on the next start
+	if q.ctx.Err() != nil {
+		return
+	}
+
+	// Canceled while running
+	if jobCtx.Err() != nil {
+		runErr = context.Canceled
+	}
+
+	q.finish(context.Background(), job, result, runErr)
+}
+
+// finish records the final state of a job
+func (q *Queue) finish(ctx context.Context, job *storage.Job, result json.RawMessage, runErr error) {
+	now := time.Now()
+	job.FinishedAt = &now
+	if errors.Is(runErr, context.Canceled) {
+		job.Status = storage.JobCanceled
+	} else if runErr != nil {
+		job.Status = storage.JobFailed
+		job.Error = runErr.Error()
+	} else {
+		job.Status = storage.JobSucceeded
+		job.Result = result
+	}
+
+	if err := q.repo.UpdateJob(ctx, job); err != nil {
+		log.Printf("Failed to record result of job %s: %v", job.ID, err)
+	}
+}
@@ -0,0 +1,221 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/google/uuid"
+)
+
+// waitForStatus polls the repository until the job reaches the wanted status
+func waitForStatus(t *testing.T, repo storage.Repository, id uuid.UUID, want storage.JobStatus) *storage.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := repo.GetJob(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetJob() error: %v", err)
+		}
+		if job.Status == want {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	job, _ := repo.GetJob(context.Background(), id)
+	t.Fatalf("job %s did not reach status %s, last status %s", id, want, job.Status)
+	return nil
+}
+
+func TestQueue_RunsJob(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	q := NewQueue(repo, Config{Workers: 1, QueueSize: 10})
+	q.SetRunner(func(ctx context.Context, job *storage.Job) (json.RawMessage, error) {
+		return json.RawMessage(`{"status":"verdict"}`), nil
+	})
+
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer q.Stop()
+
+	job := &storage.Job{Request: json.RawMessage(`{"input":"test"}`)}
+	if err := q.Enqueue(context.Background(), job); err != nil {
+		t.Fatalf("Enqueue() error: %v", err)
+	}
+	if job.ID == uuid.Nil {
+		t.Fatal("expected job ID to be assigned")
+	}
+
+	done := waitForStatus(t, repo, job.ID, storage.JobSucceeded)
+	if string(done.Result) != `{"status":"verdict"}` {
+		t.Errorf("unexpected result: %s", done.Result)
+	}
+	if done.StartedAt == nil || done.FinishedAt == nil {
+		t.Error("expected started_at and finished_at to be set")
+	}
+}
+
+func TestQueue_FailedJob(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	q := NewQueue(repo, Config{Workers: 1})
+	q.SetRunner(func(ctx context.Context, job *storage.Job) (json.RawMessage, error) {
+		return nil, errors.New("pipeline failed")
+	})
+
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer q.Stop()
+
+	job := &storage.Job{Request: json.RawMessage(`{}`)}
+	if err := q.Enqueue(context.Background(), job); err != nil {
+		t.Fatalf("Enqueue() error: %v", err)
+	}
+
+	failed := waitForStatus(t, repo, job.ID, storage.JobFailed)
+	if failed.Error != "pipeline failed" {
+		t.Errorf("expected error message, got %q", failed.Error)
+	}
+}
+
+func TestQueue_CancelRunningJob(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	started := make(chan struct{})
+	q := NewQueue(repo, Config{Workers: 1})
+	q.SetRunner(func(ctx context.Context, job *storage.Job) (json.RawMessage, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer q.Stop()
+
+	job := &storage.Job{Request: json.RawMessage(`{}`)}
+	if err := q.Enqueue(context.Background(), job); err != nil {
+		t.Fatalf("Enqueue() error: %v", err)
+	}
+	<-started
+
+	if _, err := q.Cancel(context.Background(), job.ID); err != nil {
+		t.Fatalf("Cancel() error: %v", err)
+	}
+
+	waitForStatus(t, repo, job.ID, storage.JobCanceled)
+
+	// Canceling again reports the job as finished
+	if _, err := q.Cancel(context.Background(), job.ID); !errors.Is(err, ErrJobFinished) {
+		t.Errorf("expected ErrJobFinished, got %v", err)
+	}
+}
+
+func TestQueue_CancelQueuedJob(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	release := make(chan struct{})
+	var ran []uuid.UUID
+	q := NewQueue(repo, Config{Workers: 1})
+	q.SetRunner(func(ctx context.Context, job *storage.Job) (json.RawMessage, error) {
+		ran = append(ran, job.ID)
+		<-release
+		return json.RawMessage(`{}`), nil
+	})
+
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer q.Stop()
+
+	first := &storage.Job{Request: json.RawMessage(`{}`)}
+	second := &storage.Job{Request: json.RawMessage(`{}`)}
+	q.Enqueue(context.Background(), first)
+	waitForStatus(t, repo, first.ID, storage.JobRunning)
+	q.Enqueue(context.Background(), second)
+
+	if _, err := q.Cancel(context.Background(), second.ID); err != nil {
+		t.Fatalf("Cancel() error: %v", err)
+	}
+	close(release)
+
+	waitForStatus(t, repo, first.ID, storage.JobSucceeded)
+	// Give the worker a chance to pick up the canceled job
+	time.Sleep(20 * time.Millisecond)
+
+	if len(ran) != 1 || ran[0] != first.ID {
+		t.Errorf("expected only the first job to run, ran %v", ran)
+	}
+	job, _ := repo.GetJob(context.Background(), second.ID)
+	if job.Status != storage.JobCanceled {
+		t.Errorf("expected second job canceled, got %s", job.Status)
+	}
+}
+
+func TestQueue_QueueFull(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	release := make(chan struct{})
+	defer close(release)
+	q := NewQueue(repo, Config{Workers: 1, QueueSize: 1})
+	q.SetRunner(func(ctx context.Context, job *storage.Job) (json.RawMessage, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return json.RawMessage(`{}`), nil
+	})
+
+	if err := q.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer q.Stop()
+
+	running := &storage.Job{Request: json.RawMessage(`{}`)}
+	q.Enqueue(context.Background(), running)
+	waitForStatus(t, repo, running.ID, storage.JobRunning)
+
+	if err := q.Enqueue(context.Background(), &storage.Job{Request: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("expected second job to be queued, got %v", err)
+	}
+	if err := q.Enqueue(context.Background(), &storage.Job{Request: json.RawMessage(`{}`)}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("expected ErrQueueFull, got %v", err)
+	}
+}
+
+func TestQueue_RecoversUnfinishedJobs(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	ctx := context.Background()
+
+	// Simulate jobs left behind by a previous process
+	queued := &storage.Job{Request: json.RawMessage(`{}`), Status: storage.JobQueued}
+	interrupted := &storage.Job{Request: json.RawMessage(`{}`), Status: storage.JobRunning}
+	repo.CreateJob(ctx, queued)
+	repo.CreateJob(ctx, interrupted)
+
+	q := NewQueue(repo, Config{Workers: 2})
+	q.SetRunner(func(ctx context.Context, job *storage.Job) (json.RawMessage, error) {
+		return json.RawMessage(`{}`), nil
+	})
+	if err := q.Start(ctx); err != nil {
+		t.Fatalf("Start() error: %v", err)
+	}
+	defer q.Stop()
+
+	waitForStatus(t, repo, queued.ID, storage.JobSucceeded)
+	waitForStatus(t, repo, interrupted.ID, storage.JobSucceeded)
+}
+
+func TestQueue_StartRequiresRunner(t *testing.T) {
+	q := NewQueue(storage.NewMemoryRepository(), Config{})
+	if err := q.Start(context.Background()); !errors.Is(err, ErrNoRunner) {
+		t.Errorf("expected ErrNoRunner, got %v", err)
+	}
+	if err := q.Enqueue(context.Background(), &storage.Job{}); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning, got %v", err)
+	}
+}
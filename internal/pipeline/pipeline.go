@@ -28,14 +28,83 @@ type Pipeline struct {
 	executionAgent *agent.ExecutionAgent
 	searchClient   search.Client
 	timeout        time.Duration
+	prices         agent.PriceTable
 }
 
 // PipelineResult contains the complete output of the pipeline execution
 type PipelineResult struct {
-	Input     string                `json:"input"`
-	Verdict   *agent.VerdictOutput  `json:"verdict"`
+	Input     string                 `json:"input"`
+	Verdict   *agent.VerdictOutput   `json:"verdict"`
 	Execution *agent.ExecutionOutput `json:"execution"`
-	Duration  time.Duration         `json:"duration"`
+	Duration  time.Duration          `json:"duration"`
+	Usage     agent.Usage            `json:"usage"`               // Total tokens across all LLM calls
+	LLMCalls  []agent.UsageRecord    `json:"llm_calls"`           // Per-call token usage, in call order
+	CostUSD   float64                `json:"cost_usd"`            // Estimated from the price table
+	Repairs   []agent.RepairAttempt  `json:"repairs,omitempty"`   // Corrective turns for outputs that failed validation
+	Providers map[string]string      `json:"providers,omitempty"` // "provider/model" that served each stage
+
+	QualityFlags []agent.QualityFlag `json:"quality_flags,omitempty"` // Hedging or multi-option rulings
+}
+
+// Stage identifies a step of the verdict flow reported to progress observers
+type Stage string
+
+// Pipeline stages, in execution order. Clarification, artifact generation and
+// persistence run outside the pipeline but share the same vocabulary so callers
+// can report a single, consistent stream of progress.
+const (
+	StageClarification Stage = "clarification"
+	StageSearch        Stage = "search"
+	StageVerdict       Stage = "verdict"
+	StageExecution     Stage = "execution"
+	StageArtifacts     Stage = "artifacts"
+	StagePersistence   Stage = "persistence"
+)
+
+// Stage statuses
+const (
+	StatusStarted   = "started"
+	StatusCompleted = "completed"
+	StatusSkipped   = "skipped"
+	StatusFailed    = "failed"
+	StatusDelta     = "delta" // Incremental model output; Data is the text fragment
+)
+
+// Event is a progress notification emitted while the pipeline runs
+type Event struct {
+	Stage  Stage  `json:"stage"`
+	Status string `json:"status"`
+	Data   any    `json:"data,omitempty"` // Partial structured output of the stage
+	Error  string `json:"error,omitempty"`
+}
+
+// ProgressFunc receives pipeline progress events. It is called synchronously
+// from the goroutine running the pipeline.
+type ProgressFunc func(Event)
+
+// emit sends an event to the progress function if one is set
+func (f ProgressFunc) emit(stage Stage, status string, data any) {
+	if f != nil {
+		f(Event{Stage: stage, Status: status, Data: data})
+	}
+}
+
+// fail sends a failure event to the progress function if one is set
+func (f ProgressFunc) fail(stage Stage, err error) {
+	if f != nil {
+		f(Event{Stage: stage, Status: StatusFailed, Error: err.Error()})
+	}
+}
+
+// delta returns a function that forwards streamed model output for stage as
+// delta events, or nil if no progress function is set
+func (f ProgressFunc) delta(stage Stage) agent.DeltaFunc {
+	if f == nil {
+		return nil
+	}
+	return func(text string) {
+		f(Event{Stage: stage, Status: StatusDelta, Data: text})
+	}
 }
 
 // NewPipeline creates a new pipeline with the given agents and timeout
@@ -54,15 +123,31 @@ func NewPipelineWithSearch(verdictAgent *agent.VerdictAgent, executionAgent *age
 		executionAgent: executionAgent,
 		searchClient:   searchClient,
 		timeout:        timeout,
+		prices:         agent.DefaultPriceTable(),
 	}
 }
 
+// SetPriceTable sets the per-model prices used to estimate the cost of a run
+func (p *Pipeline) SetPriceTable(prices agent.PriceTable) {
+	p.prices = prices
+}
+
 // Execute runs the complete pipeline: validate input → search → Agent A → validate → Agent B → validate
 func (p *Pipeline) Execute(ctx context.Context, input string) (*PipelineResult, error) {
+	return p.ExecuteWithProgress(ctx, input, nil)
+}
+
+// ExecuteWithProgress runs the pipeline like Execute and reports each stage to
+// progress as it starts and finishes. A nil progress function is allowed.
+func (p *Pipeline) ExecuteWithProgress(ctx context.Context, input string, progress ProgressFunc) (*PipelineResult, error) {
 	startTime := time.Now()
 
-	// Create context with timeout
-	timeoutCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	// Create context with timeout, recording token usage of every LLM call and
+	// every repair of invalid agent output
+	usage := agent.NewUsageRecorder()
+	repairs := agent.NewRepairLog()
+	runCtx := agent.WithRepairLog(agent.WithUsageRecorder(ctx, usage), repairs)
+	timeoutCtx, cancel := context.WithTimeout(runCtx, p.timeout)
 	defer cancel()
 
 	result := &PipelineResult{
@@ -77,48 +162,71 @@ func (p *Pipeline) Execute(ctx context.Context, input string) (*PipelineResult,
 	// Step 2: Perform web search (if enabled)
 	searchContext := ""
 	if p.searchClient != nil {
+		progress.emit(StageSearch, StatusStarted, nil)
 		searchResults, err := p.searchClient.Search(timeoutCtx, input, 5)
 		if err != nil {
 			// Log but don't fail - search is optional
 			log.Printf("Web search failed (continuing without): %v", err)
+			progress.fail(StageSearch, err)
 		} else if searchResults != nil {
 			searchContext = searchResults.FormatForPrompt()
 			log.Printf("Web search completed: %d results for '%s'", len(searchResults.Results), input)
+			progress.emit(StageSearch, StatusCompleted, searchResults)
+		} else {
+			progress.emit(StageSearch, StatusCompleted, nil)
 		}
+	} else {
+		progress.emit(StageSearch, StatusSkipped, nil)
 	}
 
 	// Step 3: Execute Agent A (Verdict) with search context
-	verdict, err := p.executeVerdictAgentWithContext(timeoutCtx, input, searchContext)
+	progress.emit(StageVerdict, StatusStarted, nil)
+	verdictCtx := agent.WithStage(timeoutCtx, string(StageVerdict))
+	verdict, err := p.executeVerdictAgentWithContext(verdictCtx, input, searchContext, progress.delta(StageVerdict))
 	if err != nil {
+		progress.fail(StageVerdict, err)
 		if errors.Is(err, context.DeadlineExceeded) {
 			return nil, ErrTimeout
 		}
-		return nil, fmt.Errorf("%w: %v", ErrVerdictFailed, err)
+		return nil, fmt.Errorf("%w: %w", ErrVerdictFailed, err)
 	}
 	result.Verdict = verdict
 
 	// Step 4: Validate Agent A output
 	if err := p.validateVerdictOutput(verdict); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrVerdictFailed, err)
+		progress.fail(StageVerdict, err)
+		return nil, fmt.Errorf("%w: %w", ErrVerdictFailed, err)
 	}
+	result.QualityFlags = p.verdictAgent.QualityFlags(verdict)
+	progress.emit(StageVerdict, StatusCompleted, verdict)
 
 	// Step 5: Execute Agent B (Execution)
-	execution, err := p.executeExecutionAgent(timeoutCtx, verdict)
+	progress.emit(StageExecution, StatusStarted, nil)
+	executionCtx := agent.WithStage(timeoutCtx, string(StageExecution))
+	execution, err := p.executeExecutionAgent(executionCtx, verdict, progress.delta(StageExecution))
 	if err != nil {
+		progress.fail(StageExecution, err)
 		if errors.Is(err, context.DeadlineExceeded) {
 			return nil, ErrTimeout
 		}
-		return nil, fmt.Errorf("%w: %v", ErrExecutionFailed, err)
+		return nil, fmt.Errorf("%w: %w", ErrExecutionFailed, err)
 	}
 	result.Execution = execution
 
 	// Step 6: Validate Agent B output
 	if err := p.validateExecutionOutput(execution); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrExecutionFailed, err)
+		progress.fail(StageExecution, err)
+		return nil, fmt.Errorf("%w: %w", ErrExecutionFailed, err)
 	}
+	progress.emit(StageExecution, StatusCompleted, execution)
 
-	// Calculate total duration
+	// Calculate total duration and spend
 	result.Duration = time.Since(startTime)
+	result.LLMCalls = usage.Records()
+	result.Usage = usage.Total()
+	result.CostUSD = p.prices.Cost(result.LLMCalls)
+	result.Repairs = repairs.Attempts()
+	result.Providers = usage.ServedBy()
 
 	return result, nil
 }
@@ -141,8 +249,8 @@ func (p *Pipeline) executeVerdictAgent(ctx context.Context, input string) (*agen
 }
 
 // executeVerdictAgentWithContext calls Agent A with the user input and search context
-func (p *Pipeline) executeVerdictAgentWithContext(ctx context.Context, input string, searchContext string) (*agent.VerdictOutput, error) {
-	return p.verdictAgent.ProcessWithContext(ctx, input, searchContext)
+func (p *Pipeline) executeVerdictAgentWithContext(ctx context.Context, input string, searchContext string, onDelta agent.DeltaFunc) (*agent.VerdictOutput, error) {
+	return p.verdictAgent.ProcessStream(ctx, input, searchContext, onDelta)
 }
 
 // validateVerdictOutput ensures the verdict output meets quality standards
@@ -160,8 +268,8 @@ func (p *Pipeline) validateVerdictOutput(output *agent.VerdictOutput) error {
 }
 
 // executeExecutionAgent calls Agent B with the verdict
-func (p *Pipeline) executeExecutionAgent(ctx context.Context, verdict *agent.VerdictOutput) (*agent.ExecutionOutput, error) {
-	return p.executionAgent.Process(ctx, verdict)
+func (p *Pipeline) executeExecutionAgent(ctx context.Context, verdict *agent.VerdictOutput, onDelta agent.DeltaFunc) (*agent.ExecutionOutput, error) {
+	return p.executionAgent.ProcessStream(ctx, verdict, onDelta)
 }
 
 // validateExecutionOutput ensures the execution output meets constraints
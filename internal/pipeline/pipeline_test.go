@@ -2,6 +2,7 @@ package pipeline
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"strings"
 	"testing"
@@ -464,3 +465,181 @@ func TestPipeline_Execute_InputMaxLength(t *testing.T) {
 		t.Errorf("expected ErrInputTooLong for 10001 chars, got %v", err)
 	}
 }
+
+func TestPipeline_ExecuteWithProgress(t *testing.T) {
+	client := &mockLLMClient{
+		verdictResponse: &agent.VerdictOutput{
+			Ruling:    "Build a REST API",
+			Rationale: "REST APIs are simple and widely supported",
+		},
+		executionResponse: &agent.ExecutionOutput{
+			MVPScope: []string{"Basic CRUD"},
+			Phases: []agent.Phase{
+				{Name: "Phase 1", Tasks: []string{"Create project"}},
+			},
+			DoneCriteria: []string{"API responds to requests"},
+		},
+	}
+
+	p := NewPipeline(agent.NewVerdictAgent(client), agent.NewExecutionAgent(client), 1*time.Minute)
+
+	var events []Event
+	result, err := p.ExecuteWithProgress(context.Background(), "Should I build a REST API?", func(e Event) {
+		events = append(events, e)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []struct {
+		stage  Stage
+		status string
+	}{
+		{StageSearch, StatusSkipped},
+		{StageVerdict, StatusStarted},
+		{StageVerdict, StatusCompleted},
+		{StageExecution, StatusStarted},
+		{StageExecution, StatusCompleted},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("expected %d events, got %d: %+v", len(want), len(events), events)
+	}
+	for i, w := range want {
+		if events[i].Stage != w.stage || events[i].Status != w.status {
+			t.Errorf("event %d: expected %s/%s, got %s/%s", i, w.stage, w.status, events[i].Stage, events[i].Status)
+		}
+	}
+
+	if events[2].Data != result.Verdict {
+		t.Error("expected verdict completed event to carry the verdict output")
+	}
+	if events[4].Data != result.Execution {
+		t.Error("expected execution completed event to carry the execution output")
+	}
+}
+
+func TestPipeline_ExecuteWithProgress_Failure(t *testing.T) {
+	client := &mockLLMClient{
+		verdictError: errors.New("LLM API error"),
+	}
+
+	p := NewPipeline(agent.NewVerdictAgent(client), agent.NewExecutionAgent(client), 1*time.Minute)
+
+	var last Event
+	_, err := p.ExecuteWithProgress(context.Background(), "Test input", func(e Event) {
+		last = e
+	})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if last.Stage != StageVerdict || last.Status != StatusFailed || last.Error == "" {
+		t.Errorf("expected failed verdict event, got %+v", last)
+	}
+}
+
+// streamingLLMClient streams canned responses, one per call, in two chunks each
+type streamingLLMClient struct {
+	mockLLMClient
+	responses []string
+}
+
+func (m *streamingLLMClient) Stream(ctx context.Context, prompt string, onDelta agent.DeltaFunc) (string, error) {
+	response := m.responses[m.callCount]
+	m.callCount++
+	half := len(response) / 2
+	onDelta(response[:half])
+	onDelta(response[half:])
+	return response, nil
+}
+
+func TestPipeline_ExecuteWithProgress_Deltas(t *testing.T) {
+	client := &streamingLLMClient{responses: []string{
+		`{"ruling": "Build a REST API", "rationale": "Simple and widely supported", "rejected": []}`,
+		`{"mvp_scope": ["Basic CRUD"], "phases": [{"name": "Phase 1", "tasks": ["Create project"]}], "done_criteria": ["API responds"]}`,
+	}}
+
+	p := NewPipeline(agent.NewVerdictAgent(client), agent.NewExecutionAgent(client), 1*time.Minute)
+
+	deltas := map[Stage]string{}
+	result, err := p.ExecuteWithProgress(context.Background(), "Should I build a REST API?", func(e Event) {
+		if e.Status == StatusDelta {
+			deltas[e.Stage] += e.Data.(string)
+		}
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if deltas[StageVerdict] != client.responses[0] {
+		t.Errorf("expected verdict deltas to reassemble the response, got %q", deltas[StageVerdict])
+	}
+	if deltas[StageExecution] != client.responses[1] {
+		t.Errorf("expected execution deltas to reassemble the response, got %q", deltas[StageExecution])
+	}
+	if result.Verdict.Ruling != "Build a REST API" {
+		t.Errorf("unexpected ruling: %q", result.Verdict.Ruling)
+	}
+}
+
+func TestPipeline_UsageAndCost(t *testing.T) {
+	client := &usageLLMClient{responses: []string{
+		`{"ruling": "Build a REST API", "rationale": "Simple and widely supported", "rejected": []}`,
+		`{"mvp_scope": ["Basic CRUD"], "phases": [{"name": "Phase 1", "tasks": ["Create project"]}], "done_criteria": ["API responds"]}`,
+	}}
+
+	p := NewPipeline(agent.NewVerdictAgent(client), agent.NewExecutionAgent(client), 1*time.Minute)
+	p.SetPriceTable(agent.PriceTable{"test-model": {Input: 1, Output: 2}})
+
+	result, err := p.Execute(context.Background(), "Should I build a REST API?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.LLMCalls) != 2 {
+		t.Fatalf("expected 2 recorded calls, got %d", len(result.LLMCalls))
+	}
+	if result.Usage.InputTokens != 2_000_000 || result.Usage.OutputTokens != 1_000_000 {
+		t.Errorf("unexpected total usage: %+v", result.Usage)
+	}
+	if result.CostUSD != 4 {
+		t.Errorf("expected cost 4, got %v", result.CostUSD)
+	}
+}
+
+// usageLLMClient reports one million input and half a million output tokens per call
+type usageLLMClient struct {
+	mockLLMClient
+	responses []string
+}
+
+func (m *usageLLMClient) CompleteJSON(ctx context.Context, prompt string, result interface{}) error {
+	agent.UsageRecorderFromContext(ctx).Record("test", "test-model", agent.Usage{InputTokens: 1_000_000, OutputTokens: 500_000})
+	response := m.responses[m.callCount]
+	m.callCount++
+	return json.Unmarshal([]byte(response), result)
+}
+
+func TestPipeline_RecordsRepairs(t *testing.T) {
+	client := &usageLLMClient{responses: []string{
+		`{"ruling": "Build a REST API", "rationale": "Simple and widely supported", "rejected": []}`,
+		`{"mvp_scope": ["Basic CRUD"], "phases": [{"name": "Phase 1", "tasks": ["Create project"]}], "done_criteria": []}`,
+		`{"mvp_scope": ["Basic CRUD"], "phases": [{"name": "Phase 1", "tasks": ["Create project"]}], "done_criteria": ["API responds"]}`,
+	}}
+
+	p := NewPipeline(agent.NewVerdictAgent(client), agent.NewExecutionAgent(client), 1*time.Minute)
+
+	result, err := p.Execute(context.Background(), "Should I build a REST API?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Repairs) != 1 {
+		t.Fatalf("expected 1 repair, got %d", len(result.Repairs))
+	}
+	if result.Repairs[0].Agent != "execution" || !result.Repairs[0].Repaired {
+		t.Errorf("unexpected repair: %+v", result.Repairs[0])
+	}
+	if len(result.LLMCalls) != 3 {
+		t.Errorf("expected the repair call to be counted, got %d calls", len(result.LLMCalls))
+	}
+}
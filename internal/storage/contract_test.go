@@ -0,0 +1,49 @@
+package storage_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/1psychoQAQ/verdict-agent/internal/storage/storagetest"
+)
+
+func TestMemoryRepositoryContract(t *testing.T) {
+	storagetest.TestRepository(t, func(t *testing.T) storage.Repository {
+		return storage.NewMemoryRepository()
+	})
+}
+
+func TestSQLiteRepositoryContract(t *testing.T) {
+	storagetest.TestRepository(t, func(t *testing.T) storage.Repository {
+		path := filepath.Join(t.TempDir(), "verdict.db")
+		repo, err := storage.NewSQLiteRepository(context.Background(), "sqlite://"+path)
+		if err != nil {
+			t.Fatalf("NewSQLiteRepository() error = %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
+
+// TestPostgresRepositoryContract runs against the database in
+// TEST_DATABASE_URL, migrating it first
+func TestPostgresRepositoryContract(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	storagetest.TestRepository(t, func(t *testing.T) storage.Repository {
+		repo, err := storage.NewPostgresRepositoryWithConfig(context.Background(), &storage.Config{
+			DatabaseURL: url,
+			AutoMigrate: true,
+		})
+		if err != nil {
+			t.Fatalf("NewPostgresRepositoryWithConfig() error = %v", err)
+		}
+		t.Cleanup(func() { repo.Close() })
+		return repo
+	})
+}
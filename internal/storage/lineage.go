@@ -0,0 +1,38 @@
+package storage
+
+import "fmt"
+
+// validateSupersedes rejects a decision that supersedes itself, which would
+// make its lineage a cycle
+func validateSupersedes(d *Decision) error {
+	if d.Supersedes != nil && *d.Supersedes == d.ID {
+		return fmt.Errorf("decision %s cannot supersede itself", d.ID)
+	}
+	return nil
+}
+
+// lineageQuery returns a query for the supersession chain through the
+// decision whose ID is bound to param, oldest first. The chain is followed
+// back through supersedes and forward through the decisions that point at
+// each link.
+func lineageQuery(cols, param string) string {
+	return `
+		WITH RECURSIVE
+			ancestors(id, supersedes, depth) AS (
+				SELECT id, supersedes, 0 FROM decisions WHERE id = ` + param + `
+				UNION ALL
+				SELECT p.id, p.supersedes, a.depth - 1 FROM decisions p JOIN ancestors a ON p.id = a.supersedes
+			),
+			descendants(id, depth) AS (
+				SELECT id, 0 FROM decisions WHERE id = ` + param + `
+				UNION ALL
+				SELECT c.id, s.depth + 1 FROM decisions c JOIN descendants s ON c.supersedes = s.id
+			),
+			chain(id, depth) AS (
+				SELECT id, depth FROM ancestors
+				UNION
+				SELECT id, depth FROM descendants
+			)
+		SELECT ` + cols + ` FROM chain l JOIN decisions d ON d.id = l.id
+		ORDER BY l.depth`
+}
@@ -0,0 +1,242 @@
+package storage
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Page sizes for ListDecisions and ListUserHistory
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListOptions filters, orders and pages ListDecisions and ListUserHistory.
+// Zero values leave a filter unset.
+type ListOptions struct {
+	UserID    *uuid.UUID // Decisions requested by this user (ListDecisions only)
+	From      time.Time  // Created at or after
+	To        time.Time  // Created before
+	Language  string     // Input language: "en" or "zh"
+	IsFinal   *bool      // Decision is final
+	MinScore  *float64   // Completion score range of the history entry;
+	MaxScore  *float64   // decisions without one never match
+	Ascending bool       // Oldest first; newest first by default
+	Cursor    string     // NextCursor of the previous page
+	Limit     int        // DefaultListLimit if zero, capped at MaxListLimit
+}
+
+// DecisionPage is one page of ListDecisions
+type DecisionPage struct {
+	Decisions  []*Decision
+	NextCursor string // Empty on the last page
+}
+
+// HistoryPage is one page of ListUserHistory
+type HistoryPage struct {
+	Entries    []*UserHistory
+	NextCursor string // Empty on the last page
+}
+
+// limit returns the page size
+func (o ListOptions) limit() int {
+	switch {
+	case o.Limit <= 0:
+		return DefaultListLimit
+	case o.Limit > MaxListLimit:
+		return MaxListLimit
+	default:
+		return o.Limit
+	}
+}
+
+// matches reports whether a record passes the filters. score is the
+// completion score, or nil if there is none.
+func (o ListOptions) matches(createdAt time.Time, language string, isFinal bool, score *float64) bool {
+	if !o.From.IsZero() && createdAt.Before(o.From) {
+		return false
+	}
+	if !o.To.IsZero() && !createdAt.Before(o.To) {
+		return false
+	}
+	if o.Language != "" && language != o.Language {
+		return false
+	}
+	if o.IsFinal != nil && isFinal != *o.IsFinal {
+		return false
+	}
+	if o.MinScore != nil && (score == nil || *score < *o.MinScore) {
+		return false
+	}
+	if o.MaxScore != nil && (score == nil || *score > *o.MaxScore) {
+		return false
+	}
+	return true
+}
+
+// cursor is the position of the last record of a page. Records are ordered by
+// creation time, with the ID breaking ties.
+type cursor struct {
+	createdAt time.Time
+	id        uuid.UUID
+}
+
+func (c cursor) String() string {
+	raw := c.createdAt.UTC().Format(time.RFC3339Nano) + "," + c.id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// parseCursor decodes a cursor produced by cursor.String. An empty string is
+// the start of the listing and returns nil.
+func parseCursor(s string) (*cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor{createdAt: createdAt, id: parsed}, nil
+}
+
+// follows reports whether a record comes after c in the listing order
+func (c *cursor) follows(createdAt time.Time, id uuid.UUID, ascending bool) bool {
+	if c == nil {
+		return true
+	}
+	cmp := compareListed(createdAt, id, c.createdAt, c.id)
+	if ascending {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// decisionPosition and historyPosition return a record's position in a listing
+func decisionPosition(d *Decision) (time.Time, uuid.UUID)   { return d.CreatedAt, d.ID }
+func historyPosition(h *UserHistory) (time.Time, uuid.UUID) { return h.CreatedAt, h.ID }
+
+// compareListed orders records by creation time, then by ID
+func compareListed(aCreated time.Time, aID uuid.UUID, bCreated time.Time, bID uuid.UUID) int {
+	if cmp := aCreated.Compare(bCreated); cmp != 0 {
+		return cmp
+	}
+	return strings.Compare(aID.String(), bID.String())
+}
+
+// listColumns names the columns a SQL listing filters and orders on
+type listColumns struct {
+	createdAt, id, userID, language, isFinal, score string
+}
+
+var (
+	decisionListColumns = listColumns{
+		createdAt: "d.created_at",
+		id:        "d.id",
+		userID:    "d.user_id",
+		language:  "d.language",
+		isFinal:   "d.is_final",
+		score:     "(SELECT MAX(s.score) FROM user_history s WHERE s.decision_id = d.id)",
+	}
+	historyListColumns = listColumns{
+		createdAt: "h.created_at",
+		id:        "h.id",
+		userID:    "h.user_id",
+		language:  "d.language",
+		isFinal:   "d.is_final",
+		score:     "h.score",
+	}
+)
+
+// listQuery builds the WHERE, ORDER BY and LIMIT clauses of a listing for the
+// SQL backends
+type listQuery struct {
+	cols  listColumns
+	bind  func(n int) string // Placeholder for the nth argument
+	time  func(t time.Time) any
+	where []string
+	args  []any
+}
+
+// add appends a condition, replacing each ? in cond with the placeholder of
+// the matching argument
+func (q *listQuery) add(cond string, args ...any) {
+	for _, arg := range args {
+		q.args = append(q.args, arg)
+		cond = strings.Replace(cond, "?", q.bind(len(q.args)), 1)
+	}
+	q.where = append(q.where, cond)
+}
+
+// build returns the clauses following the FROM clause for opts, fetching one
+// row more than the page size to tell whether another page follows
+func (q *listQuery) build(opts ListOptions) (string, error) {
+	after, err := parseCursor(opts.Cursor)
+	if err != nil {
+		return "", err
+	}
+
+	c := q.cols
+	if opts.UserID != nil {
+		q.add(c.userID+" = ?", *opts.UserID)
+	}
+	if !opts.From.IsZero() {
+		q.add(c.createdAt+" >= ?", q.time(opts.From))
+	}
+	if !opts.To.IsZero() {
+		q.add(c.createdAt+" < ?", q.time(opts.To))
+	}
+	if opts.Language != "" {
+		q.add(c.language+" = ?", opts.Language)
+	}
+	if opts.IsFinal != nil {
+		q.add(c.isFinal+" = ?", *opts.IsFinal)
+	}
+	if opts.MinScore != nil {
+		q.add(c.score+" >= ?", *opts.MinScore)
+	}
+	if opts.MaxScore != nil {
+		q.add(c.score+" <= ?", *opts.MaxScore)
+	}
+
+	dir, op := "DESC", "<"
+	if opts.Ascending {
+		dir, op = "ASC", ">"
+	}
+	if after != nil {
+		q.add(fmt.Sprintf("(%s, %s) %s (?, ?)", c.createdAt, c.id, op), q.time(after.createdAt), after.id)
+	}
+
+	var sb strings.Builder
+	if len(q.where) > 0 {
+		sb.WriteString("WHERE " + strings.Join(q.where, " AND "))
+	}
+	fmt.Fprintf(&sb, " ORDER BY %s %s, %s %s LIMIT %d", c.createdAt, dir, c.id, dir, opts.limit()+1)
+	return sb.String(), nil
+}
+
+// nextCursor trims a listing fetched with one extra row to the page size and
+// returns the cursor of the following page, if there is one
+func nextCursor[T any](items []T, limit int, key func(T) (time.Time, uuid.UUID)) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	createdAt, id := key(items[limit-1])
+	return items, cursor{createdAt: createdAt, id: id}.String()
+}
@@ -2,8 +2,9 @@ package storage
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"maps"
+	"sort"
 	"sync"
 	"time"
 
@@ -11,46 +12,8 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
-// User represents a user account
-type User struct {
-	ID           uuid.UUID `json:"id"`
-	Username     string    `json:"username"`
-	PasswordHash string    `json:"-"`
-	CreatedAt    time.Time `json:"created_at"`
-}
-
-// UserHistory represents a user's decision history entry
-type UserHistory struct {
-	ID              uuid.UUID              `json:"id"`
-	UserID          uuid.UUID              `json:"user_id"`
-	DecisionID      uuid.UUID              `json:"decision_id"`
-	Input           string                 `json:"input"`
-	Verdict         json.RawMessage        `json:"verdict"`
-	Todo            string                 `json:"todo"`
-	DoneCriteria    []DoneCriterion        `json:"done_criteria"`
-	Score           float64                `json:"score"`
-	UploadedContent []UploadedContent      `json:"uploaded_content,omitempty"`
-	CreatedAt       time.Time              `json:"created_at"`
-	UpdatedAt       time.Time              `json:"updated_at"`
-}
-
-// DoneCriterion represents a single done criterion with completion status
-type DoneCriterion struct {
-	Index     int    `json:"index"`
-	Text      string `json:"text"`
-	Completed bool   `json:"completed"`
-}
-
-// UploadedContent represents user-uploaded proof/evidence
-type UploadedContent struct {
-	ID          uuid.UUID `json:"id"`
-	FileName    string    `json:"file_name"`
-	ContentType string    `json:"content_type"`
-	URL         string    `json:"url"`
-	UploadedAt  time.Time `json:"uploaded_at"`
-}
-
-// MemoryRepository implements Repository interface with in-memory storage
+// MemoryRepository implements the Repository and UserRepository interfaces with
+// in-memory storage
 type MemoryRepository struct {
 	mu        sync.RWMutex
 	decisions map[uuid.UUID]*Decision
@@ -59,6 +22,16 @@ type MemoryRepository struct {
 	usernames map[string]uuid.UUID
 	history   map[uuid.UUID]*UserHistory // keyed by history ID
 	sessions  map[string]uuid.UUID       // token -> user ID
+	jobs      map[uuid.UUID]*Job
+	states    map[uuid.UUID][]*StateSnapshot // keyed by decision ID, oldest first
+	tasks     map[uuid.UUID]*Task
+	exports   map[githubExportKey]*GitHubExport
+}
+
+// githubExportKey identifies the GitHub export of a decision to a repository
+type githubExportKey struct {
+	decisionID uuid.UUID
+	repository string
 }
 
 // NewMemoryRepository creates a new in-memory repository
@@ -70,6 +43,10 @@ func NewMemoryRepository() *MemoryRepository {
 		usernames: make(map[string]uuid.UUID),
 		history:   make(map[uuid.UUID]*UserHistory),
 		sessions:  make(map[string]uuid.UUID),
+		jobs:      make(map[uuid.UUID]*Job),
+		states:    make(map[uuid.UUID][]*StateSnapshot),
+		tasks:     make(map[uuid.UUID]*Task),
+		exports:   make(map[githubExportKey]*GitHubExport),
 	}
 }
 
@@ -84,14 +61,72 @@ func (r *MemoryRepository) CreateDecision(ctx context.Context, d *Decision) erro
 	if d.ID == uuid.Nil {
 		d.ID = uuid.New()
 	}
+	if _, exists := r.decisions[d.ID]; exists {
+		return fmt.Errorf("decision %s %w", d.ID, ErrConflict)
+	}
+	if err := r.checkSupersedes(d); err != nil {
+		return err
+	}
 	if d.CreatedAt.IsZero() {
 		d.CreatedAt = time.Now()
 	}
 
-	r.decisions[d.ID] = d
+	r.storeDecision(d)
+	return nil
+}
+
+// checkSupersedes enforces what the SQL schemas do for a new decision's
+// Supersedes: the decision exists, is not the new one, and has no successor
+func (r *MemoryRepository) checkSupersedes(d *Decision) error {
+	if d.Supersedes == nil {
+		return nil
+	}
+	if err := validateSupersedes(d); err != nil {
+		return err
+	}
+	prev, ok := r.decisions[*d.Supersedes]
+	if !ok {
+		return fmt.Errorf("superseded decision %w", ErrNotFound)
+	}
+	if prev.SupersededBy != nil {
+		return fmt.Errorf("successor of decision %s %w", prev.ID, ErrConflict)
+	}
 	return nil
 }
 
+// storeDecision stores a checked decision and records it as the successor of
+// the one it supersedes. That decision is replaced by a copy rather than
+// changed, since callers may hold it.
+func (r *MemoryRepository) storeDecision(d *Decision) {
+	r.decisions[d.ID] = d
+	if d.Supersedes != nil {
+		prev := *r.decisions[*d.Supersedes]
+		prev.SupersededBy = &d.ID
+		r.decisions[prev.ID] = &prev
+	}
+}
+
+// GetDecisionLineage returns the supersession chain through a decision,
+// oldest first
+func (r *MemoryRepository) GetDecisionLineage(ctx context.Context, id uuid.UUID) ([]*Decision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.decisions[id]
+	if !ok {
+		return nil, fmt.Errorf("decision %w", ErrNotFound)
+	}
+	for d.Supersedes != nil {
+		d = r.decisions[*d.Supersedes]
+	}
+	lineage := []*Decision{d}
+	for d.SupersededBy != nil {
+		d = r.decisions[*d.SupersededBy]
+		lineage = append(lineage, d)
+	}
+	return lineage, nil
+}
+
 // GetDecision retrieves a decision by ID
 func (r *MemoryRepository) GetDecision(ctx context.Context, id uuid.UUID) (*Decision, error) {
 	r.mu.RLock()
@@ -99,11 +134,100 @@ func (r *MemoryRepository) GetDecision(ctx context.Context, id uuid.UUID) (*Deci
 
 	d, ok := r.decisions[id]
 	if !ok {
-		return nil, fmt.Errorf("decision not found")
+		return nil, fmt.Errorf("decision %w", ErrNotFound)
 	}
 	return d, nil
 }
 
+// ListDecisions returns a page of decisions matching opts
+func (r *MemoryRepository) ListDecisions(ctx context.Context, opts ListOptions) (*DecisionPage, error) {
+	after, err := parseCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Completion score of each decision's history entry
+	scores := make(map[uuid.UUID]*float64)
+	for _, h := range r.history {
+		if s, ok := scores[h.DecisionID]; !ok || h.Score > *s {
+			score := h.Score
+			scores[h.DecisionID] = &score
+		}
+	}
+
+	var result []*Decision
+	for _, d := range r.decisions {
+		if opts.UserID != nil && (d.UserID == nil || *d.UserID != *opts.UserID) {
+			continue
+		}
+		if !opts.matches(d.CreatedAt, d.Language, d.IsFinal, scores[d.ID]) ||
+			!after.follows(d.CreatedAt, d.ID, opts.Ascending) {
+			continue
+		}
+		result = append(result, d)
+	}
+	sortListed(result, opts.Ascending, decisionPosition)
+
+	page := &DecisionPage{}
+	page.Decisions, page.NextCursor = nextCursor(result, opts.limit(), decisionPosition)
+	return page, nil
+}
+
+// SearchDecisions returns the decisions whose text or todo contain every term
+// of query, best match first
+func (r *MemoryRepository) SearchDecisions(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	todos := make(map[uuid.UUID]string)
+	for _, t := range r.todos {
+		todos[t.DecisionID] += t.Content + "\n"
+	}
+
+	var results []SearchResult
+	for _, d := range r.decisions {
+		if opts.UserID != nil && (d.UserID == nil || *d.UserID != *opts.UserID) {
+			continue
+		}
+		if rank := searchRank(terms, searchText(d), todos[d.ID]); rank > 0 {
+			results = append(results, SearchResult{Decision: d, Rank: rank})
+		}
+	}
+	return sortSearchResults(results, opts.limit()), nil
+}
+
+// FindSimilarDecisions returns the decisions whose input resembles input,
+// most similar first
+func (r *MemoryRepository) FindSimilarDecisions(ctx context.Context, input string, opts SimilarOptions) ([]SimilarDecision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidates := make([]*Decision, 0, len(r.decisions))
+	for _, d := range r.decisions {
+		candidates = append(candidates, d)
+	}
+	return similarDecisions(input, candidates, opts), nil
+}
+
+// sortListed sorts records into listing order
+func sortListed[T any](items []T, ascending bool, key func(T) (time.Time, uuid.UUID)) {
+	sort.Slice(items, func(a, b int) bool {
+		aCreated, aID := key(items[a])
+		bCreated, bID := key(items[b])
+		cmp := compareListed(aCreated, aID, bCreated, bID)
+		if ascending {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+}
+
 // CreateTodo stores a new todo
 func (r *MemoryRepository) CreateTodo(ctx context.Context, t *Todo) error {
 	if t == nil {
@@ -115,6 +239,9 @@ func (r *MemoryRepository) CreateTodo(ctx context.Context, t *Todo) error {
 	if t.ID == uuid.Nil {
 		t.ID = uuid.New()
 	}
+	if _, exists := r.todos[t.ID]; exists {
+		return fmt.Errorf("todo %s %w", t.ID, ErrConflict)
+	}
 	if t.CreatedAt.IsZero() {
 		t.CreatedAt = time.Now()
 	}
@@ -130,7 +257,7 @@ func (r *MemoryRepository) GetTodo(ctx context.Context, id uuid.UUID) (*Todo, er
 
 	t, ok := r.todos[id]
 	if !ok {
-		return nil, fmt.Errorf("todo not found")
+		return nil, fmt.Errorf("todo %w", ErrNotFound)
 	}
 	return t, nil
 }
@@ -145,7 +272,7 @@ func (r *MemoryRepository) GetTodoByDecisionID(ctx context.Context, decisionID u
 			return t, nil
 		}
 	}
-	return nil, fmt.Errorf("todo not found for decision")
+	return nil, fmt.Errorf("todo %w for decision", ErrNotFound)
 }
 
 // SaveArtifacts saves both decision and todo atomically
@@ -162,6 +289,16 @@ func (r *MemoryRepository) SaveArtifacts(ctx context.Context, d *Decision, t *To
 	if t.ID == uuid.Nil {
 		t.ID = uuid.New()
 	}
+	// Check both before storing either, like a rolled back transaction
+	if _, exists := r.decisions[d.ID]; exists {
+		return fmt.Errorf("decision %s %w", d.ID, ErrConflict)
+	}
+	if _, exists := r.todos[t.ID]; exists {
+		return fmt.Errorf("todo %s %w", t.ID, ErrConflict)
+	}
+	if err := r.checkSupersedes(d); err != nil {
+		return err
+	}
 
 	now := time.Now()
 	if d.CreatedAt.IsZero() {
@@ -172,12 +309,257 @@ func (r *MemoryRepository) SaveArtifacts(ctx context.Context, d *Decision, t *To
 	}
 
 	t.DecisionID = d.ID
-	r.decisions[d.ID] = d
+	r.storeDecision(d)
 	r.todos[t.ID] = t
 
 	return nil
 }
 
+// CreateStateSnapshot stores a new version of a decision's state
+func (r *MemoryRepository) CreateStateSnapshot(ctx context.Context, s *StateSnapshot) error {
+	if s == nil {
+		return fmt.Errorf("state snapshot cannot be nil")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.states[s.DecisionID] {
+		if existing.Version == s.Version {
+			return fmt.Errorf("state snapshot version %d %w", s.Version, ErrConflict)
+		}
+	}
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+
+	stored := *s
+	snapshots := append(r.states[s.DecisionID], &stored)
+	sort.Slice(snapshots, func(a, b int) bool {
+		return snapshots[a].Version < snapshots[b].Version
+	})
+	r.states[s.DecisionID] = snapshots
+	return nil
+}
+
+// ListStateSnapshots retrieves every version of a decision's state, oldest first
+func (r *MemoryRepository) ListStateSnapshots(ctx context.Context, decisionID uuid.UUID) ([]*StateSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*StateSnapshot, len(r.states[decisionID]))
+	for i, s := range r.states[decisionID] {
+		snapshot := *s
+		result[i] = &snapshot
+	}
+	return result, nil
+}
+
+// CreateTasks stores new tasks, all or none
+func (r *MemoryRepository) CreateTasks(ctx context.Context, tasks []*Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	type slot struct {
+		decisionID      uuid.UUID
+		phase, position int
+	}
+	taken := make(map[slot]bool)
+	for _, t := range r.tasks {
+		taken[slot{t.DecisionID, t.PhaseIndex, t.Position}] = true
+	}
+	ids := make(map[uuid.UUID]bool, len(tasks))
+	now := time.Now()
+	for _, t := range tasks {
+		if err := prepareNewTask(t, now); err != nil {
+			return err
+		}
+		s := slot{t.DecisionID, t.PhaseIndex, t.Position}
+		if _, exists := r.tasks[t.ID]; exists || ids[t.ID] || taken[s] {
+			return fmt.Errorf("task %s %w", t.ID, ErrConflict)
+		}
+		taken[s] = true
+		ids[t.ID] = true
+	}
+
+	for _, t := range tasks {
+		stored := *t
+		r.tasks[t.ID] = &stored
+	}
+	return nil
+}
+
+// ListTasks retrieves the tasks of a decision by phase, then position
+func (r *MemoryRepository) ListTasks(ctx context.Context, decisionID uuid.UUID) ([]*Task, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := []*Task{}
+	for _, t := range r.tasks {
+		if t.DecisionID == decisionID {
+			task := *t
+			result = append(result, &task)
+		}
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].PhaseIndex != result[b].PhaseIndex {
+			return result[a].PhaseIndex < result[b].PhaseIndex
+		}
+		return result[a].Position < result[b].Position
+	})
+	return result, nil
+}
+
+// UpdateTask replaces the status, notes and completion time of a task
+func (r *MemoryRepository) UpdateTask(ctx context.Context, t *Task) error {
+	if t == nil {
+		return fmt.Errorf("task cannot be nil")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.tasks[t.ID]
+	if !ok {
+		return fmt.Errorf("task %w", ErrNotFound)
+	}
+	if err := touchTask(t, time.Now()); err != nil {
+		return err
+	}
+
+	updated := *stored
+	updated.Status = t.Status
+	updated.Notes = t.Notes
+	updated.UpdatedAt = t.UpdatedAt
+	updated.CompletedAt = t.CompletedAt
+	r.tasks[t.ID] = &updated
+	return nil
+}
+
+// GetGitHubExport retrieves the GitHub export of a decision to a repository
+func (r *MemoryRepository) GetGitHubExport(ctx context.Context, decisionID uuid.UUID, repository string) (*GitHubExport, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.exports[githubExportKey{decisionID, repository}]
+	if !ok {
+		return nil, fmt.Errorf("github export %w", ErrNotFound)
+	}
+	return copyGitHubExport(e), nil
+}
+
+// SaveGitHubExport creates or replaces the GitHub export of a decision to a
+// repository
+func (r *MemoryRepository) SaveGitHubExport(ctx context.Context, e *GitHubExport) error {
+	if e == nil {
+		return fmt.Errorf("github export cannot be nil")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := githubExportKey{e.DecisionID, e.Repository}
+	e.UpdatedAt = time.Now()
+	if prev, ok := r.exports[key]; ok {
+		e.CreatedAt = prev.CreatedAt
+	} else if e.CreatedAt.IsZero() {
+		e.CreatedAt = e.UpdatedAt
+	}
+	r.exports[key] = copyGitHubExport(e)
+	return nil
+}
+
+// copyGitHubExport returns a copy of e with its own, non-nil mappings
+func copyGitHubExport(e *GitHubExport) *GitHubExport {
+	c := *e
+	c.Milestones = make(map[int]int, len(e.Milestones))
+	maps.Copy(c.Milestones, e.Milestones)
+	c.Issues = make(map[string]int, len(e.Issues))
+	maps.Copy(c.Issues, e.Issues)
+	return &c
+}
+
+// CreateJob stores a new job
+func (r *MemoryRepository) CreateJob(ctx context.Context, j *Job) error {
+	if j == nil {
+		return fmt.Errorf("job cannot be nil")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	if _, exists := r.jobs[j.ID]; exists {
+		return fmt.Errorf("job %s %w", j.ID, ErrConflict)
+	}
+	if j.Status == "" {
+		j.Status = JobQueued
+	}
+	now := time.Now()
+	if j.CreatedAt.IsZero() {
+		j.CreatedAt = now
+	}
+	j.UpdatedAt = now
+
+	stored := *j
+	r.jobs[j.ID] = &stored
+	return nil
+}
+
+// GetJob retrieves a job by ID
+func (r *MemoryRepository) GetJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	j, ok := r.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job %w", ErrNotFound)
+	}
+	// Return a copy so workers and handlers never share mutable state
+	job := *j
+	return &job, nil
+}
+
+// UpdateJob replaces the stored state of an existing job
+func (r *MemoryRepository) UpdateJob(ctx context.Context, j *Job) error {
+	if j == nil {
+		return fmt.Errorf("job cannot be nil")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[j.ID]; !ok {
+		return fmt.Errorf("job %w", ErrNotFound)
+	}
+
+	j.UpdatedAt = time.Now()
+	stored := *j
+	r.jobs[j.ID] = &stored
+	return nil
+}
+
+// ListJobsByStatus retrieves all jobs in any of the given statuses, oldest first
+func (r *MemoryRepository) ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*Job
+	for _, j := range r.jobs {
+		for _, s := range statuses {
+			if j.Status == s {
+				job := *j
+				result = append(result, &job)
+				break
+			}
+		}
+	}
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].CreatedAt.Before(result[b].CreatedAt)
+	})
+	return result, nil
+}
+
 // Ping checks repository health
 func (r *MemoryRepository) Ping(ctx context.Context) error {
 	return nil
@@ -195,19 +577,18 @@ func (r *MemoryRepository) CreateUser(ctx context.Context, username, password st
 
 	// Check if username exists
 	if _, exists := r.usernames[username]; exists {
-		return nil, fmt.Errorf("username already exists")
+		return nil, fmt.Errorf("username %w", ErrConflict)
 	}
 
-	// Hash password
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hash, err := hashPassword(password)
 	if err != nil {
-		return nil, fmt.Errorf("failed to hash password: %w", err)
+		return nil, err
 	}
 
 	user := &User{
 		ID:           uuid.New(),
 		Username:     username,
-		PasswordHash: string(hash),
+		PasswordHash: hash,
 		CreatedAt:    time.Now(),
 	}
 
@@ -224,7 +605,7 @@ func (r *MemoryRepository) GetUserByUsername(ctx context.Context, username strin
 
 	userID, ok := r.usernames[username]
 	if !ok {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("user %w", ErrNotFound)
 	}
 
 	return r.users[userID], nil
@@ -237,7 +618,7 @@ func (r *MemoryRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*User
 
 	user, ok := r.users[id]
 	if !ok {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("user %w", ErrNotFound)
 	}
 
 	return user, nil
@@ -245,8 +626,7 @@ func (r *MemoryRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*User
 
 // ValidatePassword checks if password matches user's hash
 func (r *MemoryRepository) ValidatePassword(user *User, password string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
-	return err == nil
+	return checkPassword(user, password)
 }
 
 // === Session Management ===
@@ -268,12 +648,12 @@ func (r *MemoryRepository) GetUserBySession(ctx context.Context, token string) (
 
 	userID, ok := r.sessions[token]
 	if !ok {
-		return nil, fmt.Errorf("invalid session")
+		return nil, fmt.Errorf("session %w", ErrNotFound)
 	}
 
 	user, ok := r.users[userID]
 	if !ok {
-		return nil, fmt.Errorf("user not found")
+		return nil, fmt.Errorf("user %w", ErrNotFound)
 	}
 
 	return user, nil
@@ -300,11 +680,15 @@ func (r *MemoryRepository) CreateHistory(ctx context.Context, h *UserHistory) er
 	if h.ID == uuid.Nil {
 		h.ID = uuid.New()
 	}
+	if _, exists := r.history[h.ID]; exists {
+		return fmt.Errorf("history %s %w", h.ID, ErrConflict)
+	}
 	now := time.Now()
 	if h.CreatedAt.IsZero() {
 		h.CreatedAt = now
 	}
 	h.UpdatedAt = now
+	defaultUploads(h.UploadedContent, now)
 
 	r.history[h.ID] = h
 	return nil
@@ -317,7 +701,7 @@ func (r *MemoryRepository) GetHistory(ctx context.Context, id uuid.UUID) (*UserH
 
 	h, ok := r.history[id]
 	if !ok {
-		return nil, fmt.Errorf("history not found")
+		return nil, fmt.Errorf("history %w", ErrNotFound)
 	}
 	return h, nil
 }
@@ -332,10 +716,10 @@ func (r *MemoryRepository) GetHistoryByDecisionID(ctx context.Context, decisionI
 			return h, nil
 		}
 	}
-	return nil, fmt.Errorf("history not found for decision")
+	return nil, fmt.Errorf("history %w for decision", ErrNotFound)
 }
 
-// GetUserHistory retrieves all history entries for a user
+// GetUserHistory retrieves all history entries for a user, newest first
 func (r *MemoryRepository) GetUserHistory(ctx context.Context, userID uuid.UUID) ([]*UserHistory, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -346,9 +730,45 @@ func (r *MemoryRepository) GetUserHistory(ctx context.Context, userID uuid.UUID)
 			result = append(result, h)
 		}
 	}
+	sort.Slice(result, func(a, b int) bool {
+		return result[a].CreatedAt.After(result[b].CreatedAt)
+	})
 	return result, nil
 }
 
+// ListUserHistory returns a page of a user's history entries matching opts
+func (r *MemoryRepository) ListUserHistory(ctx context.Context, userID uuid.UUID, opts ListOptions) (*HistoryPage, error) {
+	after, err := parseCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*UserHistory
+	for _, h := range r.history {
+		if h.UserID != userID {
+			continue
+		}
+		var language string
+		var isFinal bool
+		if d, ok := r.decisions[h.DecisionID]; ok {
+			language, isFinal = d.Language, d.IsFinal
+		}
+		score := h.Score
+		if !opts.matches(h.CreatedAt, language, isFinal, &score) ||
+			!after.follows(h.CreatedAt, h.ID, opts.Ascending) {
+			continue
+		}
+		result = append(result, h)
+	}
+	sortListed(result, opts.Ascending, historyPosition)
+
+	page := &HistoryPage{}
+	page.Entries, page.NextCursor = nextCursor(result, opts.limit(), historyPosition)
+	return page, nil
+}
+
 // UpdateHistory updates a history entry
 func (r *MemoryRepository) UpdateHistory(ctx context.Context, h *UserHistory) error {
 	if h == nil {
@@ -358,14 +778,27 @@ func (r *MemoryRepository) UpdateHistory(ctx context.Context, h *UserHistory) er
 	defer r.mu.Unlock()
 
 	if _, ok := r.history[h.ID]; !ok {
-		return fmt.Errorf("history not found")
+		return fmt.Errorf("history %w", ErrNotFound)
 	}
 
 	h.UpdatedAt = time.Now()
+	defaultUploads(h.UploadedContent, h.UpdatedAt)
 	r.history[h.ID] = h
 	return nil
 }
 
+// defaultUploads assigns IDs and upload times the way the SQL backends do
+func defaultUploads(uploads []UploadedContent, now time.Time) {
+	for i := range uploads {
+		if uploads[i].ID == uuid.Nil {
+			uploads[i].ID = uuid.New()
+		}
+		if uploads[i].UploadedAt.IsZero() {
+			uploads[i].UploadedAt = now
+		}
+	}
+}
+
 // UpdateDoneCriteria updates the done criteria and recalculates score
 func (r *MemoryRepository) UpdateDoneCriteria(ctx context.Context, historyID uuid.UUID, criteria []DoneCriterion) (*UserHistory, error) {
 	r.mu.Lock()
@@ -373,7 +806,7 @@ func (r *MemoryRepository) UpdateDoneCriteria(ctx context.Context, historyID uui
 
 	h, ok := r.history[historyID]
 	if !ok {
-		return nil, fmt.Errorf("history not found")
+		return nil, fmt.Errorf("history %w", ErrNotFound)
 	}
 
 	h.DoneCriteria = criteria
@@ -383,6 +816,20 @@ func (r *MemoryRepository) UpdateDoneCriteria(ctx context.Context, historyID uui
 	return h, nil
 }
 
+// hashPassword hashes a password for storage
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// checkPassword checks if password matches user's hash
+func checkPassword(user *User, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) == nil
+}
+
 // calculateScore calculates completion percentage
 func calculateScore(criteria []DoneCriterion) float64 {
 	if len(criteria) == 0 {
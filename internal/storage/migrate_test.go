@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/1psychoQAQ/verdict-agent/migrations"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_jobs.up.sql":      {Data: []byte("CREATE TABLE jobs ();")},
+		"001_initial.up.sql":   {Data: []byte("CREATE TABLE decisions ();")},
+		"001_initial.down.sql": {Data: []byte("DROP TABLE decisions;")},
+	}
+
+	list, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("got %d migrations, want 2", len(list))
+	}
+	if list[0].Version != 1 || list[0].Name != "initial" || list[0].Down != "DROP TABLE decisions;" {
+		t.Errorf("first migration = %+v", list[0])
+	}
+	if list[1].Version != 2 || list[1].Name != "jobs" || list[1].Down != "" {
+		t.Errorf("second migration = %+v", list[1])
+	}
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "bad file name",
+			fsys: fstest.MapFS{"001_initial.sql": {Data: []byte("SELECT 1;")}},
+		},
+		{
+			name: "down without up",
+			fsys: fstest.MapFS{"001_initial.down.sql": {Data: []byte("SELECT 1;")}},
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"001_initial.up.sql": {Data: []byte("SELECT 1;")},
+				"001_other.up.sql":   {Data: []byte("SELECT 1;")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadMigrations(tt.fsys); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	for name, fsys := range map[string]fs.FS{"postgres": migrations.FS, "sqlite": migrations.SQLiteFS()} {
+		list, err := LoadMigrations(fsys)
+		if err != nil {
+			t.Fatalf("%s: LoadMigrations() error = %v", name, err)
+		}
+		if len(list) == 0 {
+			t.Fatalf("%s: no embedded migrations", name)
+		}
+		// Versions are contiguous from 1 and every migration can be reverted
+		for i, m := range list {
+			if m.Version != i+1 {
+				t.Errorf("%s: migration %d_%s: want version %d", name, m.Version, m.Name, i+1)
+			}
+			if m.Down == "" {
+				t.Errorf("%s: migration %d_%s has no down file", name, m.Version, m.Name)
+			}
+		}
+	}
+}
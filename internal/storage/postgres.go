@@ -3,6 +3,8 @@ package storage
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,6 +23,7 @@ type Config struct {
 	MaxConns        int32         // default 10
 	MinConns        int32         // default 2
 	MaxConnLifetime time.Duration // default 1 hour
+	AutoMigrate     bool          // Apply pending migrations on connect
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository with connection pooling
@@ -30,6 +33,7 @@ func NewPostgresRepository(ctx context.Context, databaseURL string) (*PostgresRe
 		MaxConns:        10,
 		MinConns:        2,
 		MaxConnLifetime: time.Hour,
+		AutoMigrate:     true,
 	}
 	return NewPostgresRepositoryWithConfig(ctx, config)
 }
@@ -61,7 +65,19 @@ func NewPostgresRepositoryWithConfig(ctx context.Context, cfg *Config) (*Postgre
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &PostgresRepository{pool: pool}, nil
+	repo := &PostgresRepository{pool: pool}
+	if cfg.AutoMigrate {
+		migrator, err := repo.Migrator()
+		if err == nil {
+			_, err = migrator.Up(ctx)
+		}
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+	}
+
+	return repo, nil
 }
 
 // CreateDecision inserts a new decision into the database
@@ -71,23 +87,27 @@ func (r *PostgresRepository) CreateDecision(ctx context.Context, d *Decision) er
 	}
 
 	query := `
-		INSERT INTO decisions (id, input, verdict, created_at, is_final)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO decisions (id, input, verdict, created_at, is_final, language, user_id, input_tokens, output_tokens, cost_usd, providers, supersedes, content_hash, signature)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
 	// Generate UUID if not provided
 	if d.ID == uuid.Nil {
 		d.ID = uuid.New()
 	}
+	if err := validateSupersedes(d); err != nil {
+		return err
+	}
 
 	// Set created_at if not provided
 	if d.CreatedAt.IsZero() {
 		d.CreatedAt = time.Now()
 	}
 
-	_, err := r.pool.Exec(ctx, query, d.ID, d.Input, d.Verdict, d.CreatedAt, d.IsFinal)
+	_, err := r.pool.Exec(ctx, query, d.ID, d.Input, d.Verdict, d.CreatedAt, d.IsFinal, d.Language,
+		d.UserID, d.InputTokens, d.OutputTokens, d.CostUSD, d.Providers, d.Supersedes, d.ContentHash, d.Signature)
 	if err != nil {
-		return fmt.Errorf("failed to create decision: %w", err)
+		return fmt.Errorf("failed to create decision: %w", conflictError(err))
 	}
 
 	return nil
@@ -95,30 +115,237 @@ func (r *PostgresRepository) CreateDecision(ctx context.Context, d *Decision) er
 
 // GetDecision retrieves a decision by its ID
 func (r *PostgresRepository) GetDecision(ctx context.Context, id uuid.UUID) (*Decision, error) {
-	query := `
-		SELECT id, input, verdict, created_at, is_final
-		FROM decisions
-		WHERE id = $1
+	query := `SELECT ` + decisionColumns + ` FROM decisions d WHERE d.id = $1`
+
+	d, err := scanDecision(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("decision %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get decision: %w", err)
+	}
+
+	return d, nil
+}
+
+// GetDecisionLineage returns the supersession chain through a decision,
+// oldest first
+func (r *PostgresRepository) GetDecisionLineage(ctx context.Context, id uuid.UUID) ([]*Decision, error) {
+	rows, err := r.pool.Query(ctx, lineageQuery(decisionColumns, "$1"), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decision lineage: %w", err)
+	}
+	defer rows.Close()
+
+	var lineage []*Decision
+	for rows.Next() {
+		d, err := scanDecision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		lineage = append(lineage, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get decision lineage: %w", err)
+	}
+	if len(lineage) == 0 {
+		return nil, fmt.Errorf("decision %w", ErrNotFound)
+	}
+	return lineage, nil
+}
+
+// ListDecisions returns a page of decisions matching opts, using
+// idx_decisions_created_at for the ordering
+func (r *PostgresRepository) ListDecisions(ctx context.Context, opts ListOptions) (*DecisionPage, error) {
+	q := postgresListQuery(decisionListColumns)
+	clauses, err := q.build(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+decisionColumns+` FROM decisions d `+clauses, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var decisions []*Decision
+	for rows.Next() {
+		d, err := scanDecision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		decisions = append(decisions, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+
+	page := &DecisionPage{}
+	page.Decisions, page.NextCursor = nextCursor(decisions, opts.limit(), decisionPosition)
+	return page, nil
+}
+
+// SearchDecisions returns the decisions whose text or todo match query, best
+// match first. Words are matched with the english text search configuration,
+// and the whole query as a substring, which is how Chinese is found.
+func (r *PostgresRepository) SearchDecisions(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+
+	sql := `
+		SELECT ` + decisionColumns + `,
+			(ts_rank(to_tsvector('english', d.search_document), websearch_to_tsquery('english', $1))
+				+ CASE WHEN d.search_document ILIKE $2 THEN 1 ELSE 0 END)::float8 AS rank
+		FROM decisions d
+		WHERE (
+			to_tsvector('english', d.search_document) @@ websearch_to_tsquery('english', $1)
+			OR d.search_document ILIKE $2
+			OR EXISTS (
+				SELECT 1 FROM todos t
+				WHERE t.decision_id = d.id AND (
+					to_tsvector('english', t.content) @@ websearch_to_tsquery('english', $1)
+					OR t.content ILIKE $2
+				)
+			)
+		) AND ($3::uuid IS NULL OR d.user_id = $3)
+		ORDER BY rank DESC, d.created_at DESC, d.id DESC
+		LIMIT $4
 	`
+	rows, err := r.pool.Query(ctx, sql, query, likePattern(query), opts.UserID, opts.limit())
+	if err != nil {
+		return nil, fmt.Errorf("failed to search decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var rank float64
+		d, err := scanDecision(rows, &rank)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		results = append(results, SearchResult{Decision: d, Rank: rank})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search decisions: %w", err)
+	}
+	return results, nil
+}
+
+// similarCandidates is the number of trigram matches FindSimilarDecisions
+// scores for each result it returns
+const similarCandidates = 10
+
+// trigramThreshold returns the pg_trgm similarity above which decisions are
+// candidates for a minimum input similarity. pg_trgm pads words and scores
+// their trigrams differently from inputSimilarity, so the bound is looser than
+// the minimum; without it, pg_trgm's default of 0.3 would drop decisions the
+// other backends return for thresholds below about 0.3.
+func trigramThreshold(minSimilarity float64) float64 {
+	return minSimilarity / 2
+}
+
+// inputKeyExpr normalizes an input in SQL the way the input_key column of
+// decisions does. Only ASCII and common CJK punctuation are stripped, so that
+// the result does not depend on the database locale.
+func inputKeyExpr(input string) string {
+	return `btrim(regexp_replace(lower(` + input + `), '[[:space:][:punct:]，。？！、；：“”‘’（）《》【】…—]+', ' ', 'g'))`
+}
+
+// FindSimilarDecisions returns the decisions whose input resembles input,
+// most similar first. Candidates are the decisions with the same normalized
+// input or similar trigrams, found through the input_key indexes.
+func (r *PostgresRepository) FindSimilarDecisions(ctx context.Context, input string, opts SimilarOptions) ([]SimilarDecision, error) {
+	if normalizeInput(input) == "" {
+		return nil, nil
+	}
 
+	// The % operator uses the index with the threshold set for this
+	// transaction only
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+	threshold := strconv.FormatFloat(trigramThreshold(opts.MinSimilarity), 'f', -1, 64)
+	if _, err := tx.Exec(ctx, `SELECT set_config('pg_trgm.similarity_threshold', $1, true)`, threshold); err != nil {
+		return nil, fmt.Errorf("failed to set similarity threshold: %w", err)
+	}
+
+	sql := `
+		SELECT ` + decisionColumns + `
+		FROM decisions d, (SELECT ` + inputKeyExpr("$1::text") + ` AS key) q
+		WHERE (d.input_key = q.key OR d.input_key % q.key)
+			AND ($2::uuid IS NULL OR d.user_id = $2)
+		ORDER BY similarity(d.input_key, q.key) DESC, d.created_at DESC, d.id DESC
+		LIMIT $3
+	`
+	rows, err := tx.Query(ctx, sql, input, opts.UserID, opts.limit()*similarCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*Decision
+	for rows.Next() {
+		d, err := scanDecision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		candidates = append(candidates, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find similar decisions: %w", err)
+	}
+	return similarDecisions(input, candidates, opts), nil
+}
+
+// decisionColumns are the decision columns read by scanDecision
+const decisionColumns = `d.id, d.input, d.verdict, d.created_at, d.is_final, d.language, d.user_id,
+	d.input_tokens, d.output_tokens, d.cost_usd, d.providers,
+	d.supersedes, (SELECT s.id FROM decisions s WHERE s.supersedes = d.id),
+	d.content_hash, d.signature`
+
+// scanDecision scans a single decision row, followed by any extra columns
+// into extra
+func scanDecision(row pgx.Row, extra ...any) (*Decision, error) {
 	var d Decision
-	err := r.pool.QueryRow(ctx, query, id).Scan(
+	dest := []any{
 		&d.ID,
 		&d.Input,
 		&d.Verdict,
 		&d.CreatedAt,
 		&d.IsFinal,
-	)
+		&d.Language,
+		&d.UserID,
+		&d.InputTokens,
+		&d.OutputTokens,
+		&d.CostUSD,
+		&d.Providers,
+		&d.Supersedes,
+		&d.SupersededBy,
+		&d.ContentHash,
+		&d.Signature,
+	}
+	err := row.Scan(append(dest, extra...)...)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("decision not found: %w", err)
-		}
-		return nil, fmt.Errorf("failed to get decision: %w", err)
+		return nil, err
 	}
-
 	return &d, nil
 }
 
+// postgresListQuery returns a listing query builder using $n placeholders
+func postgresListQuery(cols listColumns) *listQuery {
+	return &listQuery{
+		cols: cols,
+		bind: func(n int) string { return "$" + strconv.Itoa(n) },
+		time: func(t time.Time) any { return t },
+	}
+}
+
 // CreateTodo inserts a new todo into the database
 func (r *PostgresRepository) CreateTodo(ctx context.Context, t *Todo) error {
 	if t == nil {
@@ -142,7 +369,7 @@ func (r *PostgresRepository) CreateTodo(ctx context.Context, t *Todo) error {
 
 	_, err := r.pool.Exec(ctx, query, t.ID, t.DecisionID, t.Content, t.CreatedAt)
 	if err != nil {
-		return fmt.Errorf("failed to create todo: %w", err)
+		return fmt.Errorf("failed to create todo: %w", conflictError(err))
 	}
 
 	return nil
@@ -165,7 +392,7 @@ func (r *PostgresRepository) GetTodo(ctx context.Context, id uuid.UUID) (*Todo,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("todo not found: %w", err)
+			return nil, fmt.Errorf("todo %w", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get todo: %w", err)
 	}
@@ -190,7 +417,7 @@ func (r *PostgresRepository) GetTodoByDecisionID(ctx context.Context, decisionID
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("todo not found for decision: %w", err)
+			return nil, fmt.Errorf("todo %w for decision", ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to get todo by decision ID: %w", err)
 	}
@@ -218,6 +445,9 @@ func (r *PostgresRepository) SaveArtifacts(ctx context.Context, d *Decision, t *
 	if t.ID == uuid.Nil {
 		t.ID = uuid.New()
 	}
+	if err := validateSupersedes(d); err != nil {
+		return err
+	}
 
 	// Set created_at if not provided
 	now := time.Now()
@@ -233,12 +463,13 @@ func (r *PostgresRepository) SaveArtifacts(ctx context.Context, d *Decision, t *
 
 	// Insert decision
 	decisionQuery := `
-		INSERT INTO decisions (id, input, verdict, created_at, is_final)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO decisions (id, input, verdict, created_at, is_final, language, user_id, input_tokens, output_tokens, cost_usd, providers, supersedes, content_hash, signature)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
-	_, err = tx.Exec(ctx, decisionQuery, d.ID, d.Input, d.Verdict, d.CreatedAt, d.IsFinal)
+	_, err = tx.Exec(ctx, decisionQuery, d.ID, d.Input, d.Verdict, d.CreatedAt, d.IsFinal, d.Language,
+		d.UserID, d.InputTokens, d.OutputTokens, d.CostUSD, d.Providers, d.Supersedes, d.ContentHash, d.Signature)
 	if err != nil {
-		return fmt.Errorf("failed to insert decision in transaction: %w", err)
+		return fmt.Errorf("failed to insert decision in transaction: %w", conflictError(err))
 	}
 
 	// Insert todo
@@ -248,7 +479,7 @@ func (r *PostgresRepository) SaveArtifacts(ctx context.Context, d *Decision, t *
 	`
 	_, err = tx.Exec(ctx, todoQuery, t.ID, t.DecisionID, t.Content, t.CreatedAt)
 	if err != nil {
-		return fmt.Errorf("failed to insert todo in transaction: %w", err)
+		return fmt.Errorf("failed to insert todo in transaction: %w", conflictError(err))
 	}
 
 	// Commit transaction
@@ -259,6 +490,349 @@ func (r *PostgresRepository) SaveArtifacts(ctx context.Context, d *Decision, t *
 	return nil
 }
 
+// CreateStateSnapshot inserts a new version of a decision's state
+func (r *PostgresRepository) CreateStateSnapshot(ctx context.Context, s *StateSnapshot) error {
+	if s == nil {
+		return fmt.Errorf("state snapshot cannot be nil")
+	}
+
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO state_snapshots (id, decision_id, version, state, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	_, err := r.pool.Exec(ctx, query, s.ID, s.DecisionID, s.Version, s.State, s.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create state snapshot: %w", conflictError(err))
+	}
+
+	return nil
+}
+
+// ListStateSnapshots retrieves every version of a decision's state, oldest first
+func (r *PostgresRepository) ListStateSnapshots(ctx context.Context, decisionID uuid.UUID) ([]*StateSnapshot, error) {
+	query := `
+		SELECT id, decision_id, version, state, created_at
+		FROM state_snapshots
+		WHERE decision_id = $1
+		ORDER BY version ASC
+	`
+	rows, err := r.pool.Query(ctx, query, decisionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []*StateSnapshot{}
+	for rows.Next() {
+		var s StateSnapshot
+		if err := rows.Scan(&s.ID, &s.DecisionID, &s.Version, &s.State, &s.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan state snapshot: %w", err)
+		}
+		snapshots = append(snapshots, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list state snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// CreateTasks inserts new tasks in a single transaction
+func (r *PostgresRepository) CreateTasks(ctx context.Context, tasks []*Task) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO tasks (id, decision_id, phase_index, position, text, status, notes, created_at, updated_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	now := time.Now()
+	for _, t := range tasks {
+		if err := prepareNewTask(t, now); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, query, t.ID, t.DecisionID, t.PhaseIndex, t.Position, t.Text, t.Status, t.Notes,
+			t.CreatedAt, t.UpdatedAt, t.CompletedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create task: %w", conflictError(err))
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ListTasks retrieves the tasks of a decision by phase, then position
+func (r *PostgresRepository) ListTasks(ctx context.Context, decisionID uuid.UUID) ([]*Task, error) {
+	query := `
+		SELECT id, decision_id, phase_index, position, text, status, notes, created_at, updated_at, completed_at
+		FROM tasks
+		WHERE decision_id = $1
+		ORDER BY phase_index, position
+	`
+	rows, err := r.pool.Query(ctx, query, decisionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*Task{}
+	for rows.Next() {
+		var t Task
+		err := rows.Scan(&t.ID, &t.DecisionID, &t.PhaseIndex, &t.Position, &t.Text, &t.Status, &t.Notes,
+			&t.CreatedAt, &t.UpdatedAt, &t.CompletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// UpdateTask updates the status, notes and completion time of a task
+func (r *PostgresRepository) UpdateTask(ctx context.Context, t *Task) error {
+	if t == nil {
+		return fmt.Errorf("task cannot be nil")
+	}
+	if err := touchTask(t, time.Now()); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE tasks
+		SET status = $2, notes = $3, updated_at = $4, completed_at = $5
+		WHERE id = $1
+	`
+	tag, err := r.pool.Exec(ctx, query, t.ID, t.Status, t.Notes, t.UpdatedAt, t.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("task %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+// GetGitHubExport retrieves the GitHub export of a decision to a repository
+func (r *PostgresRepository) GetGitHubExport(ctx context.Context, decisionID uuid.UUID, repository string) (*GitHubExport, error) {
+	query := `
+		SELECT decision_id, repository, tracking_issue, milestones, issues, created_at, updated_at
+		FROM github_exports
+		WHERE decision_id = $1 AND repository = $2
+	`
+	var e GitHubExport
+	err := r.pool.QueryRow(ctx, query, decisionID, repository).Scan(
+		&e.DecisionID, &e.Repository, &e.TrackingIssue, &e.Milestones, &e.Issues, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("github export %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get github export: %w", err)
+	}
+	if e.Milestones == nil {
+		e.Milestones = map[int]int{}
+	}
+	if e.Issues == nil {
+		e.Issues = map[string]int{}
+	}
+
+	return &e, nil
+}
+
+// SaveGitHubExport creates or replaces the GitHub export of a decision to a
+// repository
+func (r *PostgresRepository) SaveGitHubExport(ctx context.Context, e *GitHubExport) error {
+	if e == nil {
+		return fmt.Errorf("github export cannot be nil")
+	}
+	if e.Milestones == nil {
+		e.Milestones = map[int]int{}
+	}
+	if e.Issues == nil {
+		e.Issues = map[string]int{}
+	}
+	now := time.Now()
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = now
+	}
+	e.UpdatedAt = now
+
+	query := `
+		INSERT INTO github_exports (decision_id, repository, tracking_issue, milestones, issues, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (decision_id, repository) DO UPDATE
+		SET tracking_issue = EXCLUDED.tracking_issue, milestones = EXCLUDED.milestones,
+			issues = EXCLUDED.issues, updated_at = EXCLUDED.updated_at
+		RETURNING created_at
+	`
+	err := r.pool.QueryRow(ctx, query, e.DecisionID, e.Repository, e.TrackingIssue, e.Milestones, e.Issues,
+		e.CreatedAt, e.UpdatedAt).Scan(&e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save github export: %w", err)
+	}
+
+	return nil
+}
+
+// CreateJob inserts a new job into the database
+func (r *PostgresRepository) CreateJob(ctx context.Context, j *Job) error {
+	if j == nil {
+		return fmt.Errorf("job cannot be nil")
+	}
+
+	// Generate UUID if not provided
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	if j.Status == "" {
+		j.Status = JobQueued
+	}
+	now := time.Now()
+	if j.CreatedAt.IsZero() {
+		j.CreatedAt = now
+	}
+	j.UpdatedAt = now
+
+	query := `
+		INSERT INTO jobs (id, status, request, result, error, user_id, created_at, updated_at, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := r.pool.Exec(ctx, query, j.ID, j.Status, j.Request, nullableJSON(j.Result), j.Error, j.UserID,
+		j.CreatedAt, j.UpdatedAt, j.StartedAt, j.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", conflictError(err))
+	}
+
+	return nil
+}
+
+// GetJob retrieves a job by its ID
+func (r *PostgresRepository) GetJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	query := `
+		SELECT id, status, request, result, error, user_id, created_at, updated_at, started_at, finished_at
+		FROM jobs
+		WHERE id = $1
+	`
+
+	j, err := scanJob(r.pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("job %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return j, nil
+}
+
+// UpdateJob updates the mutable state of an existing job
+func (r *PostgresRepository) UpdateJob(ctx context.Context, j *Job) error {
+	if j == nil {
+		return fmt.Errorf("job cannot be nil")
+	}
+
+	j.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE jobs
+		SET status = $2, result = $3, error = $4, updated_at = $5, started_at = $6, finished_at = $7
+		WHERE id = $1
+	`
+	tag, err := r.pool.Exec(ctx, query, j.ID, j.Status, nullableJSON(j.Result), j.Error, j.UpdatedAt, j.StartedAt, j.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("job %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+// ListJobsByStatus retrieves all jobs in any of the given statuses, oldest first
+func (r *PostgresRepository) ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error) {
+	names := make([]string, len(statuses))
+	for i, s := range statuses {
+		names[i] = string(s)
+	}
+
+	query := `
+		SELECT id, status, request, result, error, user_id, created_at, updated_at, started_at, finished_at
+		FROM jobs
+		WHERE status = ANY($1)
+		ORDER BY created_at ASC
+	`
+	rows, err := r.pool.Query(ctx, query, names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// scanJob scans a single job row
+func scanJob(row pgx.Row) (*Job, error) {
+	var j Job
+	var result []byte
+	err := row.Scan(
+		&j.ID,
+		&j.Status,
+		&j.Request,
+		&result,
+		&j.Error,
+		&j.UserID,
+		&j.CreatedAt,
+		&j.UpdatedAt,
+		&j.StartedAt,
+		&j.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if result != nil {
+		j.Result = result
+	}
+	return &j, nil
+}
+
+// nullableJSON converts an empty JSON payload to NULL
+func nullableJSON(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return data
+}
+
 // Ping checks if the database connection is healthy
 func (r *PostgresRepository) Ping(ctx context.Context) error {
 	if err := r.pool.Ping(ctx); err != nil {
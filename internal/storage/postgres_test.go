@@ -437,3 +437,9 @@ func TestRepositoryInterface(t *testing.T) {
 	// This test verifies that PostgresRepository implements Repository interface
 	var _ Repository = (*PostgresRepository)(nil)
 }
+
+func TestUserRepositoryInterface(t *testing.T) {
+	// Both backends persist users, sessions and history
+	var _ UserRepository = (*PostgresRepository)(nil)
+	var _ UserRepository = (*MemoryRepository)(nil)
+}
@@ -0,0 +1,444 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the PostgreSQL error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// isUniqueViolation reports whether err is a unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
+
+// conflictError wraps err with ErrConflict if it is a unique constraint
+// violation, keeping the database error for the message
+func conflictError(err error) error {
+	if isUniqueViolation(err) {
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return err
+}
+
+// querier is the subset of pgxpool.Pool and pgx.Tx used by the user queries
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// === User Management ===
+
+// CreateUser creates a new user account
+func (r *PostgresRepository) CreateUser(ctx context.Context, username, password string) (*User, error) {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &User{
+		ID:           uuid.New(),
+		Username:     username,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}
+
+	query := `
+		INSERT INTO users (id, username, password_hash, created_at)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err = r.pool.Exec(ctx, query, user.ID, user.Username, user.PasswordHash, user.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("username %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserByUsername retrieves a user by username
+func (r *PostgresRepository) GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	query := `
+		SELECT id, username, password_hash, created_at
+		FROM users
+		WHERE username = $1
+	`
+	return scanUser(r.pool.QueryRow(ctx, query, username))
+}
+
+// GetUserByID retrieves a user by ID
+func (r *PostgresRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	query := `
+		SELECT id, username, password_hash, created_at
+		FROM users
+		WHERE id = $1
+	`
+	return scanUser(r.pool.QueryRow(ctx, query, id))
+}
+
+// ValidatePassword checks if password matches user's hash
+func (r *PostgresRepository) ValidatePassword(user *User, password string) bool {
+	return checkPassword(user, password)
+}
+
+// scanUser scans a single user row
+func scanUser(row pgx.Row) (*User, error) {
+	var u User
+	err := row.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.CreatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &u, nil
+}
+
+// === Session Management ===
+
+// CreateSession creates a new session token for user
+func (r *PostgresRepository) CreateSession(ctx context.Context, userID uuid.UUID) (string, error) {
+	token := uuid.New().String()
+
+	query := `
+		INSERT INTO sessions (token, user_id, created_at)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := r.pool.Exec(ctx, query, token, userID, time.Now()); err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetUserBySession retrieves user by session token
+func (r *PostgresRepository) GetUserBySession(ctx context.Context, token string) (*User, error) {
+	query := `
+		SELECT u.id, u.username, u.password_hash, u.created_at
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token = $1
+	`
+	user, err := scanUser(r.pool.QueryRow(ctx, query, token))
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("session %w", ErrNotFound)
+	}
+	return user, err
+}
+
+// DeleteSession removes a session
+func (r *PostgresRepository) DeleteSession(ctx context.Context, token string) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM sessions WHERE token = $1`, token); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// === History Management ===
+
+// CreateHistory creates a new history entry with its done criteria and uploads
+func (r *PostgresRepository) CreateHistory(ctx context.Context, h *UserHistory) error {
+	if h == nil {
+		return fmt.Errorf("history cannot be nil")
+	}
+
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	now := time.Now()
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = now
+	}
+	h.UpdatedAt = now
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		INSERT INTO user_history (id, user_id, decision_id, input, verdict, todo, score, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = tx.Exec(ctx, query, h.ID, h.UserID, h.DecisionID, h.Input, h.Verdict, h.Todo, h.Score,
+		h.CreatedAt, h.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create history: %w", conflictError(err))
+	}
+	if err := replaceDoneCriteria(ctx, tx, h.ID, h.DoneCriteria); err != nil {
+		return err
+	}
+	if err := replaceUploadedContent(ctx, tx, h.ID, h.UploadedContent); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetHistory retrieves a history entry by ID
+func (r *PostgresRepository) GetHistory(ctx context.Context, id uuid.UUID) (*UserHistory, error) {
+	return getHistory(ctx, r.pool, `WHERE id = $1`, id)
+}
+
+// GetHistoryByDecisionID retrieves history by decision ID
+func (r *PostgresRepository) GetHistoryByDecisionID(ctx context.Context, decisionID uuid.UUID) (*UserHistory, error) {
+	return getHistory(ctx, r.pool, `WHERE decision_id = $1 ORDER BY created_at LIMIT 1`, decisionID)
+}
+
+// GetUserHistory retrieves all history entries for a user, newest first
+func (r *PostgresRepository) GetUserHistory(ctx context.Context, userID uuid.UUID) ([]*UserHistory, error) {
+	histories, err := listHistory(ctx, r.pool, `WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+	return histories, nil
+}
+
+// ListUserHistory returns a page of a user's history entries matching opts,
+// using idx_user_history_user_id_created_at for the ordering
+func (r *PostgresRepository) ListUserHistory(ctx context.Context, userID uuid.UUID, opts ListOptions) (*HistoryPage, error) {
+	opts.UserID = &userID
+	q := postgresListQuery(historyListColumns)
+	clauses, err := q.build(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := listHistory(ctx, r.pool, `JOIN decisions d ON d.id = h.decision_id `+clauses, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	page := &HistoryPage{}
+	page.Entries, page.NextCursor = nextCursor(entries, opts.limit(), historyPosition)
+	return page, nil
+}
+
+// UpdateHistory updates a history entry, replacing its done criteria and uploads
+func (r *PostgresRepository) UpdateHistory(ctx context.Context, h *UserHistory) error {
+	if h == nil {
+		return fmt.Errorf("history cannot be nil")
+	}
+
+	h.UpdatedAt = time.Now()
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE user_history
+		SET input = $2, verdict = $3, todo = $4, score = $5, updated_at = $6
+		WHERE id = $1
+	`
+	tag, err := tx.Exec(ctx, query, h.ID, h.Input, h.Verdict, h.Todo, h.Score, h.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update history: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("history %w", ErrNotFound)
+	}
+	if err := replaceDoneCriteria(ctx, tx, h.ID, h.DoneCriteria); err != nil {
+		return err
+	}
+	if err := replaceUploadedContent(ctx, tx, h.ID, h.UploadedContent); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UpdateDoneCriteria updates the done criteria and recalculates score
+func (r *PostgresRepository) UpdateDoneCriteria(ctx context.Context, historyID uuid.UUID, criteria []DoneCriterion) (*UserHistory, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE user_history
+		SET score = $2, updated_at = $3
+		WHERE id = $1
+	`
+	tag, err := tx.Exec(ctx, query, historyID, calculateScore(criteria), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to update history: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("history %w", ErrNotFound)
+	}
+	if err := replaceDoneCriteria(ctx, tx, historyID, criteria); err != nil {
+		return nil, err
+	}
+
+	h, err := getHistory(ctx, tx, `WHERE id = $1`, historyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return h, nil
+}
+
+// getHistory retrieves the first history entry matching where
+func getHistory(ctx context.Context, q querier, where string, args ...any) (*UserHistory, error) {
+	histories, err := listHistory(ctx, q, where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+	if len(histories) == 0 {
+		return nil, fmt.Errorf("history %w", ErrNotFound)
+	}
+	return histories[0], nil
+}
+
+// listHistory retrieves the history entries matching where, together with
+// their done criteria and uploaded content
+func listHistory(ctx context.Context, q querier, where string, args ...any) ([]*UserHistory, error) {
+	query := `
+		SELECT h.id, h.user_id, h.decision_id, h.input, h.verdict, h.todo, h.score, h.created_at, h.updated_at
+		FROM user_history h
+	` + where
+
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var histories []*UserHistory
+	byID := make(map[uuid.UUID]*UserHistory)
+	var ids []uuid.UUID
+	for rows.Next() {
+		var h UserHistory
+		if err := rows.Scan(&h.ID, &h.UserID, &h.DecisionID, &h.Input, &h.Verdict, &h.Todo, &h.Score,
+			&h.CreatedAt, &h.UpdatedAt); err != nil {
+			return nil, err
+		}
+		h.DoneCriteria = []DoneCriterion{}
+		histories = append(histories, &h)
+		byID[h.ID] = &h
+		ids = append(ids, h.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return histories, nil
+	}
+
+	// Done criteria
+	rows, err = q.Query(ctx, `
+		SELECT history_id, idx, text, completed
+		FROM done_criteria
+		WHERE history_id = ANY($1)
+		ORDER BY history_id, idx
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var historyID uuid.UUID
+		var c DoneCriterion
+		if err := rows.Scan(&historyID, &c.Index, &c.Text, &c.Completed); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		byID[historyID].DoneCriteria = append(byID[historyID].DoneCriteria, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Uploaded content
+	rows, err = q.Query(ctx, `
+		SELECT history_id, id, file_name, content_type, url, uploaded_at
+		FROM uploaded_content
+		WHERE history_id = ANY($1)
+		ORDER BY uploaded_at
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var historyID uuid.UUID
+		var u UploadedContent
+		if err := rows.Scan(&historyID, &u.ID, &u.FileName, &u.ContentType, &u.URL, &u.UploadedAt); err != nil {
+			return nil, err
+		}
+		byID[historyID].UploadedContent = append(byID[historyID].UploadedContent, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+// replaceDoneCriteria replaces the done criteria of a history entry
+func replaceDoneCriteria(ctx context.Context, q querier, historyID uuid.UUID, criteria []DoneCriterion) error {
+	if _, err := q.Exec(ctx, `DELETE FROM done_criteria WHERE history_id = $1`, historyID); err != nil {
+		return fmt.Errorf("failed to replace done criteria: %w", err)
+	}
+
+	query := `
+		INSERT INTO done_criteria (history_id, idx, text, completed)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, c := range criteria {
+		if _, err := q.Exec(ctx, query, historyID, c.Index, c.Text, c.Completed); err != nil {
+			return fmt.Errorf("failed to insert done criterion: %w", err)
+		}
+	}
+	return nil
+}
+
+// replaceUploadedContent replaces the uploaded content of a history entry
+func replaceUploadedContent(ctx context.Context, q querier, historyID uuid.UUID, uploads []UploadedContent) error {
+	if _, err := q.Exec(ctx, `DELETE FROM uploaded_content WHERE history_id = $1`, historyID); err != nil {
+		return fmt.Errorf("failed to replace uploaded content: %w", err)
+	}
+
+	query := `
+		INSERT INTO uploaded_content (id, history_id, file_name, content_type, url, uploaded_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	for i := range uploads {
+		u := &uploads[i]
+		if u.ID == uuid.Nil {
+			u.ID = uuid.New()
+		}
+		if u.UploadedAt.IsZero() {
+			u.UploadedAt = time.Now()
+		}
+		if _, err := q.Exec(ctx, query, u.ID, historyID, u.FileName, u.ContentType, u.URL, u.UploadedAt); err != nil {
+			return fmt.Errorf("failed to insert uploaded content: %w", err)
+		}
+	}
+	return nil
+}
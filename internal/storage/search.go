@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// SearchOptions scopes SearchDecisions
+type SearchOptions struct {
+	UserID *uuid.UUID // Only decisions requested by this user
+	Limit  int        // DefaultListLimit if zero, capped at MaxListLimit
+}
+
+// SearchResult is a decision matching a search
+type SearchResult struct {
+	Decision *Decision
+	Rank     float64 // Relevance, only comparable within one search
+}
+
+// limit returns the number of results to return
+func (o SearchOptions) limit() int {
+	return ListOptions{Limit: o.Limit}.limit()
+}
+
+// searchVerdict is the searchable part of a stored verdict. Decisions store
+// the whole decision.json document, which nests the verdict; a bare verdict is
+// accepted too.
+type searchVerdict struct {
+	Ruling    string `json:"ruling"`
+	Rationale string `json:"rationale"`
+	Rejected  []struct {
+		Option string `json:"option"`
+		Reason string `json:"reason"`
+	} `json:"rejected"`
+	Verdict *searchVerdict `json:"verdict"`
+}
+
+// searchText returns the text of a decision that searches match: its input,
+// ruling, rationale and rejected options
+func searchText(d *Decision) string {
+	parts := []string{d.Input}
+	var v searchVerdict
+	if json.Unmarshal(d.Verdict, &v) == nil {
+		if v.Verdict != nil {
+			v = *v.Verdict
+		}
+		parts = append(parts, v.Ruling, v.Rationale)
+		for _, r := range v.Rejected {
+			parts = append(parts, r.Option, r.Reason)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// searchTerms splits a query into lowercase terms
+func searchTerms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// searchRank is the fallback used by backends without full-text indexes. Every
+// term must occur in the decision text or its todo, case-insensitively; the
+// rank counts the occurrences, weighting the todo half. Zero means no match.
+func searchRank(terms []string, decision, todo string) float64 {
+	if len(terms) == 0 {
+		return 0
+	}
+	decision, todo = strings.ToLower(decision), strings.ToLower(todo)
+	var rank float64
+	for _, term := range terms {
+		inDecision, inTodo := strings.Count(decision, term), strings.Count(todo, term)
+		if inDecision == 0 && inTodo == 0 {
+			return 0
+		}
+		rank += float64(inDecision) + float64(inTodo)/2
+	}
+	return rank
+}
+
+// sortSearchResults orders results best match first, newest first among equals,
+// and trims them to limit
+func sortSearchResults(results []SearchResult, limit int) []SearchResult {
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Rank != results[b].Rank {
+			return results[a].Rank > results[b].Rank
+		}
+		aCreated, aID := decisionPosition(results[a].Decision)
+		bCreated, bID := decisionPosition(results[b].Decision)
+		return compareListed(aCreated, aID, bCreated, bID) > 0
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// likePattern returns a LIKE pattern matching s anywhere, escaping wildcards
+// with a backslash
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// SimilarOptions scopes FindSimilarDecisions
+type SimilarOptions struct {
+	UserID        *uuid.UUID // Only decisions requested by this user
+	MinSimilarity float64    // Lowest similarity returned, above zero and at most 1
+	Limit         int        // DefaultListLimit if zero, capped at MaxListLimit
+}
+
+// SimilarDecision is a decision whose input resembles a new input
+type SimilarDecision struct {
+	Decision   *Decision
+	Similarity float64 // 1 for inputs that are identical once normalized
+}
+
+// limit returns the number of results to return
+func (o SimilarOptions) limit() int {
+	return ListOptions{Limit: o.Limit}.limit()
+}
+
+// normalizeInput lowercases an input and replaces each run of whitespace,
+// punctuation and symbols with a single space, so that inputs differing only
+// in case, spacing or punctuation compare equal
+func normalizeInput(s string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			space = sb.Len() > 0
+			continue
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// inputShingles returns the set of character trigrams of a normalized input.
+// Characters rather than words are shingled so that Chinese, which is not
+// separated by spaces, compares as well as English.
+func inputShingles(s string) map[string]struct{} {
+	runes := []rune(s)
+	shingles := make(map[string]struct{})
+	if len(runes) < 3 {
+		if len(runes) > 0 {
+			shingles[s] = struct{}{}
+		}
+		return shingles
+	}
+	for i := 0; i+3 <= len(runes); i++ {
+		shingles[string(runes[i:i+3])] = struct{}{}
+	}
+	return shingles
+}
+
+// inputSimilarity returns the Jaccard similarity of the shingles of two
+// inputs, from 0 (nothing in common) to 1 (identical once normalized)
+func inputSimilarity(a, b string) float64 {
+	a, b = normalizeInput(a), normalizeInput(b)
+	if a == b {
+		if a == "" {
+			return 0
+		}
+		return 1
+	}
+	sa, sb := inputShingles(a), inputShingles(b)
+	shared := 0
+	for s := range sa {
+		if _, ok := sb[s]; ok {
+			shared++
+		}
+	}
+	union := len(sa) + len(sb) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// similarDecisions scores candidate decisions against input and returns those
+// at or above opts.MinSimilarity, most similar first, newest first among
+// equals. The backends narrow the candidates, never below opts.MinSimilarity;
+// the score is always computed here so that every backend agrees on it.
+func similarDecisions(input string, candidates []*Decision, opts SimilarOptions) []SimilarDecision {
+	var results []SimilarDecision
+	for _, d := range candidates {
+		if opts.UserID != nil && (d.UserID == nil || *d.UserID != *opts.UserID) {
+			continue
+		}
+		similarity := inputSimilarity(input, d.Input)
+		if similarity > 0 && similarity >= opts.MinSimilarity {
+			results = append(results, SimilarDecision{Decision: d, Similarity: similarity})
+		}
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Similarity != results[b].Similarity {
+			return results[a].Similarity > results[b].Similarity
+		}
+		aCreated, aID := decisionPosition(results[a].Decision)
+		bCreated, bID := decisionPosition(results[b].Decision)
+		return compareListed(aCreated, aID, bCreated, bID) > 0
+	})
+	if len(results) > opts.limit() {
+		results = results[:opts.limit()]
+	}
+	return results
+}
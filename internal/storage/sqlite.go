@@ -0,0 +1,1364 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/migrations"
+	"github.com/google/uuid"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// sqliteDriver is the database/sql driver name registered by the pure-Go
+// modernc.org/sqlite driver
+const sqliteDriver = "sqlite"
+
+// IsSQLiteURL reports whether databaseURL selects the SQLite backend
+func IsSQLiteURL(databaseURL string) bool {
+	return strings.HasPrefix(databaseURL, "sqlite://")
+}
+
+// sqlitePath extracts the database file from sqlite:///abs/path or
+// sqlite://relative/path
+func sqlitePath(databaseURL string) (string, error) {
+	path := strings.TrimPrefix(databaseURL, "sqlite://")
+	if path == "" || path == "/" {
+		return "", fmt.Errorf("database path is required")
+	}
+	return path, nil
+}
+
+// SQLiteRepository implements the Repository and UserRepository interfaces
+// using a SQLite database file
+type SQLiteRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteRepository opens (creating if needed) the SQLite database named by
+// a sqlite:// URL and applies pending schema migrations
+func NewSQLiteRepository(ctx context.Context, databaseURL string) (*SQLiteRepository, error) {
+	path, err := sqlitePath(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// SQLite allows a single writer; one connection also keeps per-connection
+	// pragmas in effect and makes ":memory:" databases usable
+	db.SetMaxOpenConns(1)
+
+	for _, pragma := range []string{
+		`PRAGMA foreign_keys = ON`,
+		`PRAGMA journal_mode = WAL`,
+		`PRAGMA busy_timeout = 5000`,
+	} {
+		if _, err := db.ExecContext(ctx, pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to configure database: %w", err)
+		}
+	}
+
+	repo := &SQLiteRepository{db: db}
+	if err := repo.migrate(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	return repo, nil
+}
+
+// migrate applies pending SQLite migrations. Each runs in a transaction that
+// re-checks schema_migrations, so a second process starting at the same time
+// fails to commit instead of applying a migration twice.
+func (r *SQLiteRepository) migrate(ctx context.Context) error {
+	list, err := LoadMigrations(migrations.SQLiteFS())
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TEXT NOT NULL
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	for _, mig := range list {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		var applied int
+		err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, mig.Version).Scan(&applied)
+		if err == nil && applied == 0 {
+			if _, err = tx.ExecContext(ctx, mig.Up); err == nil {
+				_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+					mig.Version, mig.Name, formatTime(time.Now()))
+			}
+		}
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("migration %d_%s failed: %w", mig.Version, mig.Name, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+	}
+	return nil
+}
+
+// === Decisions and Todos ===
+
+// CreateDecision inserts a new decision into the database
+func (r *SQLiteRepository) CreateDecision(ctx context.Context, d *Decision) error {
+	if d == nil {
+		return fmt.Errorf("decision cannot be nil")
+	}
+	if err := insertSQLiteDecision(ctx, r.db, d); err != nil {
+		return fmt.Errorf("failed to create decision: %w", sqliteConflictError(err))
+	}
+	return nil
+}
+
+// GetDecision retrieves a decision by its ID
+func (r *SQLiteRepository) GetDecision(ctx context.Context, id uuid.UUID) (*Decision, error) {
+	query := `SELECT ` + sqliteDecisionColumns + ` FROM decisions d WHERE d.id = ?`
+
+	d, err := scanSQLiteDecision(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("decision %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get decision: %w", err)
+	}
+
+	return d, nil
+}
+
+// ListDecisions returns a page of decisions matching opts
+func (r *SQLiteRepository) ListDecisions(ctx context.Context, opts ListOptions) (*DecisionPage, error) {
+	q := sqliteListQuery(decisionListColumns)
+	clauses, err := q.build(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT `+sqliteDecisionColumns+` FROM decisions d `+clauses, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var decisions []*Decision
+	for rows.Next() {
+		d, err := scanSQLiteDecision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		decisions = append(decisions, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+
+	page := &DecisionPage{}
+	page.Decisions, page.NextCursor = nextCursor(decisions, opts.limit(), decisionPosition)
+	return page, nil
+}
+
+// SearchDecisions returns the decisions whose text or todo contain every term
+// of query, best match first. SQLite has no full-text index here: LIKE narrows
+// the candidates and they are ranked like the in-memory backend.
+func (r *SQLiteRepository) SearchDecisions(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var where []string
+	var args []any
+	if opts.UserID != nil {
+		where = append(where, "d.user_id = ?")
+		args = append(args, *opts.UserID)
+	}
+	for _, term := range terms {
+		where = append(where, `(d.input LIKE ? ESCAPE '\' OR d.verdict LIKE ? ESCAPE '\'
+			OR EXISTS (SELECT 1 FROM todos t WHERE t.decision_id = d.id AND t.content LIKE ? ESCAPE '\'))`)
+		pattern := likePattern(term)
+		args = append(args, pattern, pattern, pattern)
+	}
+	q := `SELECT ` + sqliteDecisionColumns + `,
+			COALESCE((SELECT group_concat(t.content, char(10)) FROM todos t WHERE t.decision_id = d.id), '')
+		FROM decisions d
+		WHERE ` + strings.Join(where, " AND ")
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var results []SearchResult
+	for rows.Next() {
+		var todo string
+		d, err := scanSQLiteDecision(rows, &todo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		if rank := searchRank(terms, searchText(d), todo); rank > 0 {
+			results = append(results, SearchResult{Decision: d, Rank: rank})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search decisions: %w", err)
+	}
+	return sortSearchResults(results, opts.limit()), nil
+}
+
+// FindSimilarDecisions returns the decisions whose input resembles input,
+// most similar first. Single-node databases are small enough to score every
+// decision in scope.
+func (r *SQLiteRepository) FindSimilarDecisions(ctx context.Context, input string, opts SimilarOptions) ([]SimilarDecision, error) {
+	q := `SELECT ` + sqliteDecisionColumns + ` FROM decisions d`
+	var args []any
+	if opts.UserID != nil {
+		q += ` WHERE d.user_id = ?`
+		args = append(args, *opts.UserID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find similar decisions: %w", err)
+	}
+	defer rows.Close()
+
+	var candidates []*Decision
+	for rows.Next() {
+		d, err := scanSQLiteDecision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		candidates = append(candidates, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find similar decisions: %w", err)
+	}
+	return similarDecisions(input, candidates, opts), nil
+}
+
+// GetDecisionLineage returns the supersession chain through a decision,
+// oldest first
+func (r *SQLiteRepository) GetDecisionLineage(ctx context.Context, id uuid.UUID) ([]*Decision, error) {
+	rows, err := r.db.QueryContext(ctx, lineageQuery(sqliteDecisionColumns, "?1"), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decision lineage: %w", err)
+	}
+	defer rows.Close()
+
+	var lineage []*Decision
+	for rows.Next() {
+		d, err := scanSQLiteDecision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan decision: %w", err)
+		}
+		lineage = append(lineage, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get decision lineage: %w", err)
+	}
+	if len(lineage) == 0 {
+		return nil, fmt.Errorf("decision %w", ErrNotFound)
+	}
+	return lineage, nil
+}
+
+// sqliteDecisionColumns are the decision columns read by scanSQLiteDecision
+const sqliteDecisionColumns = `d.id, d.input, d.verdict, d.created_at, d.is_final, d.language, d.user_id,
+	d.input_tokens, d.output_tokens, d.cost_usd, d.providers,
+	d.supersedes, (SELECT s.id FROM decisions s WHERE s.supersedes = d.id),
+	d.content_hash, d.signature`
+
+// scanSQLiteDecision scans a single decision row, followed by any extra
+// columns into extra
+func scanSQLiteDecision(row sqlRow, extra ...any) (*Decision, error) {
+	var d Decision
+	dest := []any{
+		&d.ID,
+		&d.Input,
+		jsonText{&d.Verdict},
+		timestamp{&d.CreatedAt},
+		&d.IsFinal,
+		&d.Language,
+		&d.UserID,
+		&d.InputTokens,
+		&d.OutputTokens,
+		&d.CostUSD,
+		jsonMap{&d.Providers},
+		&d.Supersedes,
+		&d.SupersededBy,
+		&d.ContentHash,
+		&d.Signature,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// sqliteListQuery returns a listing query builder using ? placeholders
+func sqliteListQuery(cols listColumns) *listQuery {
+	return &listQuery{
+		cols: cols,
+		bind: func(int) string { return "?" },
+		time: func(t time.Time) any { return formatTime(t) },
+	}
+}
+
+// CreateTodo inserts a new todo into the database
+func (r *SQLiteRepository) CreateTodo(ctx context.Context, t *Todo) error {
+	if t == nil {
+		return fmt.Errorf("todo cannot be nil")
+	}
+	if err := insertSQLiteTodo(ctx, r.db, t); err != nil {
+		return fmt.Errorf("failed to create todo: %w", sqliteConflictError(err))
+	}
+	return nil
+}
+
+// GetTodo retrieves a todo by its ID
+func (r *SQLiteRepository) GetTodo(ctx context.Context, id uuid.UUID) (*Todo, error) {
+	t, err := r.getTodo(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("todo %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get todo: %w", err)
+	}
+	return t, nil
+}
+
+// GetTodoByDecisionID retrieves a todo by its associated decision ID
+func (r *SQLiteRepository) GetTodoByDecisionID(ctx context.Context, decisionID uuid.UUID) (*Todo, error) {
+	t, err := r.getTodo(ctx, `WHERE decision_id = ?`, decisionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("todo %w for decision", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get todo by decision ID: %w", err)
+	}
+	return t, nil
+}
+
+func (r *SQLiteRepository) getTodo(ctx context.Context, where string, arg any) (*Todo, error) {
+	var t Todo
+	err := r.db.QueryRowContext(ctx, `SELECT id, decision_id, content, created_at FROM todos `+where, arg).Scan(
+		&t.ID,
+		&t.DecisionID,
+		&t.Content,
+		timestamp{&t.CreatedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// SaveArtifacts atomically saves both a decision and its associated todo
+func (r *SQLiteRepository) SaveArtifacts(ctx context.Context, d *Decision, t *Todo) error {
+	if d == nil || t == nil {
+		return fmt.Errorf("decision and todo cannot be nil")
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = now
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+
+	// Ensure todo references the decision
+	t.DecisionID = d.ID
+
+	if err := insertSQLiteDecision(ctx, tx, d); err != nil {
+		return fmt.Errorf("failed to insert decision in transaction: %w", sqliteConflictError(err))
+	}
+	if err := insertSQLiteTodo(ctx, tx, t); err != nil {
+		return fmt.Errorf("failed to insert todo in transaction: %w", sqliteConflictError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// sqlExecer is the subset of sql.DB and sql.Tx used by shared queries
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func insertSQLiteDecision(ctx context.Context, db sqlExecer, d *Decision) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	if err := validateSupersedes(d); err != nil {
+		return err
+	}
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = time.Now()
+	}
+	providers, err := nullableJSONMap(d.Providers)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO decisions (id, input, verdict, created_at, is_final, language, user_id, input_tokens, output_tokens, cost_usd, providers, supersedes, content_hash, signature)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = db.ExecContext(ctx, query, d.ID, d.Input, string(d.Verdict), formatTime(d.CreatedAt), d.IsFinal,
+		d.Language, d.UserID, d.InputTokens, d.OutputTokens, d.CostUSD, providers, d.Supersedes,
+		d.ContentHash, d.Signature)
+	return err
+}
+
+func insertSQLiteTodo(ctx context.Context, db sqlExecer, t *Todo) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO todos (id, decision_id, content, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	_, err := db.ExecContext(ctx, query, t.ID, t.DecisionID, t.Content, formatTime(t.CreatedAt))
+	return err
+}
+
+// === State Snapshots ===
+
+// CreateStateSnapshot inserts a new version of a decision's state
+func (r *SQLiteRepository) CreateStateSnapshot(ctx context.Context, s *StateSnapshot) error {
+	if s == nil {
+		return fmt.Errorf("state snapshot cannot be nil")
+	}
+
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO state_snapshots (id, decision_id, version, state, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, s.ID, s.DecisionID, s.Version, string(s.State), formatTime(s.CreatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create state snapshot: %w", sqliteConflictError(err))
+	}
+
+	return nil
+}
+
+// ListStateSnapshots retrieves every version of a decision's state, oldest first
+func (r *SQLiteRepository) ListStateSnapshots(ctx context.Context, decisionID uuid.UUID) ([]*StateSnapshot, error) {
+	query := `
+		SELECT id, decision_id, version, state, created_at
+		FROM state_snapshots
+		WHERE decision_id = ?
+		ORDER BY version ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, decisionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list state snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	snapshots := []*StateSnapshot{}
+	for rows.Next() {
+		var s StateSnapshot
+		if err := rows.Scan(&s.ID, &s.DecisionID, &s.Version, jsonText{&s.State}, timestamp{&s.CreatedAt}); err != nil {
+			return nil, fmt.Errorf("failed to scan state snapshot: %w", err)
+		}
+		snapshots = append(snapshots, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list state snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
+
+// === Tasks ===
+
+// CreateTasks inserts new tasks in a single transaction
+func (r *SQLiteRepository) CreateTasks(ctx context.Context, tasks []*Task) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO tasks (id, decision_id, phase_index, position, text, status, notes, created_at, updated_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	now := time.Now()
+	for _, t := range tasks {
+		if err := prepareNewTask(t, now); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, query, t.ID, t.DecisionID, t.PhaseIndex, t.Position, t.Text, t.Status, t.Notes,
+			formatTime(t.CreatedAt), formatTime(t.UpdatedAt), nullableTime(t.CompletedAt))
+		if err != nil {
+			return fmt.Errorf("failed to create task: %w", sqliteConflictError(err))
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// ListTasks retrieves the tasks of a decision by phase, then position
+func (r *SQLiteRepository) ListTasks(ctx context.Context, decisionID uuid.UUID) ([]*Task, error) {
+	query := `
+		SELECT id, decision_id, phase_index, position, text, status, notes, created_at, updated_at, completed_at
+		FROM tasks
+		WHERE decision_id = ?
+		ORDER BY phase_index, position
+	`
+	rows, err := r.db.QueryContext(ctx, query, decisionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := []*Task{}
+	for rows.Next() {
+		var t Task
+		err := rows.Scan(&t.ID, &t.DecisionID, &t.PhaseIndex, &t.Position, &t.Text, &t.Status, &t.Notes,
+			timestamp{&t.CreatedAt}, timestamp{&t.UpdatedAt}, nullTimestamp{&t.CompletedAt})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, &t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// UpdateTask updates the status, notes and completion time of a task
+func (r *SQLiteRepository) UpdateTask(ctx context.Context, t *Task) error {
+	if t == nil {
+		return fmt.Errorf("task cannot be nil")
+	}
+	if err := touchTask(t, time.Now()); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE tasks
+		SET status = ?, notes = ?, updated_at = ?, completed_at = ?
+		WHERE id = ?
+	`
+	res, err := r.db.ExecContext(ctx, query, t.Status, t.Notes, formatTime(t.UpdatedAt), nullableTime(t.CompletedAt), t.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update task: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("task %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+// === GitHub Exports ===
+
+// GetGitHubExport retrieves the GitHub export of a decision to a repository
+func (r *SQLiteRepository) GetGitHubExport(ctx context.Context, decisionID uuid.UUID, repository string) (*GitHubExport, error) {
+	query := `
+		SELECT decision_id, repository, tracking_issue, milestones, issues, created_at, updated_at
+		FROM github_exports
+		WHERE decision_id = ? AND repository = ?
+	`
+	var e GitHubExport
+	var milestones, issues json.RawMessage
+	err := r.db.QueryRowContext(ctx, query, decisionID, repository).Scan(
+		&e.DecisionID, &e.Repository, &e.TrackingIssue, jsonText{&milestones}, jsonText{&issues},
+		timestamp{&e.CreatedAt}, timestamp{&e.UpdatedAt})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("github export %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get github export: %w", err)
+	}
+	e.Milestones = map[int]int{}
+	if err := json.Unmarshal(milestones, &e.Milestones); err != nil {
+		return nil, fmt.Errorf("failed to parse github export milestones: %w", err)
+	}
+	e.Issues = map[string]int{}
+	if err := json.Unmarshal(issues, &e.Issues); err != nil {
+		return nil, fmt.Errorf("failed to parse github export issues: %w", err)
+	}
+
+	return &e, nil
+}
+
+// SaveGitHubExport creates or replaces the GitHub export of a decision to a
+// repository
+func (r *SQLiteRepository) SaveGitHubExport(ctx context.Context, e *GitHubExport) error {
+	if e == nil {
+		return fmt.Errorf("github export cannot be nil")
+	}
+	if e.Milestones == nil {
+		e.Milestones = map[int]int{}
+	}
+	if e.Issues == nil {
+		e.Issues = map[string]int{}
+	}
+	milestones, err := json.Marshal(e.Milestones)
+	if err != nil {
+		return fmt.Errorf("failed to marshal github export milestones: %w", err)
+	}
+	issues, err := json.Marshal(e.Issues)
+	if err != nil {
+		return fmt.Errorf("failed to marshal github export issues: %w", err)
+	}
+	now := time.Now()
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = now
+	}
+	e.UpdatedAt = now
+
+	query := `
+		INSERT INTO github_exports (decision_id, repository, tracking_issue, milestones, issues, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (decision_id, repository) DO UPDATE
+		SET tracking_issue = excluded.tracking_issue, milestones = excluded.milestones,
+			issues = excluded.issues, updated_at = excluded.updated_at
+		RETURNING created_at
+	`
+	err = r.db.QueryRowContext(ctx, query, e.DecisionID, e.Repository, e.TrackingIssue, string(milestones), string(issues),
+		formatTime(e.CreatedAt), formatTime(e.UpdatedAt)).Scan(timestamp{&e.CreatedAt})
+	if err != nil {
+		return fmt.Errorf("failed to save github export: %w", err)
+	}
+
+	return nil
+}
+
+// === Jobs ===
+
+// CreateJob inserts a new job into the database
+func (r *SQLiteRepository) CreateJob(ctx context.Context, j *Job) error {
+	if j == nil {
+		return fmt.Errorf("job cannot be nil")
+	}
+
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	if j.Status == "" {
+		j.Status = JobQueued
+	}
+	now := time.Now()
+	if j.CreatedAt.IsZero() {
+		j.CreatedAt = now
+	}
+	j.UpdatedAt = now
+
+	query := `
+		INSERT INTO jobs (id, status, request, result, error, user_id, created_at, updated_at, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query, j.ID, j.Status, string(j.Request), nullableText(j.Result), j.Error, j.UserID,
+		formatTime(j.CreatedAt), formatTime(j.UpdatedAt), nullableTime(j.StartedAt), nullableTime(j.FinishedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", sqliteConflictError(err))
+	}
+
+	return nil
+}
+
+// GetJob retrieves a job by its ID
+func (r *SQLiteRepository) GetJob(ctx context.Context, id uuid.UUID) (*Job, error) {
+	query := `
+		SELECT id, status, request, result, error, user_id, created_at, updated_at, started_at, finished_at
+		FROM jobs
+		WHERE id = ?
+	`
+
+	j, err := scanSQLiteJob(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("job %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return j, nil
+}
+
+// UpdateJob updates the mutable state of an existing job
+func (r *SQLiteRepository) UpdateJob(ctx context.Context, j *Job) error {
+	if j == nil {
+		return fmt.Errorf("job cannot be nil")
+	}
+
+	j.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE jobs
+		SET status = ?, result = ?, error = ?, updated_at = ?, started_at = ?, finished_at = ?
+		WHERE id = ?
+	`
+	res, err := r.db.ExecContext(ctx, query, j.Status, nullableText(j.Result), j.Error, formatTime(j.UpdatedAt),
+		nullableTime(j.StartedAt), nullableTime(j.FinishedAt), j.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update job: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("job %w", ErrNotFound)
+	}
+
+	return nil
+}
+
+// ListJobsByStatus retrieves all jobs in any of the given statuses, oldest first
+func (r *SQLiteRepository) ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error) {
+	if len(statuses) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(statuses))
+	for i, s := range statuses {
+		args[i] = string(s)
+	}
+
+	query := `
+		SELECT id, status, request, result, error, user_id, created_at, updated_at, started_at, finished_at
+		FROM jobs
+		WHERE status IN (` + placeholders(len(statuses)) + `)
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		j, err := scanSQLiteJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// sqlRow is implemented by *sql.Row and *sql.Rows
+type sqlRow interface {
+	Scan(dest ...any) error
+}
+
+// scanSQLiteJob scans a single job row
+func scanSQLiteJob(row sqlRow) (*Job, error) {
+	var j Job
+	err := row.Scan(
+		&j.ID,
+		&j.Status,
+		jsonText{&j.Request},
+		jsonText{&j.Result},
+		&j.Error,
+		&j.UserID,
+		timestamp{&j.CreatedAt},
+		timestamp{&j.UpdatedAt},
+		nullTimestamp{&j.StartedAt},
+		nullTimestamp{&j.FinishedAt},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// Ping checks if the database is reachable
+func (r *SQLiteRepository) Ping(ctx context.Context) error {
+	if err := r.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database ping failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (r *SQLiteRepository) Close() {
+	if r.db != nil {
+		r.db.Close()
+	}
+}
+
+// === User Management ===
+
+// CreateUser creates a new user account
+func (r *SQLiteRepository) CreateUser(ctx context.Context, username, password string) (*User, error) {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &User{
+		ID:           uuid.New(),
+		Username:     username,
+		PasswordHash: hash,
+		CreatedAt:    time.Now(),
+	}
+
+	query := `
+		INSERT INTO users (id, username, password_hash, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+	_, err = r.db.ExecContext(ctx, query, user.ID, user.Username, user.PasswordHash, formatTime(user.CreatedAt))
+	if err != nil {
+		if isSQLiteUniqueViolation(err) {
+			return nil, fmt.Errorf("username %w", ErrConflict)
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserByUsername retrieves a user by username
+func (r *SQLiteRepository) GetUserByUsername(ctx context.Context, username string) (*User, error) {
+	return r.getUser(ctx, `SELECT id, username, password_hash, created_at FROM users WHERE username = ?`, username)
+}
+
+// GetUserByID retrieves a user by ID
+func (r *SQLiteRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*User, error) {
+	return r.getUser(ctx, `SELECT id, username, password_hash, created_at FROM users WHERE id = ?`, id)
+}
+
+// ValidatePassword checks if password matches user's hash
+func (r *SQLiteRepository) ValidatePassword(user *User, password string) bool {
+	return checkPassword(user, password)
+}
+
+func (r *SQLiteRepository) getUser(ctx context.Context, query string, arg any) (*User, error) {
+	var u User
+	err := r.db.QueryRowContext(ctx, query, arg).Scan(&u.ID, &u.Username, &u.PasswordHash, timestamp{&u.CreatedAt})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &u, nil
+}
+
+// === Session Management ===
+
+// CreateSession creates a new session token for user
+func (r *SQLiteRepository) CreateSession(ctx context.Context, userID uuid.UUID) (string, error) {
+	token := uuid.New().String()
+
+	query := `
+		INSERT INTO sessions (token, user_id, created_at)
+		VALUES (?, ?, ?)
+	`
+	if _, err := r.db.ExecContext(ctx, query, token, userID, formatTime(time.Now())); err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return token, nil
+}
+
+// GetUserBySession retrieves user by session token
+func (r *SQLiteRepository) GetUserBySession(ctx context.Context, token string) (*User, error) {
+	query := `
+		SELECT u.id, u.username, u.password_hash, u.created_at
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token = ?
+	`
+	user, err := r.getUser(ctx, query, token)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("session %w", ErrNotFound)
+	}
+	return user, err
+}
+
+// DeleteSession removes a session
+func (r *SQLiteRepository) DeleteSession(ctx context.Context, token string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM sessions WHERE token = ?`, token); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// === History Management ===
+
+// CreateHistory creates a new history entry with its done criteria and uploads
+func (r *SQLiteRepository) CreateHistory(ctx context.Context, h *UserHistory) error {
+	if h == nil {
+		return fmt.Errorf("history cannot be nil")
+	}
+
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	now := time.Now()
+	if h.CreatedAt.IsZero() {
+		h.CreatedAt = now
+	}
+	h.UpdatedAt = now
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO user_history (id, user_id, decision_id, input, verdict, todo, score, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, query, h.ID, h.UserID, h.DecisionID, h.Input, string(h.Verdict), h.Todo, h.Score,
+		formatTime(h.CreatedAt), formatTime(h.UpdatedAt))
+	if err != nil {
+		return fmt.Errorf("failed to create history: %w", sqliteConflictError(err))
+	}
+	if err := replaceSQLiteHistoryDetails(ctx, tx, h.ID, h.DoneCriteria, h.UploadedContent); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetHistory retrieves a history entry by ID
+func (r *SQLiteRepository) GetHistory(ctx context.Context, id uuid.UUID) (*UserHistory, error) {
+	return getSQLiteHistory(ctx, r.db, `WHERE id = ?`, id)
+}
+
+// GetHistoryByDecisionID retrieves history by decision ID
+func (r *SQLiteRepository) GetHistoryByDecisionID(ctx context.Context, decisionID uuid.UUID) (*UserHistory, error) {
+	return getSQLiteHistory(ctx, r.db, `WHERE decision_id = ? ORDER BY created_at LIMIT 1`, decisionID)
+}
+
+// GetUserHistory retrieves all history entries for a user, newest first
+func (r *SQLiteRepository) GetUserHistory(ctx context.Context, userID uuid.UUID) ([]*UserHistory, error) {
+	histories, err := listSQLiteHistory(ctx, r.db, `WHERE user_id = ? ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+	return histories, nil
+}
+
+// ListUserHistory returns a page of a user's history entries matching opts
+func (r *SQLiteRepository) ListUserHistory(ctx context.Context, userID uuid.UUID, opts ListOptions) (*HistoryPage, error) {
+	opts.UserID = &userID
+	q := sqliteListQuery(historyListColumns)
+	clauses, err := q.build(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := listSQLiteHistory(ctx, r.db, `JOIN decisions d ON d.id = h.decision_id `+clauses, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	page := &HistoryPage{}
+	page.Entries, page.NextCursor = nextCursor(entries, opts.limit(), historyPosition)
+	return page, nil
+}
+
+// UpdateHistory updates a history entry, replacing its done criteria and uploads
+func (r *SQLiteRepository) UpdateHistory(ctx context.Context, h *UserHistory) error {
+	if h == nil {
+		return fmt.Errorf("history cannot be nil")
+	}
+
+	h.UpdatedAt = time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE user_history
+		SET input = ?, verdict = ?, todo = ?, score = ?, updated_at = ?
+		WHERE id = ?
+	`
+	res, err := tx.ExecContext(ctx, query, h.Input, string(h.Verdict), h.Todo, h.Score, formatTime(h.UpdatedAt), h.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update history: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("history %w", ErrNotFound)
+	}
+	if err := replaceSQLiteHistoryDetails(ctx, tx, h.ID, h.DoneCriteria, h.UploadedContent); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// UpdateDoneCriteria updates the done criteria and recalculates score
+func (r *SQLiteRepository) UpdateDoneCriteria(ctx context.Context, historyID uuid.UUID, criteria []DoneCriterion) (*UserHistory, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE user_history
+		SET score = ?, updated_at = ?
+		WHERE id = ?
+	`
+	res, err := tx.ExecContext(ctx, query, calculateScore(criteria), formatTime(time.Now()), historyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update history: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, fmt.Errorf("history %w", ErrNotFound)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM done_criteria WHERE history_id = ?`, historyID); err != nil {
+		return nil, fmt.Errorf("failed to replace done criteria: %w", err)
+	}
+	if err := insertSQLiteDoneCriteria(ctx, tx, historyID, criteria); err != nil {
+		return nil, err
+	}
+
+	h, err := getSQLiteHistory(ctx, tx, `WHERE id = ?`, historyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return h, nil
+}
+
+// getSQLiteHistory retrieves the first history entry matching where
+func getSQLiteHistory(ctx context.Context, db sqlExecer, where string, args ...any) (*UserHistory, error) {
+	histories, err := listSQLiteHistory(ctx, db, where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history: %w", err)
+	}
+	if len(histories) == 0 {
+		return nil, fmt.Errorf("history %w", ErrNotFound)
+	}
+	return histories[0], nil
+}
+
+// listSQLiteHistory retrieves the history entries matching where, together
+// with their done criteria and uploaded content
+func listSQLiteHistory(ctx context.Context, db sqlExecer, where string, args ...any) ([]*UserHistory, error) {
+	query := `
+		SELECT h.id, h.user_id, h.decision_id, h.input, h.verdict, h.todo, h.score, h.created_at, h.updated_at
+		FROM user_history h
+	` + where
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	var histories []*UserHistory
+	for rows.Next() {
+		var h UserHistory
+		if err := rows.Scan(&h.ID, &h.UserID, &h.DecisionID, &h.Input, jsonText{&h.Verdict}, &h.Todo, &h.Score,
+			timestamp{&h.CreatedAt}, timestamp{&h.UpdatedAt}); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		h.DoneCriteria = []DoneCriterion{}
+		histories = append(histories, &h)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Details are loaded per entry: the single connection cannot interleave
+	// result sets
+	for _, h := range histories {
+		rows, err := db.QueryContext(ctx, `
+			SELECT idx, text, completed
+			FROM done_criteria
+			WHERE history_id = ?
+			ORDER BY idx
+		`, h.ID)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var c DoneCriterion
+			if err := rows.Scan(&c.Index, &c.Text, &c.Completed); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			h.DoneCriteria = append(h.DoneCriteria, c)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		rows, err = db.QueryContext(ctx, `
+			SELECT id, file_name, content_type, url, uploaded_at
+			FROM uploaded_content
+			WHERE history_id = ?
+			ORDER BY uploaded_at
+		`, h.ID)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var u UploadedContent
+			if err := rows.Scan(&u.ID, &u.FileName, &u.ContentType, &u.URL, timestamp{&u.UploadedAt}); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			h.UploadedContent = append(h.UploadedContent, u)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return histories, nil
+}
+
+// replaceSQLiteHistoryDetails replaces the done criteria and uploaded content
+// of a history entry
+func replaceSQLiteHistoryDetails(ctx context.Context, tx *sql.Tx, historyID uuid.UUID, criteria []DoneCriterion, uploads []UploadedContent) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM done_criteria WHERE history_id = ?`, historyID); err != nil {
+		return fmt.Errorf("failed to replace done criteria: %w", err)
+	}
+	if err := insertSQLiteDoneCriteria(ctx, tx, historyID, criteria); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM uploaded_content WHERE history_id = ?`, historyID); err != nil {
+		return fmt.Errorf("failed to replace uploaded content: %w", err)
+	}
+	query := `
+		INSERT INTO uploaded_content (id, history_id, file_name, content_type, url, uploaded_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+	for i := range uploads {
+		u := &uploads[i]
+		if u.ID == uuid.Nil {
+			u.ID = uuid.New()
+		}
+		if u.UploadedAt.IsZero() {
+			u.UploadedAt = time.Now()
+		}
+		if _, err := tx.ExecContext(ctx, query, u.ID, historyID, u.FileName, u.ContentType, u.URL, formatTime(u.UploadedAt)); err != nil {
+			return fmt.Errorf("failed to insert uploaded content: %w", err)
+		}
+	}
+	return nil
+}
+
+func insertSQLiteDoneCriteria(ctx context.Context, tx *sql.Tx, historyID uuid.UUID, criteria []DoneCriterion) error {
+	query := `
+		INSERT INTO done_criteria (history_id, idx, text, completed)
+		VALUES (?, ?, ?, ?)
+	`
+	for _, c := range criteria {
+		if _, err := tx.ExecContext(ctx, query, historyID, c.Index, c.Text, c.Completed); err != nil {
+			return fmt.Errorf("failed to insert done criterion: %w", err)
+		}
+	}
+	return nil
+}
+
+// isSQLiteUniqueViolation reports whether err is a unique or primary key
+// constraint violation
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code()
+	return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+}
+
+// sqliteConflictError wraps err with ErrConflict if it is a unique constraint
+// violation, keeping the database error for the message
+func sqliteConflictError(err error) error {
+	if isSQLiteUniqueViolation(err) {
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	}
+	return err
+}
+
+// === Column encoding ===
+
+// formatTime encodes a timestamp as sortable RFC 3339 text in UTC
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000000000Z07:00")
+}
+
+func nullableTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return formatTime(*t)
+}
+
+// nullableText converts an empty JSON payload to NULL and others to TEXT
+func nullableText(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+func nullableJSONMap(m map[string]string) (any, error) {
+	if m == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal providers: %w", err)
+	}
+	return string(data), nil
+}
+
+// placeholders returns "?, ?, ..." with n placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// textValue returns the text of a TEXT or BLOB column value
+func textValue(src any) (string, bool) {
+	switch v := src.(type) {
+	case string:
+		return v, true
+	case []byte:
+		return string(v), true
+	}
+	return "", false
+}
+
+// timestamp scans a TEXT timestamp written by formatTime. Drivers that
+// already parse timestamps may also return a time.Time.
+type timestamp struct{ t *time.Time }
+
+func (s timestamp) Scan(src any) error {
+	if v, ok := src.(time.Time); ok {
+		*s.t = v
+		return nil
+	}
+	text, ok := textValue(src)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into timestamp", src)
+	}
+	t, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return err
+	}
+	*s.t = t
+	return nil
+}
+
+// nullTimestamp scans a nullable timestamp
+type nullTimestamp struct{ t **time.Time }
+
+func (s nullTimestamp) Scan(src any) error {
+	if src == nil {
+		*s.t = nil
+		return nil
+	}
+	var t time.Time
+	if err := (timestamp{&t}).Scan(src); err != nil {
+		return err
+	}
+	*s.t = &t
+	return nil
+}
+
+// jsonText scans a nullable TEXT column holding JSON
+type jsonText struct{ dst *json.RawMessage }
+
+func (s jsonText) Scan(src any) error {
+	if src == nil {
+		*s.dst = nil
+		return nil
+	}
+	text, ok := textValue(src)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into JSON", src)
+	}
+	*s.dst = json.RawMessage(text)
+	return nil
+}
+
+// jsonMap scans a nullable TEXT column holding a JSON object of strings
+type jsonMap struct{ dst *map[string]string }
+
+func (s jsonMap) Scan(src any) error {
+	if src == nil {
+		*s.dst = nil
+		return nil
+	}
+	text, ok := textValue(src)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into JSON", src)
+	}
+	return json.Unmarshal([]byte(text), s.dst)
+}
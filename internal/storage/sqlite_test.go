@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSQLitePath(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{url: "sqlite:///var/lib/verdict/verdict.db", want: "/var/lib/verdict/verdict.db"},
+		{url: "sqlite://verdict.db", want: "verdict.db"},
+		{url: "sqlite://:memory:", want: ":memory:"},
+		{url: "sqlite://", wantErr: true},
+		{url: "sqlite:///", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if !IsSQLiteURL(tt.url) {
+			t.Errorf("IsSQLiteURL(%q) = false", tt.url)
+		}
+		got, err := sqlitePath(tt.url)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("sqlitePath(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("sqlitePath(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	if IsSQLiteURL("postgres://localhost/verdict") {
+		t.Error("IsSQLiteURL(postgres URL) = true")
+	}
+}
+
+func TestSQLiteColumnEncoding(t *testing.T) {
+	// Timestamps round-trip through their TEXT encoding and sort as text
+	now := time.Date(2025, 3, 1, 12, 30, 0, 123456789, time.FixedZone("CET", 3600))
+	var got time.Time
+	if err := (timestamp{&got}).Scan(formatTime(now)); err != nil {
+		t.Fatalf("timestamp.Scan() error = %v", err)
+	}
+	if !got.Equal(now) {
+		t.Errorf("timestamp round trip = %v, want %v", got, now)
+	}
+	if formatTime(now) >= formatTime(now.Add(time.Nanosecond*877)) {
+		t.Error("formatted timestamps do not sort chronologically")
+	}
+
+	var started *time.Time
+	if err := (nullTimestamp{&started}).Scan(nil); err != nil || started != nil {
+		t.Errorf("nullTimestamp.Scan(nil) = %v, %v", started, err)
+	}
+	if err := (nullTimestamp{&started}).Scan([]byte(formatTime(now))); err != nil || started == nil || !started.Equal(now) {
+		t.Errorf("nullTimestamp.Scan() = %v, %v", started, err)
+	}
+
+	var raw json.RawMessage
+	if err := (jsonText{&raw}).Scan(`{"ruling":"Go"}`); err != nil || string(raw) != `{"ruling":"Go"}` {
+		t.Errorf("jsonText.Scan() = %s, %v", raw, err)
+	}
+
+	providers, err := nullableJSONMap(map[string]string{"verdict": "openai/gpt-4o"})
+	if err != nil {
+		t.Fatalf("nullableJSONMap() error = %v", err)
+	}
+	var m map[string]string
+	if err := (jsonMap{&m}).Scan(providers); err != nil || m["verdict"] != "openai/gpt-4o" {
+		t.Errorf("jsonMap.Scan() = %v, %v", m, err)
+	}
+	if v, _ := nullableJSONMap(nil); v != nil {
+		t.Errorf("nullableJSONMap(nil) = %v, want nil", v)
+	}
+
+	if got := placeholders(3); got != "?, ?, ?" {
+		t.Errorf("placeholders(3) = %q", got)
+	}
+}
+
+func TestSQLiteRepositoryInterface(t *testing.T) {
+	var _ Repository = (*SQLiteRepository)(nil)
+	var _ UserRepository = (*SQLiteRepository)(nil)
+}
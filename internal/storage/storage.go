@@ -3,11 +3,32 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+// Sentinel errors wrapped by every repository, so callers can tell them apart
+// with errors.Is instead of matching messages
+var (
+	// ErrNotFound is returned for a record that does not exist, e.g.
+	// "decision not found"
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is returned when a record with the same ID or username
+	// already exists, e.g. "username already exists"
+	ErrConflict = errors.New("already exists")
+
+	// ErrForbidden is returned when the caller may not access a record that
+	// belongs to another user
+	ErrForbidden = errors.New("access denied")
+
+	// ErrInvalidCursor is returned for a pagination cursor that was not
+	// produced by a previous page
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
 // Decision represents a stored decision with its verdict
 type Decision struct {
 	ID        uuid.UUID       `json:"id"`
@@ -15,6 +36,27 @@ type Decision struct {
 	Verdict   json.RawMessage `json:"verdict"` // JSONB
 	CreatedAt time.Time       `json:"created_at"`
 	IsFinal   bool            `json:"is_final"`
+	Language  string          `json:"language,omitempty"` // Input language: "en" or "zh"
+
+	// LLM spend of the pipeline run that produced the decision
+	UserID       *uuid.UUID `json:"user_id,omitempty"` // Requesting user, if authenticated
+	InputTokens  int        `json:"input_tokens"`
+	OutputTokens int        `json:"output_tokens"`
+	CostUSD      float64    `json:"cost_usd"`
+
+	// "provider/model" that served each pipeline stage, keyed by stage (JSONB)
+	Providers map[string]string `json:"providers,omitempty"`
+
+	// Lineage. Revisiting a decision creates one that supersedes it; the
+	// superseded decision is never changed, SupersededBy is read from its
+	// successor.
+	Supersedes   *uuid.UUID `json:"supersedes,omitempty"`
+	SupersededBy *uuid.UUID `json:"superseded_by,omitempty"`
+
+	// Tamper evidence recorded when the decision was made: the SHA-256 of its
+	// decision.json and todo.md, and its base64 Ed25519 signature if signed
+	ContentHash string `json:"content_hash,omitempty"`
+	Signature   string `json:"signature,omitempty"`
 }
 
 // Todo represents a stored todo item linked to a decision
@@ -25,11 +67,137 @@ type Todo struct {
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// StateSnapshot represents one version of a decision's state.json artifact.
+// Snapshots are never changed; each progress update adds the next version.
+type StateSnapshot struct {
+	ID         uuid.UUID       `json:"id"`
+	DecisionID uuid.UUID       `json:"decision_id"`
+	Version    int             `json:"version"` // Unique per decision, from 1
+	State      json.RawMessage `json:"state"`   // state.json (JSONB)
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// TaskStatus represents the progress of a task of an execution plan
+type TaskStatus string
+
+// Task statuses
+const (
+	TaskTodo       TaskStatus = "todo"
+	TaskInProgress TaskStatus = "in_progress"
+	TaskDone       TaskStatus = "done"
+	TaskBlocked    TaskStatus = "blocked"
+)
+
+// IsValid reports whether s is one of the task statuses
+func (s TaskStatus) IsValid() bool {
+	return s == TaskTodo || s == TaskInProgress || s == TaskDone || s == TaskBlocked
+}
+
+// Task represents a tracked task of a decision's execution plan
+type Task struct {
+	ID          uuid.UUID  `json:"id"`
+	DecisionID  uuid.UUID  `json:"decision_id"`
+	PhaseIndex  int        `json:"phase_index"` // Index of the phase in the plan, from 0
+	Position    int        `json:"position"`    // Index of the task in its phase, from 0
+	Text        string     `json:"text"`
+	Status      TaskStatus `json:"status"`
+	Notes       string     `json:"notes,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"` // Set while the task is done
+}
+
+// GitHubExport records the GitHub milestones and issues a decision's execution
+// plan was exported to, so that exporting it again updates them
+type GitHubExport struct {
+	DecisionID    uuid.UUID      `json:"decision_id"`
+	Repository    string         `json:"repository"` // "owner/name"
+	TrackingIssue int            `json:"tracking_issue,omitempty"`
+	Milestones    map[int]int    `json:"milestones"` // Milestone number of each phase, by phase index
+	Issues        map[string]int `json:"issues"`     // Issue number of each task, by task ID
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// JobStatus represents the lifecycle state of an asynchronous verdict job
+type JobStatus string
+
+// Job statuses
+const (
+	JobQueued    JobStatus = "queued"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCanceled  JobStatus = "canceled"
+)
+
+// IsTerminal reports whether the job has finished and will not change again
+func (s JobStatus) IsTerminal() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCanceled
+}
+
+// Job represents a queued pipeline run and its outcome
+type Job struct {
+	ID         uuid.UUID       `json:"id"`
+	Status     JobStatus       `json:"status"`
+	Request    json.RawMessage `json:"request"`          // Original request payload (JSONB)
+	Result     json.RawMessage `json:"result,omitempty"` // Response payload once succeeded (JSONB)
+	Error      string          `json:"error,omitempty"`
+	UserID     *uuid.UUID      `json:"user_id,omitempty"` // Submitting user, if authenticated
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// User represents a user account
+type User struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// UserHistory represents a user's decision history entry
+type UserHistory struct {
+	ID              uuid.UUID         `json:"id"`
+	UserID          uuid.UUID         `json:"user_id"`
+	DecisionID      uuid.UUID         `json:"decision_id"`
+	Input           string            `json:"input"`
+	Verdict         json.RawMessage   `json:"verdict"`
+	Todo            string            `json:"todo"`
+	DoneCriteria    []DoneCriterion   `json:"done_criteria"`
+	Score           float64           `json:"score"`
+	UploadedContent []UploadedContent `json:"uploaded_content,omitempty"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// DoneCriterion represents a single done criterion with completion status
+type DoneCriterion struct {
+	Index     int    `json:"index"`
+	Text      string `json:"text"`
+	Completed bool   `json:"completed"`
+}
+
+// UploadedContent represents user-uploaded proof/evidence
+type UploadedContent struct {
+	ID          uuid.UUID `json:"id"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	URL         string    `json:"url"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
 // Repository defines the interface for data persistence operations
 type Repository interface {
 	// Decisions
 	CreateDecision(ctx context.Context, d *Decision) error
 	GetDecision(ctx context.Context, id uuid.UUID) (*Decision, error)
+	ListDecisions(ctx context.Context, opts ListOptions) (*DecisionPage, error)
+	SearchDecisions(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error)
+	FindSimilarDecisions(ctx context.Context, input string, opts SimilarOptions) ([]SimilarDecision, error)
+	GetDecisionLineage(ctx context.Context, id uuid.UUID) ([]*Decision, error)
 
 	// Todos
 	CreateTodo(ctx context.Context, t *Todo) error
@@ -39,9 +207,57 @@ type Repository interface {
 	// Atomic operations
 	SaveArtifacts(ctx context.Context, d *Decision, t *Todo) error
 
+	// State snapshots. Creating a version the decision already has fails
+	// with ErrConflict; snapshots are listed oldest first.
+	CreateStateSnapshot(ctx context.Context, s *StateSnapshot) error
+	ListStateSnapshots(ctx context.Context, decisionID uuid.UUID) ([]*StateSnapshot, error)
+
+	// Tasks. CreateTasks stores all tasks or none, failing with ErrConflict
+	// if a decision already has a task at the same phase and position; tasks
+	// are listed by phase, then position. UpdateTask changes the status,
+	// notes and completion time.
+	CreateTasks(ctx context.Context, tasks []*Task) error
+	ListTasks(ctx context.Context, decisionID uuid.UUID) ([]*Task, error)
+	UpdateTask(ctx context.Context, t *Task) error
+
+	// GitHub exports, one per decision and repository. SaveGitHubExport
+	// creates the record or replaces its mapping.
+	GetGitHubExport(ctx context.Context, decisionID uuid.UUID, repository string) (*GitHubExport, error)
+	SaveGitHubExport(ctx context.Context, e *GitHubExport) error
+
+	// Jobs
+	CreateJob(ctx context.Context, j *Job) error
+	GetJob(ctx context.Context, id uuid.UUID) (*Job, error)
+	UpdateJob(ctx context.Context, j *Job) error
+	ListJobsByStatus(ctx context.Context, statuses ...JobStatus) ([]*Job, error)
+
 	// Health
 	Ping(ctx context.Context) error
 
 	// Cleanup
 	Close()
 }
+
+// UserRepository defines the persistence operations for user accounts, sessions
+// and decision history
+type UserRepository interface {
+	// Users
+	CreateUser(ctx context.Context, username, password string) (*User, error)
+	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
+	ValidatePassword(user *User, password string) bool
+
+	// Sessions
+	CreateSession(ctx context.Context, userID uuid.UUID) (string, error)
+	GetUserBySession(ctx context.Context, token string) (*User, error)
+	DeleteSession(ctx context.Context, token string) error
+
+	// History
+	CreateHistory(ctx context.Context, h *UserHistory) error
+	GetHistory(ctx context.Context, id uuid.UUID) (*UserHistory, error)
+	GetHistoryByDecisionID(ctx context.Context, decisionID uuid.UUID) (*UserHistory, error)
+	GetUserHistory(ctx context.Context, userID uuid.UUID) ([]*UserHistory, error)
+	ListUserHistory(ctx context.Context, userID uuid.UUID, opts ListOptions) (*HistoryPage, error)
+	UpdateHistory(ctx context.Context, h *UserHistory) error
+	UpdateDoneCriteria(ctx context.Context, historyID uuid.UUID, criteria []DoneCriterion) (*UserHistory, error)
+}
@@ -0,0 +1,1190 @@
+// Package storagetest provides a conformance suite for storage.Repository
+// implementations, so every backend behaves the same behind the API.
+package storagetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1psychoQAQ/verdict-agent/internal/storage"
+	"github.com/google/uuid"
+)
+
+// TestRepository runs the conformance suite against the repository returned
+// by newRepo. If it also implements storage.UserRepository, the user, session
+// and history operations are tested too.
+//
+// newRepo is called once per subtest. Tests only look at records they create,
+// so a shared database that already holds data may be returned.
+func TestRepository(t *testing.T, newRepo func(t *testing.T) storage.Repository) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo storage.Repository)
+	}{
+		{"DecisionRoundTrip", testDecisionRoundTrip},
+		{"DecisionDefaults", testDecisionDefaults},
+		{"TodoRoundTrip", testTodoRoundTrip},
+		{"ListDecisions", testListDecisions},
+		{"SearchDecisions", testSearchDecisions},
+		{"FindSimilarDecisions", testFindSimilarDecisions},
+		{"DecisionLineage", testDecisionLineage},
+		{"StateSnapshots", testStateSnapshots},
+		{"Tasks", testTasks},
+		{"GitHubExports", testGitHubExports},
+		{"NotFound", testNotFound},
+		{"SaveArtifacts", testSaveArtifacts},
+		{"SaveArtifactsAtomic", testSaveArtifactsAtomic},
+		{"Jobs", testJobs},
+		{"Concurrent", testConcurrent},
+		{"Ping", testPing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+
+	userTests := []struct {
+		name string
+		fn   func(t *testing.T, repo storage.Repository, users storage.UserRepository)
+	}{
+		{"Users", testUsers},
+		{"Sessions", testSessions},
+		{"History", testHistory},
+		{"ListUserHistory", testListUserHistory},
+		{"ListDecisionsByScore", testListDecisionsByScore},
+	}
+	for _, tt := range userTests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo(t)
+			users, ok := repo.(storage.UserRepository)
+			if !ok {
+				t.Skip("repository does not implement storage.UserRepository")
+			}
+			tt.fn(t, repo, users)
+		})
+	}
+}
+
+// fixedTime is a timestamp every backend stores exactly (microsecond precision)
+var fixedTime = time.Date(2025, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+func newDecision() *storage.Decision {
+	userID := uuid.New()
+	return &storage.Decision{
+		ID:           uuid.New(),
+		Input:        "Should I use Go or Rust?",
+		Verdict:      json.RawMessage(`{"ruling":"Use Go","rationale":"Faster to ship","rejected":[]}`),
+		CreatedAt:    fixedTime,
+		IsFinal:      true,
+		Language:     "en",
+		UserID:       &userID,
+		InputTokens:  1200,
+		OutputTokens: 340,
+		CostUSD:      0.0125,
+		Providers:    map[string]string{"verdict": "openai/gpt-4o"},
+		ContentHash:  "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		Signature:    "c2lnbmF0dXJl",
+	}
+}
+
+// assertJSONEqual compares JSON documents ignoring formatting, which JSONB
+// columns do not preserve
+func assertJSONEqual(t *testing.T, field string, got, want []byte) {
+	t.Helper()
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Errorf("%s: invalid JSON %q: %v", field, got, err)
+		return
+	}
+	if err := json.Unmarshal(want, &w); err != nil {
+		t.Fatalf("%s: invalid expected JSON: %v", field, err)
+	}
+	if !reflect.DeepEqual(g, w) {
+		t.Errorf("%s = %s, want %s", field, got, want)
+	}
+}
+
+func assertNotFound(t *testing.T, op string, err error) {
+	t.Helper()
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("%s: error = %v, want storage.ErrNotFound", op, err)
+	}
+}
+
+func assertConflict(t *testing.T, op string, err error) {
+	t.Helper()
+	if !errors.Is(err, storage.ErrConflict) {
+		t.Errorf("%s: error = %v, want storage.ErrConflict", op, err)
+	}
+}
+
+func testDecisionRoundTrip(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	want := newDecision()
+	if err := repo.CreateDecision(ctx, want); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+
+	got, err := repo.GetDecision(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("GetDecision() error = %v", err)
+	}
+	if got.ID != want.ID || got.Input != want.Input || got.IsFinal != want.IsFinal || got.Language != want.Language {
+		t.Errorf("GetDecision() = %+v, want %+v", got, want)
+	}
+	assertJSONEqual(t, "Verdict", got.Verdict, want.Verdict)
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if got.UserID == nil || *got.UserID != *want.UserID {
+		t.Errorf("UserID = %v, want %v", got.UserID, *want.UserID)
+	}
+	if got.InputTokens != want.InputTokens || got.OutputTokens != want.OutputTokens || got.CostUSD != want.CostUSD {
+		t.Errorf("usage = %d/%d/%v, want %d/%d/%v", got.InputTokens, got.OutputTokens, got.CostUSD,
+			want.InputTokens, want.OutputTokens, want.CostUSD)
+	}
+	if !reflect.DeepEqual(got.Providers, want.Providers) {
+		t.Errorf("Providers = %v, want %v", got.Providers, want.Providers)
+	}
+	if got.ContentHash != want.ContentHash || got.Signature != want.Signature {
+		t.Errorf("ContentHash, Signature = %q, %q, want %q, %q", got.ContentHash, got.Signature, want.ContentHash, want.Signature)
+	}
+
+	// Duplicate IDs are rejected
+	err = repo.CreateDecision(ctx, newDecisionWithID(want.ID))
+	assertConflict(t, "CreateDecision with a duplicate ID", err)
+}
+
+func newDecisionWithID(id uuid.UUID) *storage.Decision {
+	d := newDecision()
+	d.ID = id
+	return d
+}
+
+func testDecisionDefaults(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	before := time.Now().Add(-time.Second)
+	d := &storage.Decision{
+		Input:   "Tabs or spaces?",
+		Verdict: json.RawMessage(`{"ruling":"Spaces"}`),
+	}
+	if err := repo.CreateDecision(ctx, d); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+	if d.ID == uuid.Nil {
+		t.Fatal("CreateDecision() did not assign an ID")
+	}
+	if d.CreatedAt.Before(before) || d.CreatedAt.After(time.Now().Add(time.Second)) {
+		t.Errorf("CreatedAt = %v, want about now", d.CreatedAt)
+	}
+
+	got, err := repo.GetDecision(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("GetDecision() error = %v", err)
+	}
+	if got.CreatedAt.Sub(d.CreatedAt).Abs() > time.Millisecond {
+		t.Errorf("stored CreatedAt = %v, want %v", got.CreatedAt, d.CreatedAt)
+	}
+	if got.UserID != nil || got.Providers != nil || got.ContentHash != "" || got.Signature != "" {
+		t.Errorf("optional fields = %v, %v, %q, %q, want empty", got.UserID, got.Providers, got.ContentHash, got.Signature)
+	}
+}
+
+func testTodoRoundTrip(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	d := newDecision()
+	if err := repo.CreateDecision(ctx, d); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+
+	todo := &storage.Todo{DecisionID: d.ID, Content: "# Todo\n- [ ] Ship it"}
+	if err := repo.CreateTodo(ctx, todo); err != nil {
+		t.Fatalf("CreateTodo() error = %v", err)
+	}
+	if todo.ID == uuid.Nil || todo.CreatedAt.IsZero() {
+		t.Errorf("CreateTodo() did not assign ID and CreatedAt: %+v", todo)
+	}
+
+	for name, get := range map[string]func() (*storage.Todo, error){
+		"GetTodo":             func() (*storage.Todo, error) { return repo.GetTodo(ctx, todo.ID) },
+		"GetTodoByDecisionID": func() (*storage.Todo, error) { return repo.GetTodoByDecisionID(ctx, d.ID) },
+	} {
+		got, err := get()
+		if err != nil {
+			t.Errorf("%s() error = %v", name, err)
+			continue
+		}
+		if got.ID != todo.ID || got.DecisionID != d.ID || got.Content != todo.Content {
+			t.Errorf("%s() = %+v, want %+v", name, got, todo)
+		}
+	}
+}
+
+func testNotFound(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	missing := uuid.New()
+
+	_, err := repo.GetDecision(ctx, missing)
+	assertNotFound(t, "GetDecision", err)
+	_, err = repo.GetTodo(ctx, missing)
+	assertNotFound(t, "GetTodo", err)
+	_, err = repo.GetTodoByDecisionID(ctx, missing)
+	assertNotFound(t, "GetTodoByDecisionID", err)
+	_, err = repo.GetJob(ctx, missing)
+	assertNotFound(t, "GetJob", err)
+	err = repo.UpdateJob(ctx, &storage.Job{ID: missing, Status: storage.JobRunning, Request: json.RawMessage(`{}`)})
+	assertNotFound(t, "UpdateJob", err)
+}
+
+func testSaveArtifacts(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	d := &storage.Decision{Input: "Monolith or microservices?", Verdict: json.RawMessage(`{"ruling":"Monolith"}`), IsFinal: true}
+	todo := &storage.Todo{Content: "# Todo"}
+	if err := repo.SaveArtifacts(ctx, d, todo); err != nil {
+		t.Fatalf("SaveArtifacts() error = %v", err)
+	}
+	if d.ID == uuid.Nil || todo.ID == uuid.Nil {
+		t.Fatal("SaveArtifacts() did not assign IDs")
+	}
+	if todo.DecisionID != d.ID {
+		t.Errorf("todo.DecisionID = %v, want %v", todo.DecisionID, d.ID)
+	}
+
+	if _, err := repo.GetDecision(ctx, d.ID); err != nil {
+		t.Errorf("GetDecision() error = %v", err)
+	}
+	got, err := repo.GetTodoByDecisionID(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("GetTodoByDecisionID() error = %v", err)
+	}
+	if got.ID != todo.ID {
+		t.Errorf("GetTodoByDecisionID() ID = %v, want %v", got.ID, todo.ID)
+	}
+
+	if err := repo.SaveArtifacts(ctx, nil, todo); err == nil {
+		t.Error("SaveArtifacts(nil, todo) succeeded")
+	}
+}
+
+func testSaveArtifactsAtomic(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	existing := &storage.Todo{DecisionID: uuid.Nil, Content: "# Existing"}
+	first := newDecision()
+	if err := repo.SaveArtifacts(ctx, first, existing); err != nil {
+		t.Fatalf("SaveArtifacts() error = %v", err)
+	}
+
+	// The todo insert fails on its duplicate ID, so the decision must not be kept
+	d := newDecision()
+	err := repo.SaveArtifacts(ctx, d, &storage.Todo{ID: existing.ID, Content: "# Duplicate"})
+	assertConflict(t, "SaveArtifacts with a duplicate todo ID", err)
+	_, err = repo.GetDecision(ctx, d.ID)
+	assertNotFound(t, "GetDecision after failed SaveArtifacts", err)
+
+	got, err := repo.GetTodo(ctx, existing.ID)
+	if err != nil {
+		t.Fatalf("GetTodo() error = %v", err)
+	}
+	if got.Content != existing.Content || got.DecisionID != first.ID {
+		t.Errorf("existing todo was modified: %+v", got)
+	}
+}
+
+func testJobs(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	queued := &storage.Job{Request: json.RawMessage(`{"input":"first"}`), CreatedAt: fixedTime}
+	if err := repo.CreateJob(ctx, queued); err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	if queued.ID == uuid.Nil || queued.Status != storage.JobQueued || queued.UpdatedAt.IsZero() {
+		t.Errorf("CreateJob() defaults = %+v", queued)
+	}
+
+	running := &storage.Job{Request: json.RawMessage(`{"input":"second"}`), CreatedAt: fixedTime.Add(time.Second)}
+	if err := repo.CreateJob(ctx, running); err != nil {
+		t.Fatalf("CreateJob() error = %v", err)
+	}
+	started := fixedTime.Add(2 * time.Second)
+	running.Status = storage.JobRunning
+	running.StartedAt = &started
+	if err := repo.UpdateJob(ctx, running); err != nil {
+		t.Fatalf("UpdateJob() error = %v", err)
+	}
+
+	got, err := repo.GetJob(ctx, running.ID)
+	if err != nil {
+		t.Fatalf("GetJob() error = %v", err)
+	}
+	if got.Status != storage.JobRunning || got.StartedAt == nil || !got.StartedAt.Equal(started) || got.FinishedAt != nil {
+		t.Errorf("GetJob() = %+v", got)
+	}
+	assertJSONEqual(t, "Request", got.Request, running.Request)
+	if len(got.Result) != 0 {
+		t.Errorf("Result = %s, want empty", got.Result)
+	}
+
+	// Finish with a result
+	finished := fixedTime.Add(3 * time.Second)
+	got.Status = storage.JobSucceeded
+	got.Result = json.RawMessage(`{"status":"verdict"}`)
+	got.FinishedAt = &finished
+	if err := repo.UpdateJob(ctx, got); err != nil {
+		t.Fatalf("UpdateJob() error = %v", err)
+	}
+	done, err := repo.GetJob(ctx, running.ID)
+	if err != nil {
+		t.Fatalf("GetJob() error = %v", err)
+	}
+	assertJSONEqual(t, "Result", done.Result, got.Result)
+
+	// Listing is oldest first and filtered by status
+	jobs, err := repo.ListJobsByStatus(ctx, storage.JobQueued, storage.JobSucceeded)
+	if err != nil {
+		t.Fatalf("ListJobsByStatus() error = %v", err)
+	}
+	var ids []uuid.UUID
+	for _, j := range jobs {
+		if j.ID == queued.ID || j.ID == running.ID {
+			ids = append(ids, j.ID)
+		}
+	}
+	if !reflect.DeepEqual(ids, []uuid.UUID{queued.ID, running.ID}) {
+		t.Errorf("ListJobsByStatus() order = %v, want [%v %v]", ids, queued.ID, running.ID)
+	}
+	jobs, err = repo.ListJobsByStatus(ctx, storage.JobRunning)
+	if err != nil {
+		t.Fatalf("ListJobsByStatus() error = %v", err)
+	}
+	for _, j := range jobs {
+		if j.ID == queued.ID || j.ID == running.ID {
+			t.Errorf("ListJobsByStatus(running) returned job %v in status %s", j.ID, j.Status)
+		}
+	}
+}
+
+func testConcurrent(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	const workers = 10
+
+	var wg sync.WaitGroup
+	decisions := make([]*storage.Decision, workers)
+	errs := make(chan error, workers)
+	for i := range workers {
+		decisions[i] = &storage.Decision{
+			Input:   fmt.Sprintf("concurrent %d", i),
+			Verdict: json.RawMessage(`{"ruling":"yes"}`),
+		}
+		wg.Add(1)
+		go func(d *storage.Decision) {
+			defer wg.Done()
+			if err := repo.SaveArtifacts(ctx, d, &storage.Todo{Content: d.Input}); err != nil {
+				errs <- err
+				return
+			}
+			if _, err := repo.GetDecision(ctx, d.ID); err != nil {
+				errs <- err
+			}
+		}(decisions[i])
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent SaveArtifacts/GetDecision error = %v", err)
+	}
+
+	for _, d := range decisions {
+		todo, err := repo.GetTodoByDecisionID(ctx, d.ID)
+		if err != nil {
+			t.Errorf("GetTodoByDecisionID(%v) error = %v", d.ID, err)
+			continue
+		}
+		if todo.Content != d.Input {
+			t.Errorf("todo for %q = %q", d.Input, todo.Content)
+		}
+	}
+}
+
+func testPing(t *testing.T, repo storage.Repository) {
+	if err := repo.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+}
+
+func testUsers(t *testing.T, _ storage.Repository, users storage.UserRepository) {
+	ctx := context.Background()
+	username := "user-" + uuid.NewString()
+
+	user, err := users.CreateUser(ctx, username, "hunter22")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	if user.ID == uuid.Nil || user.CreatedAt.IsZero() || user.PasswordHash == "hunter22" {
+		t.Errorf("CreateUser() = %+v", user)
+	}
+	_, err = users.CreateUser(ctx, username, "other")
+	assertConflict(t, "CreateUser with a duplicate username", err)
+
+	byName, err := users.GetUserByUsername(ctx, username)
+	if err != nil {
+		t.Fatalf("GetUserByUsername() error = %v", err)
+	}
+	byID, err := users.GetUserByID(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserByID() error = %v", err)
+	}
+	if byName.ID != user.ID || byID.Username != username {
+		t.Errorf("lookups = %+v, %+v, want %+v", byName, byID, user)
+	}
+
+	if !users.ValidatePassword(byName, "hunter22") {
+		t.Error("ValidatePassword() rejected the correct password")
+	}
+	if users.ValidatePassword(byName, "hunter23") {
+		t.Error("ValidatePassword() accepted a wrong password")
+	}
+
+	_, err = users.GetUserByUsername(ctx, "missing-"+uuid.NewString())
+	assertNotFound(t, "GetUserByUsername", err)
+	_, err = users.GetUserByID(ctx, uuid.New())
+	assertNotFound(t, "GetUserByID", err)
+}
+
+func testSessions(t *testing.T, _ storage.Repository, users storage.UserRepository) {
+	ctx := context.Background()
+	user, err := users.CreateUser(ctx, "user-"+uuid.NewString(), "hunter22")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	token, err := users.CreateSession(ctx, user.ID)
+	if err != nil || token == "" {
+		t.Fatalf("CreateSession() = %q, %v", token, err)
+	}
+	got, err := users.GetUserBySession(ctx, token)
+	if err != nil {
+		t.Fatalf("GetUserBySession() error = %v", err)
+	}
+	if got.ID != user.ID {
+		t.Errorf("GetUserBySession() = %v, want %v", got.ID, user.ID)
+	}
+
+	if err := users.DeleteSession(ctx, token); err != nil {
+		t.Fatalf("DeleteSession() error = %v", err)
+	}
+	_, err = users.GetUserBySession(ctx, token)
+	assertNotFound(t, "GetUserBySession after DeleteSession", err)
+}
+
+func testHistory(t *testing.T, repo storage.Repository, users storage.UserRepository) {
+	ctx := context.Background()
+	user, err := users.CreateUser(ctx, "user-"+uuid.NewString(), "hunter22")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	var entries []*storage.UserHistory
+	for i := range 2 {
+		d := newDecision()
+		if err := repo.CreateDecision(ctx, d); err != nil {
+			t.Fatalf("CreateDecision() error = %v", err)
+		}
+		h := &storage.UserHistory{
+			UserID:     user.ID,
+			DecisionID: d.ID,
+			Input:      d.Input,
+			Verdict:    d.Verdict,
+			Todo:       "# Todo",
+			DoneCriteria: []storage.DoneCriterion{
+				{Index: 0, Text: "Write the service"},
+				{Index: 1, Text: "Deploy it"},
+			},
+			UploadedContent: []storage.UploadedContent{
+				{FileName: "proof.png", ContentType: "image/png", URL: "/uploads/proof.png", UploadedAt: fixedTime},
+			},
+			CreatedAt: fixedTime.Add(time.Duration(i) * time.Hour),
+		}
+		if err := users.CreateHistory(ctx, h); err != nil {
+			t.Fatalf("CreateHistory() error = %v", err)
+		}
+		if h.ID == uuid.Nil || h.UpdatedAt.IsZero() {
+			t.Errorf("CreateHistory() defaults = %+v", h)
+		}
+		entries = append(entries, h)
+	}
+
+	got, err := users.GetHistory(ctx, entries[0].ID)
+	if err != nil {
+		t.Fatalf("GetHistory() error = %v", err)
+	}
+	if got.UserID != user.ID || got.DecisionID != entries[0].DecisionID || got.Todo != "# Todo" {
+		t.Errorf("GetHistory() = %+v", got)
+	}
+	if len(got.DoneCriteria) != 2 || got.DoneCriteria[1].Text != "Deploy it" {
+		t.Errorf("DoneCriteria = %+v", got.DoneCriteria)
+	}
+	if len(got.UploadedContent) != 1 || got.UploadedContent[0].FileName != "proof.png" || got.UploadedContent[0].ID == uuid.Nil {
+		t.Errorf("UploadedContent = %+v", got.UploadedContent)
+	}
+
+	byDecision, err := users.GetHistoryByDecisionID(ctx, entries[1].DecisionID)
+	if err != nil {
+		t.Fatalf("GetHistoryByDecisionID() error = %v", err)
+	}
+	if byDecision.ID != entries[1].ID {
+		t.Errorf("GetHistoryByDecisionID() = %v, want %v", byDecision.ID, entries[1].ID)
+	}
+
+	// Newest first
+	list, err := users.GetUserHistory(ctx, user.ID)
+	if err != nil {
+		t.Fatalf("GetUserHistory() error = %v", err)
+	}
+	if len(list) != 2 || list[0].ID != entries[1].ID || list[1].ID != entries[0].ID {
+		t.Errorf("GetUserHistory() returned %d entries in the wrong order", len(list))
+	}
+
+	// Completing one of two criteria scores 50
+	updated, err := users.UpdateDoneCriteria(ctx, entries[0].ID, []storage.DoneCriterion{
+		{Index: 0, Text: "Write the service", Completed: true},
+		{Index: 1, Text: "Deploy it"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateDoneCriteria() error = %v", err)
+	}
+	if updated.Score != 50 || !updated.DoneCriteria[0].Completed {
+		t.Errorf("UpdateDoneCriteria() = score %v, criteria %+v", updated.Score, updated.DoneCriteria)
+	}
+	if got, _ := users.GetHistory(ctx, entries[0].ID); got == nil || got.Score != 50 {
+		t.Errorf("stored score = %+v, want 50", got)
+	}
+
+	_, err = users.GetHistory(ctx, uuid.New())
+	assertNotFound(t, "GetHistory", err)
+	_, err = users.GetHistoryByDecisionID(ctx, uuid.New())
+	assertNotFound(t, "GetHistoryByDecisionID", err)
+	_, err = users.UpdateDoneCriteria(ctx, uuid.New(), nil)
+	assertNotFound(t, "UpdateDoneCriteria", err)
+	err = users.UpdateHistory(ctx, &storage.UserHistory{ID: uuid.New(), Verdict: json.RawMessage(`{}`)})
+	assertNotFound(t, "UpdateHistory", err)
+}
+
+// listed pages through a listing with the given page size and returns the IDs
+// in the order they were listed
+func listed[T any](t *testing.T, limit int, list func(cursor string) ([]T, string, error), id func(T) uuid.UUID) []uuid.UUID {
+	t.Helper()
+	var ids []uuid.UUID
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 100 {
+			t.Fatal("listing did not end")
+		}
+		items, next, err := list(cursor)
+		if err != nil {
+			t.Fatalf("list error = %v", err)
+		}
+		if len(items) > limit {
+			t.Fatalf("page has %d items, limit %d", len(items), limit)
+		}
+		for _, item := range items {
+			ids = append(ids, id(item))
+		}
+		if next == "" {
+			return ids
+		}
+		cursor = next
+	}
+}
+
+func decisionIDs(t *testing.T, repo storage.Repository, opts storage.ListOptions) []uuid.UUID {
+	t.Helper()
+	return listed(t, opts.Limit, func(cursor string) ([]*storage.Decision, string, error) {
+		opts.Cursor = cursor
+		page, err := repo.ListDecisions(context.Background(), opts)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Decisions, page.NextCursor, nil
+	}, func(d *storage.Decision) uuid.UUID { return d.ID })
+}
+
+func assertIDs(t *testing.T, name string, got []uuid.UUID, want ...uuid.UUID) {
+	t.Helper()
+	if len(got) == 0 && len(want) == 0 {
+		return
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func testListDecisions(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// Five decisions an hour apart, the last two created at the same time
+	var ids []uuid.UUID
+	for i := range 5 {
+		d := newDecision()
+		d.UserID = &userID
+		d.CreatedAt = fixedTime.Add(time.Duration(min(i, 3)) * time.Hour)
+		d.IsFinal = i%2 == 0
+		if i == 1 {
+			d.Language = "zh"
+		}
+		if err := repo.CreateDecision(ctx, d); err != nil {
+			t.Fatalf("CreateDecision() error = %v", err)
+		}
+		ids = append(ids, d.ID)
+	}
+	// Ties are broken by ID
+	oldest := slices.Clone(ids)
+	if oldest[3].String() > oldest[4].String() {
+		oldest[3], oldest[4] = oldest[4], oldest[3]
+	}
+	newest := slices.Clone(oldest)
+	slices.Reverse(newest)
+
+	for _, limit := range []int{1, 2, 5, 10} {
+		got := decisionIDs(t, repo, storage.ListOptions{UserID: &userID, Limit: limit})
+		assertIDs(t, fmt.Sprintf("newest first, limit %d", limit), got, newest...)
+	}
+	got := decisionIDs(t, repo, storage.ListOptions{UserID: &userID, Limit: 2, Ascending: true})
+	assertIDs(t, "oldest first", got, oldest...)
+
+	got = decisionIDs(t, repo, storage.ListOptions{UserID: &userID, Limit: 10, Language: "zh"})
+	assertIDs(t, "language zh", got, ids[1])
+
+	notFinal := false
+	got = decisionIDs(t, repo, storage.ListOptions{UserID: &userID, Limit: 10, IsFinal: &notFinal})
+	assertIDs(t, "not final", got, ids[3], ids[1])
+
+	got = decisionIDs(t, repo, storage.ListOptions{
+		UserID: &userID,
+		Limit:  10,
+		From:   fixedTime.Add(time.Hour),
+		To:     fixedTime.Add(3 * time.Hour),
+	})
+	assertIDs(t, "date range", got, ids[2], ids[1])
+
+	other := uuid.New()
+	got = decisionIDs(t, repo, storage.ListOptions{UserID: &other, Limit: 10})
+	assertIDs(t, "other user", got)
+
+	_, err := repo.ListDecisions(ctx, storage.ListOptions{Cursor: "not-a-cursor"})
+	if !errors.Is(err, storage.ErrInvalidCursor) {
+		t.Errorf("ListDecisions() with a bad cursor error = %v, want storage.ErrInvalidCursor", err)
+	}
+}
+
+func testSearchDecisions(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	save := func(input, language, verdict, todo string) uuid.UUID {
+		t.Helper()
+		d := newDecision()
+		d.UserID = &userID
+		d.Input = input
+		d.Language = language
+		d.Verdict = json.RawMessage(verdict)
+		if err := repo.SaveArtifacts(ctx, d, &storage.Todo{Content: todo}); err != nil {
+			t.Fatalf("SaveArtifacts() error = %v", err)
+		}
+		return d.ID
+	}
+	// Decisions store the whole decision.json document
+	platform := save("Should we adopt Kubernetes for the platform team?", "en",
+		`{"input":"...","verdict":{"ruling":"Adopt Kubernetes","rationale":"The operators already know Helm","rejected":[{"option":"Nomad","reason":"Smaller ecosystem"}]},"is_final":true}`,
+		"- [ ] Migrate staging first")
+	architecture := save("我们应该用微服务还是单体架构？", "zh",
+		`{"ruling":"单体架构","rationale":"团队太小","rejected":[]}`,
+		"- [ ] 拆分模块")
+	dashboards := save("Which dashboard tool should we standardise on?", "en",
+		`{"ruling":"Grafana","rationale":"Already deployed","rejected":[]}`,
+		"- [ ] Install the Kubernetes monitoring agent")
+
+	search := func(query string, opts storage.SearchOptions) []uuid.UUID {
+		t.Helper()
+		if opts.UserID == nil {
+			opts.UserID = &userID
+		}
+		results, err := repo.SearchDecisions(ctx, query, opts)
+		if err != nil {
+			t.Fatalf("SearchDecisions(%q) error = %v", query, err)
+		}
+		var ids []uuid.UUID
+		for i, r := range results {
+			if i > 0 && r.Rank > results[i-1].Rank {
+				t.Errorf("SearchDecisions(%q) not ordered by rank: %v", query, results)
+			}
+			ids = append(ids, r.Decision.ID)
+		}
+		return ids
+	}
+
+	// Matches in the decision rank above matches only in its todo
+	assertIDs(t, "input, ruling and todo", search("kubernetes", storage.SearchOptions{}), platform, dashboards)
+	assertIDs(t, "limit", search("kubernetes", storage.SearchOptions{Limit: 1}), platform)
+	assertIDs(t, "rejected option", search("Nomad", storage.SearchOptions{}), platform)
+	assertIDs(t, "rationale, every word", search("Helm operators", storage.SearchOptions{}), platform)
+	assertIDs(t, "flat verdict", search("Grafana", storage.SearchOptions{}), dashboards)
+	assertIDs(t, "chinese", search("微服务", storage.SearchOptions{}), architecture)
+	assertIDs(t, "chinese todo", search("拆分", storage.SearchOptions{}), architecture)
+
+	assertIDs(t, "missing word", search("Kubernetes Zeppelin", storage.SearchOptions{}))
+	assertIDs(t, "wildcard", search("%", storage.SearchOptions{}))
+	assertIDs(t, "blank", search("   ", storage.SearchOptions{}))
+	other := uuid.New()
+	assertIDs(t, "other user", search("kubernetes", storage.SearchOptions{UserID: &other}))
+}
+
+func testFindSimilarDecisions(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	userID, otherID := uuid.New(), uuid.New()
+
+	create := func(input string, owner uuid.UUID) uuid.UUID {
+		t.Helper()
+		d := newDecision()
+		d.Input = input
+		d.UserID = &owner
+		if err := repo.CreateDecision(ctx, d); err != nil {
+			t.Fatalf("CreateDecision() error = %v", err)
+		}
+		return d.ID
+	}
+	goOrRust := create("Should I use Go or Rust for the ingestion service?", userID)
+	create("Monolith or microservices for a team of four?", userID)
+	chinese := create("我们应该用微服务还是单体架构？", userID)
+	theirs := create("Should I use Go or Rust for the ingestion service?", otherID)
+
+	find := func(input string, opts storage.SimilarOptions) ([]uuid.UUID, []storage.SimilarDecision) {
+		t.Helper()
+		if opts.UserID == nil {
+			opts.UserID = &userID
+		}
+		results, err := repo.FindSimilarDecisions(ctx, input, opts)
+		if err != nil {
+			t.Fatalf("FindSimilarDecisions(%q) error = %v", input, err)
+		}
+		var ids []uuid.UUID
+		for _, r := range results {
+			ids = append(ids, r.Decision.ID)
+		}
+		return ids, results
+	}
+
+	// Case, spacing and punctuation do not matter
+	ids, results := find("  should i use GO or Rust for the ingestion service ", storage.SimilarOptions{MinSimilarity: 0.9})
+	assertIDs(t, "normalized duplicate", ids, goOrRust)
+	if len(results) == 1 && results[0].Similarity != 1 {
+		t.Errorf("normalized duplicate similarity = %v, want 1", results[0].Similarity)
+	}
+	ids, _ = find("我们应该用微服务还是单体架构", storage.SimilarOptions{MinSimilarity: 0.9})
+	assertIDs(t, "chinese duplicate", ids, chinese)
+
+	// Similar but not identical inputs fall below a strict threshold
+	rephrased := "Should I use Go or Rust for the new ingestion service?"
+	ids, results = find(rephrased, storage.SimilarOptions{MinSimilarity: 0.5})
+	assertIDs(t, "similar", ids, goOrRust)
+	if len(results) == 1 && (results[0].Similarity >= 1 || results[0].Similarity < 0.5) {
+		t.Errorf("similar similarity = %v, want in [0.5, 1)", results[0].Similarity)
+	}
+	ids, _ = find(rephrased, storage.SimilarOptions{MinSimilarity: 0.95})
+	assertIDs(t, "similar, strict", ids)
+
+	// Low thresholds return loosely similar inputs on every backend
+	ids, _ = find("Go or Rust?", storage.SimilarOptions{MinSimilarity: 0.15})
+	assertIDs(t, "similar, loose", ids, goOrRust)
+
+	ids, _ = find("Tabs or spaces?", storage.SimilarOptions{MinSimilarity: 0.5})
+	assertIDs(t, "unrelated", ids)
+	ids, _ = find("?!", storage.SimilarOptions{MinSimilarity: 0.5})
+	assertIDs(t, "punctuation only", ids)
+	ids, _ = find("Should I use Go or Rust for the ingestion service?", storage.SimilarOptions{UserID: &otherID, MinSimilarity: 0.9})
+	assertIDs(t, "other user", ids, theirs)
+}
+
+func testDecisionLineage(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+
+	// first <- second <- third, created through both insert paths
+	first := newDecision()
+	if err := repo.CreateDecision(ctx, first); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+	second := newDecision()
+	second.Supersedes = &first.ID
+	if err := repo.SaveArtifacts(ctx, second, &storage.Todo{Content: "# Todo"}); err != nil {
+		t.Fatalf("SaveArtifacts() error = %v", err)
+	}
+	third := newDecision()
+	third.Supersedes = &second.ID
+	if err := repo.CreateDecision(ctx, third); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+
+	links := []struct {
+		d                        *storage.Decision
+		supersedes, supersededBy *uuid.UUID
+	}{
+		{first, nil, &second.ID},
+		{second, &first.ID, &third.ID},
+		{third, &second.ID, nil},
+	}
+	for i, link := range links {
+		got, err := repo.GetDecision(ctx, link.d.ID)
+		if err != nil {
+			t.Fatalf("GetDecision() error = %v", err)
+		}
+		if !reflect.DeepEqual(got.Supersedes, link.supersedes) || !reflect.DeepEqual(got.SupersededBy, link.supersededBy) {
+			t.Errorf("decision %d: Supersedes, SupersededBy = %v, %v, want %v, %v", i, got.Supersedes, got.SupersededBy, link.supersedes, link.supersededBy)
+		}
+		// Superseding a decision does not change it
+		assertJSONEqual(t, "Verdict", got.Verdict, link.d.Verdict)
+		if got.Input != link.d.Input || got.IsFinal != link.d.IsFinal {
+			t.Errorf("decision %d changed: %+v", i, got)
+		}
+
+		lineage, err := repo.GetDecisionLineage(ctx, link.d.ID)
+		if err != nil {
+			t.Fatalf("GetDecisionLineage() error = %v", err)
+		}
+		var ids []uuid.UUID
+		for _, d := range lineage {
+			ids = append(ids, d.ID)
+		}
+		assertIDs(t, fmt.Sprintf("lineage through decision %d", i), ids, first.ID, second.ID, third.ID)
+	}
+
+	alone := newDecision()
+	if err := repo.CreateDecision(ctx, alone); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+	lineage, err := repo.GetDecisionLineage(ctx, alone.ID)
+	if err != nil || len(lineage) != 1 || lineage[0].ID != alone.ID {
+		t.Errorf("GetDecisionLineage() of a single decision = %v, %v", lineage, err)
+	}
+	_, err = repo.GetDecisionLineage(ctx, uuid.New())
+	assertNotFound(t, "GetDecisionLineage()", err)
+
+	// A decision is superseded at most once, and never by itself
+	fork := newDecision()
+	fork.Supersedes = &first.ID
+	assertConflict(t, "CreateDecision() superseding a superseded decision", repo.CreateDecision(ctx, fork))
+	fork = newDecision()
+	fork.Supersedes = &second.ID
+	assertConflict(t, "SaveArtifacts() superseding a superseded decision", repo.SaveArtifacts(ctx, fork, &storage.Todo{Content: "# Todo"}))
+	self := newDecision()
+	self.Supersedes = &self.ID
+	if err := repo.CreateDecision(ctx, self); err == nil {
+		t.Error("CreateDecision() superseding itself succeeded")
+	}
+}
+
+func testStateSnapshots(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	d := newDecision()
+	if err := repo.CreateDecision(ctx, d); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+
+	none, err := repo.ListStateSnapshots(ctx, d.ID)
+	if err != nil || len(none) != 0 {
+		t.Fatalf("ListStateSnapshots() before any snapshot = %v, %v, want none", none, err)
+	}
+
+	// Created out of order, listed by version
+	state := func(version int) json.RawMessage {
+		return json.RawMessage(fmt.Sprintf(`{"version":%d,"current_phase":1}`, version))
+	}
+	for _, version := range []int{2, 1, 3} {
+		s := &storage.StateSnapshot{
+			DecisionID: d.ID,
+			Version:    version,
+			State:      state(version),
+			CreatedAt:  fixedTime.Add(time.Duration(version) * time.Minute),
+		}
+		if err := repo.CreateStateSnapshot(ctx, s); err != nil {
+			t.Fatalf("CreateStateSnapshot(%d) error = %v", version, err)
+		}
+		if s.ID == uuid.Nil {
+			t.Fatal("CreateStateSnapshot() did not assign an ID")
+		}
+	}
+
+	snapshots, err := repo.ListStateSnapshots(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("ListStateSnapshots() error = %v", err)
+	}
+	if len(snapshots) != 3 {
+		t.Fatalf("ListStateSnapshots() returned %d snapshots, want 3", len(snapshots))
+	}
+	for i, s := range snapshots {
+		version := i + 1
+		if s.Version != version || s.DecisionID != d.ID {
+			t.Errorf("snapshot %d = version %d of %s, want version %d of %s", i, s.Version, s.DecisionID, version, d.ID)
+		}
+		assertJSONEqual(t, "State", s.State, state(version))
+		if !s.CreatedAt.Equal(fixedTime.Add(time.Duration(version) * time.Minute)) {
+			t.Errorf("snapshot %d CreatedAt = %v", i, s.CreatedAt)
+		}
+	}
+
+	// A version is written once
+	err = repo.CreateStateSnapshot(ctx, &storage.StateSnapshot{DecisionID: d.ID, Version: 2, State: json.RawMessage(`{}`)})
+	assertConflict(t, "CreateStateSnapshot with an existing version", err)
+}
+
+func testTasks(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	d := newDecision()
+	if err := repo.CreateDecision(ctx, d); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+
+	none, err := repo.ListTasks(ctx, d.ID)
+	if err != nil || len(none) != 0 {
+		t.Fatalf("ListTasks() before any task = %v, %v, want none", none, err)
+	}
+
+	// Created out of order, listed by phase, then position
+	done := &storage.Task{DecisionID: d.ID, PhaseIndex: 0, Position: 1, Text: "Configure CI", Status: storage.TaskDone}
+	tasks := []*storage.Task{
+		{DecisionID: d.ID, PhaseIndex: 1, Position: 0, Text: "Deploy", Notes: "After review", CreatedAt: fixedTime},
+		done,
+		{ID: uuid.New(), DecisionID: d.ID, PhaseIndex: 0, Position: 0, Text: "Setup project"},
+	}
+	if err := repo.CreateTasks(ctx, tasks); err != nil {
+		t.Fatalf("CreateTasks() error = %v", err)
+	}
+	for _, task := range tasks {
+		if task.ID == uuid.Nil || task.UpdatedAt.IsZero() {
+			t.Fatalf("CreateTasks() did not fill in %+v", task)
+		}
+	}
+	if tasks[0].Status != storage.TaskTodo || done.CompletedAt == nil || tasks[2].CompletedAt != nil {
+		t.Errorf("CreateTasks() statuses = %s, %s (completed %v)", tasks[0].Status, done.Status, done.CompletedAt)
+	}
+
+	listed, err := repo.ListTasks(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("ListTasks() error = %v", err)
+	}
+	if len(listed) != 3 {
+		t.Fatalf("ListTasks() returned %d tasks, want 3", len(listed))
+	}
+	for i, want := range []*storage.Task{tasks[2], done, tasks[0]} {
+		got := listed[i]
+		if got.ID != want.ID || got.DecisionID != d.ID || got.PhaseIndex != want.PhaseIndex || got.Position != want.Position ||
+			got.Text != want.Text || got.Status != want.Status || got.Notes != want.Notes {
+			t.Errorf("task %d = %+v, want %+v", i, got, want)
+		}
+		if (got.CompletedAt == nil) != (want.CompletedAt == nil) {
+			t.Errorf("task %d CompletedAt = %v, want %v", i, got.CompletedAt, want.CompletedAt)
+		}
+	}
+	if !listed[2].CreatedAt.Equal(fixedTime) {
+		t.Errorf("CreatedAt = %v, want %v", listed[2].CreatedAt, fixedTime)
+	}
+
+	// Updates change the status, notes and completion time
+	task := listed[0]
+	task.Status = storage.TaskDone
+	task.Notes = "Scaffolded"
+	if err := repo.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	task.Status = storage.TaskBlocked
+	if err := repo.UpdateTask(ctx, task); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	listed, err = repo.ListTasks(ctx, d.ID)
+	if err != nil {
+		t.Fatalf("ListTasks() error = %v", err)
+	}
+	if got := listed[0]; got.Status != storage.TaskBlocked || got.Notes != "Scaffolded" || got.CompletedAt != nil || got.Text != "Setup project" {
+		t.Errorf("updated task = %+v", got)
+	}
+
+	task.Status = "finished"
+	if err := repo.UpdateTask(ctx, task); err == nil {
+		t.Error("UpdateTask() with an invalid status succeeded")
+	}
+	err = repo.UpdateTask(ctx, &storage.Task{ID: uuid.New(), Status: storage.TaskDone})
+	assertNotFound(t, "UpdateTask", err)
+
+	// A position is taken once, and a failed batch stores nothing
+	other := newDecision()
+	if err := repo.CreateDecision(ctx, other); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+	err = repo.CreateTasks(ctx, []*storage.Task{
+		{DecisionID: other.ID, PhaseIndex: 0, Position: 0, Text: "First"},
+		{DecisionID: other.ID, PhaseIndex: 0, Position: 0, Text: "Again"},
+	})
+	assertConflict(t, "CreateTasks with a taken position", err)
+	if stored, _ := repo.ListTasks(ctx, other.ID); len(stored) != 0 {
+		t.Errorf("failed CreateTasks() stored %d tasks", len(stored))
+	}
+}
+
+func testGitHubExports(t *testing.T, repo storage.Repository) {
+	ctx := context.Background()
+	d := newDecision()
+	if err := repo.CreateDecision(ctx, d); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+
+	_, err := repo.GetGitHubExport(ctx, d.ID, "acme/plans")
+	assertNotFound(t, "GetGitHubExport before any export", err)
+
+	taskID := uuid.NewString()
+	e := &storage.GitHubExport{
+		DecisionID:    d.ID,
+		Repository:    "acme/plans",
+		TrackingIssue: 7,
+		Milestones:    map[int]int{0: 1, 1: 2},
+		Issues:        map[string]int{taskID: 5},
+	}
+	if err := repo.SaveGitHubExport(ctx, e); err != nil {
+		t.Fatalf("SaveGitHubExport() error = %v", err)
+	}
+	got, err := repo.GetGitHubExport(ctx, d.ID, "acme/plans")
+	if err != nil {
+		t.Fatalf("GetGitHubExport() error = %v", err)
+	}
+	if got.TrackingIssue != 7 || got.Milestones[1] != 2 || len(got.Milestones) != 2 || got.Issues[taskID] != 5 || got.CreatedAt.IsZero() {
+		t.Errorf("GetGitHubExport() = %+v", got)
+	}
+
+	// Saving again replaces the mapping
+	got.Issues[uuid.NewString()] = 6
+	got.Milestones = nil
+	if err := repo.SaveGitHubExport(ctx, got); err != nil {
+		t.Fatalf("SaveGitHubExport() error = %v", err)
+	}
+	replaced, err := repo.GetGitHubExport(ctx, d.ID, "acme/plans")
+	if err != nil {
+		t.Fatalf("GetGitHubExport() error = %v", err)
+	}
+	if len(replaced.Issues) != 2 || replaced.Milestones == nil || len(replaced.Milestones) != 0 || replaced.TrackingIssue != 7 {
+		t.Errorf("replaced export = %+v", replaced)
+	}
+
+	// Exports to other repositories are separate
+	_, err = repo.GetGitHubExport(ctx, d.ID, "acme/other")
+	assertNotFound(t, "GetGitHubExport for another repository", err)
+}
+
+// createScoredHistory creates a decision and a history entry for it with one
+// of two done criteria completed if done is set, scoring 50
+func createScoredHistory(t *testing.T, repo storage.Repository, users storage.UserRepository, userID uuid.UUID, createdAt time.Time, language string, done bool) (*storage.Decision, *storage.UserHistory) {
+	t.Helper()
+	ctx := context.Background()
+	d := newDecision()
+	d.UserID = &userID
+	d.CreatedAt = createdAt
+	d.Language = language
+	if err := repo.CreateDecision(ctx, d); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+	h := &storage.UserHistory{
+		UserID:     userID,
+		DecisionID: d.ID,
+		Input:      d.Input,
+		Verdict:    d.Verdict,
+		DoneCriteria: []storage.DoneCriterion{
+			{Index: 0, Text: "Write the service"},
+			{Index: 1, Text: "Deploy it"},
+		},
+		CreatedAt: createdAt,
+	}
+	if err := users.CreateHistory(ctx, h); err != nil {
+		t.Fatalf("CreateHistory() error = %v", err)
+	}
+	if done {
+		h.DoneCriteria[0].Completed = true
+		if _, err := users.UpdateDoneCriteria(ctx, h.ID, h.DoneCriteria); err != nil {
+			t.Fatalf("UpdateDoneCriteria() error = %v", err)
+		}
+	}
+	return d, h
+}
+
+func testListUserHistory(t *testing.T, repo storage.Repository, users storage.UserRepository) {
+	ctx := context.Background()
+	user, err := users.CreateUser(ctx, "user-"+uuid.NewString(), "hunter22")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	var ids []uuid.UUID
+	for i, language := range []string{"en", "en", "zh"} {
+		_, h := createScoredHistory(t, repo, users, user.ID, fixedTime.Add(time.Duration(i)*time.Hour), language, i == 1)
+		ids = append(ids, h.ID)
+	}
+
+	list := func(opts storage.ListOptions) []uuid.UUID {
+		return listed(t, opts.Limit, func(cursor string) ([]*storage.UserHistory, string, error) {
+			opts.Cursor = cursor
+			page, err := users.ListUserHistory(ctx, user.ID, opts)
+			if err != nil {
+				return nil, "", err
+			}
+			return page.Entries, page.NextCursor, nil
+		}, func(h *storage.UserHistory) uuid.UUID { return h.ID })
+	}
+
+	assertIDs(t, "newest first", list(storage.ListOptions{Limit: 2}), ids[2], ids[1], ids[0])
+	assertIDs(t, "oldest first", list(storage.ListOptions{Limit: 1, Ascending: true}), ids...)
+	minScore := 50.0
+	assertIDs(t, "min score", list(storage.ListOptions{Limit: 10, MinScore: &minScore}), ids[1])
+	maxScore := 0.0
+	assertIDs(t, "max score", list(storage.ListOptions{Limit: 10, MaxScore: &maxScore}), ids[2], ids[0])
+	assertIDs(t, "language zh", list(storage.ListOptions{Limit: 10, Language: "zh"}), ids[2])
+	assertIDs(t, "from", list(storage.ListOptions{Limit: 10, From: fixedTime.Add(time.Hour)}), ids[2], ids[1])
+
+	// Entries of other users are never listed
+	other, err := users.CreateUser(ctx, "user-"+uuid.NewString(), "hunter22")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	page, err := users.ListUserHistory(ctx, other.ID, storage.ListOptions{})
+	if err != nil {
+		t.Fatalf("ListUserHistory() error = %v", err)
+	}
+	if len(page.Entries) != 0 || page.NextCursor != "" {
+		t.Errorf("ListUserHistory(other user) = %d entries, cursor %q", len(page.Entries), page.NextCursor)
+	}
+}
+
+func testListDecisionsByScore(t *testing.T, repo storage.Repository, users storage.UserRepository) {
+	ctx := context.Background()
+	user, err := users.CreateUser(ctx, "user-"+uuid.NewString(), "hunter22")
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+	open, _ := createScoredHistory(t, repo, users, user.ID, fixedTime, "en", false)
+	done, _ := createScoredHistory(t, repo, users, user.ID, fixedTime.Add(time.Hour), "en", true)
+
+	// Decisions without history have no score and never match a score filter
+	untracked := newDecision()
+	untracked.UserID = &user.ID
+	if err := repo.CreateDecision(ctx, untracked); err != nil {
+		t.Fatalf("CreateDecision() error = %v", err)
+	}
+
+	minScore, maxScore := 50.0, 10.0
+	got := decisionIDs(t, repo, storage.ListOptions{UserID: &user.ID, Limit: 10, MinScore: &minScore})
+	assertIDs(t, "min score", got, done.ID)
+	got = decisionIDs(t, repo, storage.ListOptions{UserID: &user.ID, Limit: 10, MaxScore: &maxScore})
+	assertIDs(t, "max score", got, open.ID)
+}
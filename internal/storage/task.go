@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// prepareNewTask validates a task about to be created and fills in its ID,
+// status and timestamps
+func prepareNewTask(t *Task, now time.Time) error {
+	if t == nil {
+		return fmt.Errorf("task cannot be nil")
+	}
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	return touchTask(t, now)
+}
+
+// touchTask validates a task's status, sets its update time and keeps its
+// completion time in step with the status: set when it becomes done, cleared
+// when it is no longer done
+func touchTask(t *Task, now time.Time) error {
+	if t.Status == "" {
+		t.Status = TaskTodo
+	}
+	if !t.Status.IsValid() {
+		return fmt.Errorf("invalid task status %q", t.Status)
+	}
+	t.UpdatedAt = now
+	switch {
+	case t.Status != TaskDone:
+		t.CompletedAt = nil
+	case t.CompletedAt == nil:
+		t.CompletedAt = &now
+	}
+	return nil
+}
@@ -1,6 +1,6 @@
 -- Initial schema for verdict-agent
 
-CREATE TABLE decisions (
+CREATE TABLE IF NOT EXISTS decisions (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     input TEXT NOT NULL,
     verdict JSONB NOT NULL,
@@ -8,7 +8,7 @@ CREATE TABLE decisions (
     is_final BOOLEAN DEFAULT TRUE
 );
 
-CREATE TABLE todos (
+CREATE TABLE IF NOT EXISTS todos (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     decision_id UUID REFERENCES decisions(id),
     content TEXT NOT NULL,
@@ -16,5 +16,5 @@ CREATE TABLE todos (
 );
 
 -- Indexes for common queries
-CREATE INDEX idx_decisions_created_at ON decisions(created_at DESC);
-CREATE INDEX idx_todos_decision_id ON todos(decision_id);
+CREATE INDEX IF NOT EXISTS idx_decisions_created_at ON decisions(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_todos_decision_id ON todos(decision_id);
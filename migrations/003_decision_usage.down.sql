@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_decisions_user_id;
+
+ALTER TABLE decisions DROP COLUMN IF EXISTS cost_usd;
+ALTER TABLE decisions DROP COLUMN IF EXISTS output_tokens;
+ALTER TABLE decisions DROP COLUMN IF EXISTS input_tokens;
+ALTER TABLE decisions DROP COLUMN IF EXISTS user_id;
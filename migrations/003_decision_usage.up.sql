@@ -0,0 +1,9 @@
+-- Token usage and estimated cost of the pipeline run behind each decision
+
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS user_id UUID;
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS input_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS output_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Spend per user
+CREATE INDEX IF NOT EXISTS idx_decisions_user_id ON decisions(user_id);
@@ -0,0 +1,4 @@
+-- Provider and model that served each pipeline stage, e.g.
+-- {"verdict": "anthropic/claude-sonnet-4-20250514", "execution": "openai/gpt-4o"}
+
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS providers JSONB;
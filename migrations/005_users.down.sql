@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS uploaded_content;
+DROP TABLE IF EXISTS done_criteria;
+DROP TABLE IF EXISTS user_history;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,50 @@
+-- User accounts, login sessions and per-user decision history
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    token TEXT PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    decision_id UUID NOT NULL REFERENCES decisions(id),
+    input TEXT NOT NULL,
+    verdict JSONB NOT NULL,
+    todo TEXT NOT NULL DEFAULT '',
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Done criteria of a history entry; idx is the criterion's position in the todo
+CREATE TABLE IF NOT EXISTS done_criteria (
+    history_id UUID NOT NULL REFERENCES user_history(id) ON DELETE CASCADE,
+    idx INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    completed BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (history_id, idx)
+);
+
+-- Proof of progress uploaded against a history entry
+CREATE TABLE IF NOT EXISTS uploaded_content (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    history_id UUID NOT NULL REFERENCES user_history(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    url TEXT NOT NULL,
+    uploaded_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_history_user_id_created_at ON user_history(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_history_decision_id ON user_history(decision_id);
+CREATE INDEX IF NOT EXISTS idx_uploaded_content_history_id ON uploaded_content(history_id);
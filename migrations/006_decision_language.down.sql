@@ -0,0 +1 @@
+ALTER TABLE decisions DROP COLUMN IF EXISTS language;
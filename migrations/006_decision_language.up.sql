@@ -0,0 +1,12 @@
+-- Input language of each decision ("en" or "zh") for filtering listings.
+-- Existing rows are classified like the verdict agent does: more than 20%
+-- CJK ideographs is Chinese.
+
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT '';
+
+UPDATE decisions SET language = CASE
+    WHEN length(input) > 0
+        AND length(regexp_replace(input, '[^一-鿿]', '', 'g'))::float / length(input) > 0.2 THEN 'zh'
+    ELSE 'en'
+END
+WHERE language = '';
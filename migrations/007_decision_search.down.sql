@@ -0,0 +1,7 @@
+-- pg_trgm is left installed; other schemas in the database may use it
+
+DROP INDEX IF EXISTS idx_todos_search_trgm;
+DROP INDEX IF EXISTS idx_todos_search_tsv;
+DROP INDEX IF EXISTS idx_decisions_search_trgm;
+DROP INDEX IF EXISTS idx_decisions_search_tsv;
+ALTER TABLE decisions DROP COLUMN IF EXISTS search_document;
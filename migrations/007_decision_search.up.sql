@@ -0,0 +1,19 @@
+-- Full-text search over decisions and their todos. English is matched with
+-- tsvector indexes; the english parser does not split Chinese into words, so
+-- pg_trgm indexes serve substring matches for it.
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Input, ruling, rationale and rejected options. decision.json nests the
+-- verdict under "verdict"; a bare verdict is accepted too.
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS search_document TEXT GENERATED ALWAYS AS (
+    input || ' ' ||
+    coalesce(coalesce(verdict->'verdict', verdict)->>'ruling', '') || ' ' ||
+    coalesce(coalesce(verdict->'verdict', verdict)->>'rationale', '') || ' ' ||
+    coalesce(jsonb_path_query_array(coalesce(verdict->'verdict', verdict), '$.rejected[*].*')::text, '')
+) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_decisions_search_tsv ON decisions USING GIN (to_tsvector('english', search_document));
+CREATE INDEX IF NOT EXISTS idx_decisions_search_trgm ON decisions USING GIN (search_document gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_todos_search_tsv ON todos USING GIN (to_tsvector('english', content));
+CREATE INDEX IF NOT EXISTS idx_todos_search_trgm ON todos USING GIN (content gin_trgm_ops);
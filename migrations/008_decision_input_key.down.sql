@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_decisions_input_key_trgm;
+DROP INDEX IF EXISTS idx_decisions_input_key;
+ALTER TABLE decisions DROP COLUMN IF EXISTS input_key;
@@ -0,0 +1,12 @@
+-- Normalized input for duplicate detection: lowercase, with each run of
+-- whitespace and punctuation replaced by a single space. Must match
+-- inputKeyExpr in internal/storage/postgres.go. Only ASCII and common CJK
+-- punctuation are listed, so the key does not depend on the database locale.
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS input_key TEXT GENERATED ALWAYS AS (
+    btrim(regexp_replace(lower(input), '[[:space:][:punct:]，。？！、；：“”‘’（）《》【】…—]+', ' ', 'g'))
+) STORED;
+
+-- Exact matches; hash indexes have no key length limit
+CREATE INDEX IF NOT EXISTS idx_decisions_input_key ON decisions USING HASH (input_key);
+-- Similar inputs (pg_trgm is installed by 007)
+CREATE INDEX IF NOT EXISTS idx_decisions_input_key_trgm ON decisions USING GIN (input_key gin_trgm_ops);
@@ -0,0 +1,7 @@
+-- Decision lineage. Revisiting a decision stores a new one pointing at the
+-- decision it supersedes; decisions are never updated, so a decision is
+-- superseded when another points at it, which at most one may.
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS supersedes UUID REFERENCES decisions(id);
+ALTER TABLE decisions ADD CONSTRAINT decisions_supersedes_other CHECK (supersedes <> id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_decisions_supersedes ON decisions(supersedes) WHERE supersedes IS NOT NULL;
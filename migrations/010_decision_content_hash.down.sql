@@ -0,0 +1,2 @@
+ALTER TABLE decisions DROP COLUMN IF EXISTS signature;
+ALTER TABLE decisions DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,5 @@
+-- Tamper evidence. Each decision records the SHA-256 of its canonical
+-- decision.json and todo.md and, if the server signs, an Ed25519 signature
+-- of that hash. Decisions made before this migration have neither.
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS content_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE decisions ADD COLUMN IF NOT EXISTS signature TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,11 @@
+-- Versioned state.json artifacts: where execution of a decision stands.
+-- Snapshots are never updated; each progress update inserts the next version.
+
+CREATE TABLE IF NOT EXISTS state_snapshots (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    decision_id UUID NOT NULL REFERENCES decisions(id),
+    version INTEGER NOT NULL,
+    state JSONB NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    UNIQUE (decision_id, version)
+);
@@ -0,0 +1,12 @@
+-- GitHub milestones and issues each decision's execution plan was exported to
+
+CREATE TABLE IF NOT EXISTS github_exports (
+    decision_id UUID NOT NULL REFERENCES decisions(id),
+    repository TEXT NOT NULL,
+    tracking_issue INTEGER NOT NULL DEFAULT 0,
+    milestones JSONB NOT NULL DEFAULT '{}',
+    issues JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (decision_id, repository)
+);
@@ -0,0 +1,29 @@
+// Package migrations embeds the versioned SQL schema migrations.
+//
+// Each version NNN has an NNN_name.up.sql file and, if it can be reverted, an
+// NNN_name.down.sql file. PostgreSQL migrations live at the top level and are
+// applied by storage.Migrator; the sqlite directory holds the equivalent
+// schema for the SQLite backend.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+// FS holds the PostgreSQL migration files
+//
+//go:embed *.sql
+var FS embed.FS
+
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+// SQLiteFS returns the SQLite migration files
+func SQLiteFS() fs.FS {
+	sub, err := fs.Sub(sqliteFiles, "sqlite")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
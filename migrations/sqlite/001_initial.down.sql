@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS uploaded_content;
+DROP TABLE IF EXISTS done_criteria;
+DROP TABLE IF EXISTS user_history;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS jobs;
+DROP TABLE IF EXISTS todos;
+DROP TABLE IF EXISTS decisions;
@@ -0,0 +1,86 @@
+-- SQLite schema for single-node deployments. Mirrors the PostgreSQL
+-- migrations: UUIDs, JSON and timestamps (RFC 3339, UTC) are stored as TEXT.
+
+CREATE TABLE IF NOT EXISTS decisions (
+    id TEXT PRIMARY KEY,
+    input TEXT NOT NULL,
+    verdict TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    is_final INTEGER NOT NULL DEFAULT 1,
+    user_id TEXT,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd REAL NOT NULL DEFAULT 0,
+    providers TEXT
+);
+
+CREATE TABLE IF NOT EXISTS todos (
+    id TEXT PRIMARY KEY,
+    decision_id TEXT REFERENCES decisions(id),
+    content TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id TEXT PRIMARY KEY,
+    status TEXT NOT NULL DEFAULT 'queued',
+    request TEXT NOT NULL,
+    result TEXT,
+    error TEXT NOT NULL DEFAULT '',
+    user_id TEXT,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    started_at TEXT,
+    finished_at TEXT
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    id TEXT PRIMARY KEY,
+    username TEXT NOT NULL UNIQUE,
+    password_hash TEXT NOT NULL,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    token TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS user_history (
+    id TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    decision_id TEXT NOT NULL REFERENCES decisions(id),
+    input TEXT NOT NULL,
+    verdict TEXT NOT NULL,
+    todo TEXT NOT NULL DEFAULT '',
+    score REAL NOT NULL DEFAULT 0,
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS done_criteria (
+    history_id TEXT NOT NULL REFERENCES user_history(id) ON DELETE CASCADE,
+    idx INTEGER NOT NULL,
+    text TEXT NOT NULL,
+    completed INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (history_id, idx)
+);
+
+CREATE TABLE IF NOT EXISTS uploaded_content (
+    id TEXT PRIMARY KEY,
+    history_id TEXT NOT NULL REFERENCES user_history(id) ON DELETE CASCADE,
+    file_name TEXT NOT NULL,
+    content_type TEXT NOT NULL DEFAULT '',
+    url TEXT NOT NULL,
+    uploaded_at TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_decisions_created_at ON decisions(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_decisions_user_id ON decisions(user_id);
+CREATE INDEX IF NOT EXISTS idx_todos_decision_id ON todos(decision_id);
+CREATE INDEX IF NOT EXISTS idx_jobs_status_created_at ON jobs(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+CREATE INDEX IF NOT EXISTS idx_user_history_user_id_created_at ON user_history(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_user_history_decision_id ON user_history(decision_id);
+CREATE INDEX IF NOT EXISTS idx_uploaded_content_history_id ON uploaded_content(history_id);
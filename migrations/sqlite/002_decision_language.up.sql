@@ -0,0 +1,5 @@
+-- Input language of each decision ("en" or "zh") for filtering listings.
+-- SQLite has no regular expressions to classify existing rows, so they are
+-- left unset and only match listings without a language filter.
+
+ALTER TABLE decisions ADD COLUMN language TEXT NOT NULL DEFAULT '';
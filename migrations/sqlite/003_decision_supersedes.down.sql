@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_decisions_supersedes;
+ALTER TABLE decisions DROP COLUMN supersedes;
@@ -0,0 +1,5 @@
+-- Decision lineage: the decision each revisit supersedes. A decision is
+-- superseded when another points at it, which at most one may.
+
+ALTER TABLE decisions ADD COLUMN supersedes TEXT REFERENCES decisions(id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_decisions_supersedes ON decisions(supersedes) WHERE supersedes IS NOT NULL;
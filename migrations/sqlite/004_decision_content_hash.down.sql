@@ -0,0 +1,2 @@
+ALTER TABLE decisions DROP COLUMN signature;
+ALTER TABLE decisions DROP COLUMN content_hash;
@@ -0,0 +1,5 @@
+-- Tamper evidence: the SHA-256 of each decision's canonical decision.json and
+-- todo.md, and its Ed25519 signature if the server signs.
+
+ALTER TABLE decisions ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE decisions ADD COLUMN signature TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Versioned state.json artifacts, one row per progress update.
+
+CREATE TABLE IF NOT EXISTS state_snapshots (
+    id TEXT PRIMARY KEY,
+    decision_id TEXT NOT NULL REFERENCES decisions(id),
+    version INTEGER NOT NULL,
+    state TEXT NOT NULL,
+    created_at TEXT NOT NULL,
+    UNIQUE (decision_id, version)
+);
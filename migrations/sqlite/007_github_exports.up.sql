@@ -0,0 +1,12 @@
+-- GitHub milestones and issues each decision's execution plan was exported to.
+
+CREATE TABLE IF NOT EXISTS github_exports (
+    decision_id TEXT NOT NULL REFERENCES decisions(id),
+    repository TEXT NOT NULL,
+    tracking_issue INTEGER NOT NULL DEFAULT 0,
+    milestones TEXT NOT NULL DEFAULT '{}',
+    issues TEXT NOT NULL DEFAULT '{}',
+    created_at TEXT NOT NULL,
+    updated_at TEXT NOT NULL,
+    PRIMARY KEY (decision_id, repository)
+);
@@ -20,14 +20,18 @@ import (
 
 // testRepository is a test implementation of storage.Repository
 type testRepository struct {
+	storage.Repository // Methods not overridden below are not exercised by these tests
+
 	decisions map[uuid.UUID]*storage.Decision
 	todos     map[uuid.UUID]*storage.Todo
+	states    map[uuid.UUID][]*storage.StateSnapshot
 }
 
 func newTestRepository() *testRepository {
 	return &testRepository{
 		decisions: make(map[uuid.UUID]*storage.Decision),
 		todos:     make(map[uuid.UUID]*storage.Todo),
+		states:    make(map[uuid.UUID][]*storage.StateSnapshot),
 	}
 }
 
@@ -83,6 +87,15 @@ func (r *testRepository) SaveArtifacts(ctx context.Context, d *storage.Decision,
 	return nil
 }
 
+func (r *testRepository) CreateStateSnapshot(ctx context.Context, s *storage.StateSnapshot) error {
+	r.states[s.DecisionID] = append(r.states[s.DecisionID], s)
+	return nil
+}
+
+func (r *testRepository) ListStateSnapshots(ctx context.Context, decisionID uuid.UUID) ([]*storage.StateSnapshot, error) {
+	return r.states[decisionID], nil
+}
+
 func (r *testRepository) Ping(ctx context.Context) error {
 	return nil
 }
@@ -97,6 +110,10 @@ func (e *notFoundError) Error() string {
 	return e.message
 }
 
+func (e *notFoundError) Unwrap() error {
+	return storage.ErrNotFound
+}
+
 func setupTestServer() *httptest.Server {
 	mockLLM := mocks.NewMockLLMClient()
 	verdictAgent := agent.NewVerdictAgent(mockLLM)
@@ -14,6 +14,7 @@
     const clarificationReason = document.getElementById('clarification-reason');
     const questionsContainer = document.getElementById('questions-container');
     const results = document.getElementById('results');
+    const duplicateNotice = document.getElementById('duplicate-notice');
     const rulingText = document.getElementById('ruling-text');
     const rationaleText = document.getElementById('rationale-text');
     const rejectedSection = document.getElementById('rejected-section');
@@ -42,18 +43,25 @@
 
     // State
     let currentInput = '';
+    let lastPayload = null;
     let currentQuestions = [];
-    let progressTimer = null;
-    let currentStep = 0;
     let authMode = 'login'; // 'login' or 'register'
     let authToken = localStorage.getItem('authToken');
     let currentUser = null;
     let currentHistoryId = null;
     let currentDoneCriteria = [];
+    let currentDecisionId = null;
+    let currentTaskIds = null; // Tracked task IDs, in todo.md order
 
     // Progress steps configuration
     const progressSteps = ['step-clarify', 'step-search', 'step-verdict', 'step-plan'];
-    const stepDurations = [2000, 4000, 8000, 5000]; // Estimated time for each step
+    // Pipeline stage (from server progress events) -> progress step element
+    const stageSteps = {
+        clarification: 'step-clarify',
+        search: 'step-search',
+        verdict: 'step-verdict',
+        execution: 'step-plan'
+    };
 
     // Update character count
     input.addEventListener('input', function() {
@@ -115,7 +123,21 @@
         }
     };
 
+    // Ask again for a verdict the server answered with an earlier decision
+    window.forceNewVerdict = async function() {
+        showLoading(true);
+        results.classList.add('hidden');
+
+        try {
+            const response = await submitVerdict(Object.assign({}, lastPayload, { force: true }));
+            handleResponse(response);
+        } catch (err) {
+            displayError(err.message);
+        }
+    };
+
     async function submitVerdict(payload) {
+        lastPayload = payload;
         const headers = {
             'Content-Type': 'application/json'
         };
@@ -123,19 +145,86 @@
             headers['Authorization'] = 'Bearer ' + authToken;
         }
 
-        const response = await fetch('/api/verdict', {
+        const response = await fetch('/api/verdict/stream', {
             method: 'POST',
             headers: headers,
             body: JSON.stringify(payload)
         });
 
-        const data = await response.json();
-
+        // Validation errors are returned as plain JSON before the stream starts
         if (!response.ok) {
+            const data = await response.json();
             throw new Error(data.error || 'An unknown error occurred');
         }
 
-        return data;
+        return readVerdictStream(response);
+    }
+
+    // Read Server-Sent Events from the verdict stream, updating progress steps
+    // as stages run, and resolve with the final result
+    async function readVerdictStream(response) {
+        const reader = response.body.getReader();
+        const decoder = new TextDecoder();
+        let buffer = '';
+
+        while (true) {
+            const chunk = await reader.read();
+            if (chunk.done) break;
+            buffer += decoder.decode(chunk.value, { stream: true });
+
+            let boundary;
+            while ((boundary = buffer.indexOf('\n\n')) !== -1) {
+                const raw = buffer.slice(0, boundary);
+                buffer = buffer.slice(boundary + 2);
+
+                const event = parseSSEEvent(raw);
+                if (!event) continue;
+
+                if (event.name === 'progress') {
+                    updateProgressStep(event.data);
+                } else if (event.name === 'result') {
+                    return event.data;
+                } else if (event.name === 'error') {
+                    throw new Error(event.data.error || 'An unknown error occurred');
+                }
+            }
+        }
+
+        throw new Error('Connection closed before the verdict was ready');
+    }
+
+    function parseSSEEvent(raw) {
+        let name = 'message';
+        let data = '';
+        raw.split('\n').forEach(function(line) {
+            if (line.indexOf('event: ') === 0) {
+                name = line.slice(7);
+            } else if (line.indexOf('data: ') === 0) {
+                data += line.slice(6);
+            }
+        });
+        if (!data) return null;
+        return { name: name, data: JSON.parse(data) };
+    }
+
+    function updateProgressStep(event) {
+        const stepId = stageSteps[event.stage];
+        if (!stepId) return;
+
+        const step = document.getElementById(stepId);
+        if (!step) return;
+
+        if (event.status === 'delta') {
+            // Streamed model output; the step is already active
+            return;
+        }
+
+        if (event.status === 'started') {
+            step.classList.add('active');
+        } else {
+            step.classList.remove('active');
+            step.classList.add('completed');
+        }
     }
 
     function handleResponse(data) {
@@ -158,9 +247,10 @@
         // Reset progress steps
         resetProgressSteps();
 
-        // Start progress animation
-        currentStep = skipClarifyStep ? 1 : 0; // Skip clarify step if already answered
-        startProgressAnimation();
+        // Clarify step is already done if the user answered the questions
+        if (skipClarifyStep) {
+            updateProgressStep({ stage: 'clarification', status: 'skipped' });
+        }
     }
 
     function hideLoading() {
@@ -169,12 +259,6 @@
         // Complete all remaining steps
         completeAllSteps();
 
-        // Stop progress timer
-        if (progressTimer) {
-            clearTimeout(progressTimer);
-            progressTimer = null;
-        }
-
         // Slight delay before hiding to show completion
         setTimeout(function() {
             loading.classList.add('hidden');
@@ -189,28 +273,6 @@
                 step.classList.remove('active', 'completed');
             }
         });
-        currentStep = 0;
-    }
-
-    function startProgressAnimation() {
-        if (currentStep >= progressSteps.length) return;
-
-        const stepId = progressSteps[currentStep];
-        const step = document.getElementById(stepId);
-
-        if (step) {
-            step.classList.add('active');
-        }
-
-        // Schedule next step
-        progressTimer = setTimeout(function() {
-            if (step) {
-                step.classList.remove('active');
-                step.classList.add('completed');
-            }
-            currentStep++;
-            startProgressAnimation();
-        }, stepDurations[currentStep] || 3000);
     }
 
     function completeAllSteps() {
@@ -330,6 +392,7 @@
         error.classList.add('hidden');
         clarification.classList.add('hidden');
         results.classList.remove('hidden');
+        duplicateNotice.classList.toggle('hidden', !data.duplicate_of);
 
         // Parse decision JSON
         let decision;
@@ -361,10 +424,13 @@
             }
         }
 
-        // Display todo as rendered markdown with interactive checkboxes if logged in
+        // Display todo as rendered markdown with interactive checkboxes if logged in.
+        // An earlier decision is tracked from the history panel, which has its progress.
         if (data.todo) {
-            if (authToken && data.history_id) {
+            if (authToken && data.history_id && !data.duplicate_of) {
                 currentHistoryId = data.history_id;
+                currentDecisionId = data.decision_id;
+                currentTaskIds = null;
                 // Extract done criteria from API response
                 if (data.done_criteria && data.done_criteria.length > 0) {
                     currentDoneCriteria = data.done_criteria.map(function(text, index) {
@@ -487,12 +553,12 @@
         html = html.replace(/^## (.+)$/gm, '<h2>$1</h2>');
         html = html.replace(/^# (.+)$/gm, '<h1>$1</h1>');
 
-        // Phase task checkboxes (visual only, not tracked for score)
-        html = html.replace(/^- \[ \] (.+)$/gm, function(match, text) {
-            return '<li class="phase-task"><input type="checkbox" class="phase-checkbox"> ' + text + '</li>';
-        });
-        html = html.replace(/^- \[x\] (.+)$/gm, function(match, text) {
-            return '<li class="phase-task completed"><input type="checkbox" class="phase-checkbox" checked> ' + text + '</li>';
+        // Phase task checkboxes (tracked as task status, not for score)
+        let taskIndex = 0;
+        html = html.replace(/^- \[( |x)\] (.+)$/gm, function(match, mark, text) {
+            const checked = mark === 'x';
+            const idx = taskIndex++;
+            return '<li class="phase-task' + (checked ? ' completed' : '') + '"><input type="checkbox" class="phase-checkbox" data-task-index="' + idx + '"' + (checked ? ' checked' : '') + '> ' + text + '</li>';
         });
 
         // Done Criteria checkboxes (tracked for score)
@@ -538,15 +604,16 @@
             checkbox.addEventListener('change', handleDoneCriteriaChange);
         });
 
-        // Phase task checkboxes - visual only (strikethrough)
+        // Phase task checkboxes - saved as the task's status
         const phaseCheckboxes = todoContent.querySelectorAll('.phase-checkbox');
         phaseCheckboxes.forEach(function(checkbox) {
             checkbox.addEventListener('change', handlePhaseCheckboxChange);
         });
     }
 
-    function handlePhaseCheckboxChange(event) {
+    async function handlePhaseCheckboxChange(event) {
         const checkbox = event.target;
+        const index = parseInt(checkbox.dataset.taskIndex, 10);
         const li = checkbox.closest('li');
 
         if (checkbox.checked) {
@@ -554,6 +621,50 @@
         } else {
             li.classList.remove('completed');
         }
+
+        // Save to backend if we have a decision ID
+        if (currentDecisionId && authToken) {
+            try {
+                if (!currentTaskIds) {
+                    currentTaskIds = await fetchTaskIds(currentDecisionId);
+                }
+                if (currentTaskIds[index]) {
+                    await updateTaskStatus(currentDecisionId, currentTaskIds[index], checkbox.checked ? 'done' : 'todo');
+                }
+            } catch (err) {
+                console.error('Failed to save task progress:', err);
+            }
+        }
+    }
+
+    async function fetchTaskIds(decisionId) {
+        const response = await fetch('/api/decisions/' + decisionId + '/tasks', {
+            headers: { 'Authorization': 'Bearer ' + authToken }
+        });
+
+        if (!response.ok) {
+            throw new Error('Failed to load tasks');
+        }
+
+        const data = await response.json();
+        return data.tasks.map(function(task) { return task.id; });
+    }
+
+    async function updateTaskStatus(decisionId, taskId, status) {
+        const response = await fetch('/api/decisions/' + decisionId + '/tasks/' + taskId, {
+            method: 'PATCH',
+            headers: {
+                'Content-Type': 'application/json',
+                'Authorization': 'Bearer ' + authToken
+            },
+            body: JSON.stringify({ status: status })
+        });
+
+        if (!response.ok) {
+            throw new Error('Failed to update task');
+        }
+
+        return response.json();
     }
 
     async function handleDoneCriteriaChange(event) {
@@ -760,40 +871,53 @@
         if (!authToken) return;
 
         if (historyModal) historyModal.classList.remove('hidden');
+        if (historyList) historyList.innerHTML = '';
+        await loadHistoryPage('');
+    };
 
+    // Loads a page of history after the given cursor and appends it to the list
+    async function loadHistoryPage(cursor) {
         try {
-            const response = await fetch('/api/history', {
+            const url = '/api/history?limit=50' + (cursor ? '&cursor=' + encodeURIComponent(cursor) : '');
+            const response = await fetch(url, {
                 headers: { 'Authorization': 'Bearer ' + authToken }
             });
 
             if (!response.ok) throw new Error('Failed to load history');
 
-            const history = await response.json();
-            displayHistory(history);
+            const page = await response.json();
+            displayHistory(page.history, !cursor, page.next_cursor);
         } catch (err) {
             if (historyList) {
-                historyList.innerHTML = '<div class="history-empty">' +
+                removeHistoryMore();
+                historyList.insertAdjacentHTML('beforeend', '<div class="history-empty">' +
                     (getCurrentLang() === 'zh' ? '加载历史记录失败' : 'Failed to load history') +
-                    '</div>';
+                    '</div>');
             }
         }
-    };
+    }
 
     window.hideHistoryModal = function() {
         if (historyModal) historyModal.classList.add('hidden');
     };
 
-    function displayHistory(history) {
+    function removeHistoryMore() {
+        const more = historyList.querySelector('.history-more');
+        if (more) more.remove();
+    }
+
+    function displayHistory(history, firstPage, nextCursor) {
         if (!historyList) return;
+        removeHistoryMore();
 
-        if (!history || history.length === 0) {
+        if (firstPage && (!history || history.length === 0)) {
             historyList.innerHTML = '<div class="history-empty">' +
                 (getCurrentLang() === 'zh' ? '暂无决策历史' : 'No decision history yet') +
                 '</div>';
             return;
         }
 
-        historyList.innerHTML = history.map(function(item) {
+        historyList.insertAdjacentHTML('beforeend', (history || []).map(function(item) {
             const date = new Date(item.created_at).toLocaleDateString();
             const inputPreview = item.input.length > 60
                 ? item.input.substring(0, 60) + '...'
@@ -806,7 +930,19 @@
                 '</div>' +
                 '<div class="history-item-date">' + date + '</div>' +
                 '</div>';
-        }).join('');
+        }).join(''));
+
+        // Older entries are loaded on request
+        if (nextCursor) {
+            const more = document.createElement('button');
+            more.className = 'auth-btn history-more';
+            more.textContent = getCurrentLang() === 'zh' ? '加载更多' : 'Load more';
+            more.addEventListener('click', function() {
+                more.disabled = true;
+                loadHistoryPage(nextCursor);
+            });
+            historyList.appendChild(more);
+        }
     }
 
     window.loadHistoryItem = async function(historyId) {
@@ -824,6 +960,8 @@
             // Display the saved decision
             currentHistoryId = item.id;
             currentDoneCriteria = item.done_criteria || [];
+            currentDecisionId = item.decision_id;
+            currentTaskIds = null;
 
             // Parse and display the verdict
             let decision;
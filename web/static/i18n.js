@@ -21,7 +21,9 @@ const i18n = {
         stepClarify: "Analyzing context",
         stepSearch: "Searching for information",
         stepVerdict: "Making decision",
-        stepPlan: "Generating execution plan"
+        stepPlan: "Generating execution plan",
+        duplicateNotice: "You already asked this. Showing the earlier decision.",
+        duplicateForce: "Get a New Ruling"
     },
     zh: {
         title: "裁决代理",
@@ -44,7 +46,9 @@ const i18n = {
         stepClarify: "分析上下文",
         stepSearch: "搜索相关信息",
         stepVerdict: "做出决策",
-        stepPlan: "生成执行计划"
+        stepPlan: "生成执行计划",
+        duplicateNotice: "这个问题已经裁决过，以下是之前的决策。",
+        duplicateForce: "重新裁决"
     }
 };
 
@@ -71,6 +75,8 @@ function updateUILanguage() {
     document.getElementById('rejected-label').textContent = t.rejectedLabel;
     document.getElementById('todo-title').textContent = t.todoTitle;
     document.getElementById('decision-id-label').textContent = t.decisionIdLabel;
+    document.getElementById('duplicate-text').textContent = t.duplicateNotice;
+    document.getElementById('duplicate-force-text').textContent = t.duplicateForce;
     document.getElementById('lang-text').textContent = t.langToggle;
 
     // Update clarification labels